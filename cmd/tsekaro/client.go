@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// client calls the v1 api of a tsekaro server
+type client struct {
+	base string
+	http *http.Client
+}
+
+// page is a page of records as returned by the GetAll handlers
+type page struct {
+	Page         int64           `json:"page"`
+	PageSize     int64           `json:"page_size"`
+	Data         json.RawMessage `json:"data"`
+	TotalRecords int64           `json:"total_records"`
+}
+
+// apiError is the body of a failed request
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func newClient(server string) client {
+	return client{
+		base: strings.TrimSuffix(server, "/") + "/v1",
+		http: &http.Client{},
+	}
+}
+
+// do sends in as json, when given, and decodes the response into out
+func (c client) do(method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	res, err := c.send(method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err = json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("unexpected response of %s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// send issues a request and checks its status, the caller closing the body
+// of the response
+func (c client) send(method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 == 2 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	data, _ := ioutil.ReadAll(res.Body)
+	var e apiError
+	if json.Unmarshal(data, &e) == nil && e.Message != "" {
+		return nil, fmt.Errorf("%s %s: %s", method, path, e.Message)
+	}
+	return nil, fmt.Errorf("%s %s: %s %s", method, path, res.Status, strings.TrimSpace(string(data)))
+}
+
+// pageSize is the number of records fetched per page by all
+const pageSize = 100
+
+// all fetches every page of a listing and appends the records to out, a
+// pointer to a slice. Pages are numbered from 1, page 0 being another name
+// for the first one. A short page is the last one whatever the total.
+func (c client) all(path string, query url.Values, out interface{}) error {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("pagesize", strconv.Itoa(pageSize))
+	var records []json.RawMessage
+	for n := 1; ; n++ {
+		q.Set("page", fmt.Sprint(n))
+		var p page
+		if err := c.do(http.MethodGet, path, q, nil, &p); err != nil {
+			return err
+		}
+		var data []json.RawMessage
+		if err := json.Unmarshal(p.Data, &data); err != nil {
+			return fmt.Errorf("unexpected response of GET %s: %v", path, err)
+		}
+		records = append(records, data...)
+		if len(data) < pageSize || int64(len(records)) >= p.TotalRecords {
+			break
+		}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thejasn/tester/core/export"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+// runFile runs a suite file on this machine, as exported by suites export,
+// without a server nor a database. The report of every flow is written to
+// the directory given with -o in each of the formats asked for, along with
+// suite.json, the aggregate report.
+func runFile(_ client, args []string, out io.Writer) error {
+	fs := newFlags("run file")
+	var o runOptions
+	o.register(fs, true)
+	dir := fs.String("o", "", "directory to write the reports to")
+	formats := fs.String("format", "junit", "comma separated formats of the reports: junit, tap, json or html")
+	positional, err := arguments(fs, args, 1, "a suite file")
+	if err != nil {
+		return err
+	}
+	var fmts []export.Format
+	for _, s := range strings.Split(*formats, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		f, err := export.ParseFormat(s)
+		if err != nil {
+			return err
+		}
+		fmts = append(fmts, f)
+	}
+
+	l, err := openLocal(positional[0])
+	if err != nil {
+		return err
+	}
+
+	// logs go to stderr, leaving stdout to the report
+	logger := logrus.New()
+	logger.Out = os.Stderr
+	logger.Level = logrus.WarnLevel
+	ctx := log.WithLogger(context.Background(), logrus.NewEntry(logger))
+	if o.timeout != "" {
+		d, err := time.ParseDuration(o.timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", o.timeout)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	fmt.Fprintf(os.Stderr, "running suite %q from %s\n", l.Suite().Name, positional[0])
+	started := time.Now()
+	report, err := l.Execute(ctx, service.RunOptions{
+		Environment: o.env,
+		Source:      service.SourceLocal,
+		Progress: func(f service.SuiteFlowResult) {
+			if !o.json {
+				printFlow(out, f)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if *dir != "" {
+		if err = writeReports(*dir, fmts, started, report); err != nil {
+			return err
+		}
+		if !o.json {
+			fmt.Fprintf(out, "reports written to %s\n", *dir)
+		}
+	}
+	return printSuite(out, report, o)
+}
+
+func openLocal(name string) (service.Local, error) {
+	if name == "-" {
+		return service.NewLocal(os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return service.NewLocal(f)
+}
+
+// writeReports writes the report of every flow of a local run in each
+// format, files being named after the id and name of the flow
+func writeReports(dir string, formats []export.Format, started time.Time, report service.SuiteReport) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i, f := range report.Flows {
+		r := export.Run{
+			ID:      i + 1,
+			Name:    f.Name,
+			Source:  service.SourceLocal,
+			Started: started,
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("flow %d", f.FlowID)
+		}
+		if f.Report != nil {
+			r.Report = *f.Report
+		}
+		if f.Error != "" {
+			// the steps run before the flow failed are kept
+			r.Report.Message = f.Error
+		}
+		for _, format := range formats {
+			name := filepath.Join(dir, fmt.Sprintf("%d-%s%s", f.FlowID, slug(r.Name), format.Extension()))
+			if err := writeReport(name, format, r); err != nil {
+				return err
+			}
+		}
+	}
+	w, err := os.Create(filepath.Join(dir, "suite.json"))
+	if err != nil {
+		return err
+	}
+	if err = printJSON(w, report); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func writeReport(name string, f export.Format, r export.Run) error {
+	w, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err = export.Write(w, f, r); err != nil {
+		w.Close()
+		return fmt.Errorf("could not write %s: %v", name, err)
+	}
+	return w.Close()
+}
+
+// slug turns the name of a flow into a file name
+func slug(name string) string {
+	s := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+	return strings.Trim(s, "-")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thejasn/tester/core/export"
+	"github.com/thejasn/tester/core/stream"
+	"github.com/thejasn/tester/service"
+)
+
+func TestWriteReports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsekaro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := service.SuiteReport{
+		Flows: []service.SuiteFlowResult{{
+			FlowID: 3,
+			Name:   "Checkout",
+			Error:  "flow deadline exceeded",
+			Report: &stream.Report{Steps: []stream.StepResult{{Name: "login", Status: stream.Passed}}},
+		}},
+	}
+	if err = writeReports(dir, []export.Format{export.JSON}, time.Now(), report); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "3-checkout.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r export.Run
+	if err = json.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Report.Message != "flow deadline exceeded" || len(r.Report.Steps) != 1 || r.Report.Steps[0].Name != "login" {
+		t.Fatalf("bad report: %#v", r.Report)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "suite.json")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,162 @@
+// Command tsekaro is the command line client of a Tsekaro server. It lists,
+// creates and updates flows and testcases, imports and exports suites and
+// runs flows, suites or tagged flows, exiting with a non-zero status when a
+// run fails so that pipelines can gate on it. Exported suites can also be
+// run locally, without a server nor a database.
+//
+//	tsekaro [-server url] <command> <subcommand> [args] [flags]
+//
+// The server defaults to $TSEKARO_SERVER or http://localhost:8080.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	exitPassed = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+// errFailed is returned by a command whose run did not pass, the failure
+// itself having been reported already
+var errFailed = errors.New("run failed")
+
+const usage = `usage: tsekaro [-server url] <command> <subcommand> [args] [flags]
+
+commands:
+  flows     list | get <id> | create -f file | update <id> -f file
+  testcases list | get <id> | create -f file | update <id> -f file
+  suites    list | export <id> [-o file] [-auth credentials file] | import -f file
+  run       flow <id> | testcase <id> | suite <id> | tag <tag>
+            [-env environment] [-timeout 5m] [-json]
+  run       file <suite file> [-o dir] [-format junit,html]
+            runs an exported suite locally, without a server
+
+files given as - are read from stdin, the server defaults to
+$TSEKARO_SERVER or http://localhost:8080
+`
+
+type command func(c client, args []string, out io.Writer) error
+
+var commands = map[string]map[string]command{
+	"flows": {
+		"list":   listFlows,
+		"get":    getFlow,
+		"create": createFlow,
+		"update": updateFlow,
+	},
+	"testcases": {
+		"list":   listTestcases,
+		"get":    getTestcase,
+		"create": createTestcase,
+		"update": updateTestcase,
+	},
+	"suites": {
+		"list":   listSuites,
+		"export": exportSuite,
+		"import": importSuite,
+	},
+	"run": {
+		"flow":     runFlow,
+		"testcase": runTestcase,
+		"suite":    runSuite,
+		"tag":      runTag,
+		"file":     runFile,
+	},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("tsekaro", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() { fmt.Fprint(errOut, usage) }
+	server := fs.String("server", serverURL(), "base url of the tsekaro server")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return exitError
+	}
+	cmd, ok := commands[fs.Arg(0)][fs.Arg(1)]
+	if !ok {
+		fmt.Fprintf(errOut, "tsekaro: unknown command %q\n", fs.Arg(0)+" "+fs.Arg(1))
+		fs.Usage()
+		return exitError
+	}
+
+	err := cmd(newClient(*server), fs.Args()[2:], out)
+	switch {
+	case err == nil:
+		return exitPassed
+	case errors.Is(err, errFailed):
+		return exitFailed
+	case errors.Is(err, flag.ErrHelp):
+		return exitError
+	}
+	fmt.Fprintf(errOut, "tsekaro: %v\n", err)
+	return exitError
+}
+
+func serverURL() string {
+	if s := os.Getenv("TSEKARO_SERVER"); s != "" {
+		return s
+	}
+	return "http://localhost:8080"
+}
+
+// parse parses flags given before, after or between positional arguments
+// and returns the latter
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// arguments parses the flags of a subcommand expecting n positional
+// arguments
+func arguments(fs *flag.FlagSet, args []string, n int, names string) ([]string, error) {
+	positional, err := parse(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != n {
+		return nil, fmt.Errorf("%s expects %s", fs.Name(), names)
+	}
+	return positional, nil
+}
+
+// identifier parses the id of a record given on the command line
+func identifier(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", s)
+	}
+	return id, nil
+}
+
+// newFlags returns the flag set of a subcommand, errors being returned
+// rather than exiting
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestRunExitCode(t *testing.T) {
+	cases := []struct {
+		Name string
+		Args []string
+		Body string
+		Exit int
+	}{
+		{"flow passed", []string{"run", "flow", "1"}, `{"passed": true, "steps": []}`, exitPassed},
+		{"flow failed", []string{"run", "flow", "1"}, `{"passed": false, "steps": [{"name": "login", "status": "failed"}]}`, exitFailed},
+		{"testcase failed", []string{"run", "testcase", "1"}, `{"passed": false, "steps": []}`, exitFailed},
+		{"suite passed", []string{"run", "suite", "1"},
+			`{"event": "flow", "flow": {"flow_id": 1, "name": "login", "passed": true}}` + "\n" +
+				`{"event": "report", "report": {"passed": true, "total": 1, "succeeded": 1}}` + "\n", exitPassed},
+		{"suite failed", []string{"run", "suite", "1"},
+			`{"event": "flow", "flow": {"flow_id": 1, "name": "login", "passed": false}}` + "\n" +
+				`{"event": "report", "report": {"passed": false, "total": 1, "failed": 1}}` + "\n", exitFailed},
+		{"tag errored", []string{"run", "tag", "smoke"},
+			`{"event": "flow", "flow": {"flow_id": 1, "name": "login", "passed": true}}` + "\n" +
+				`{"event": "error", "message": "run timed out"}` + "\n", exitError},
+		{"no report", []string{"run", "suite", "1"},
+			`{"event": "flow", "flow": {"flow_id": 1, "name": "login", "passed": true}}` + "\n", exitError},
+		{"truncated stream", []string{"run", "suite", "1"}, `{"event": "rep`, exitError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.Body)
+			}))
+			defer srv.Close()
+
+			var out, errOut bytes.Buffer
+			args := append([]string{"-server", srv.URL}, tc.Args...)
+			if exit := run(args, &out, &errOut); exit != tc.Exit {
+				t.Fatalf("bad exit %d, want %d: %s%s", exit, tc.Exit, out.String(), errOut.String())
+			}
+		})
+	}
+}
+
+func TestClientAll(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Records  int
+		Total    int64
+		Requests int
+	}{
+		{"empty", 0, 0, 1},
+		{"short page", 30, 30, 1},
+		{"short page below total", 30, 1000, 1},
+		{"full pages", 2*pageSize + 5, 2*pageSize + 5, 3},
+		{"exact pages", 2 * pageSize, 2 * pageSize, 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				// pages the way the GetAll of the repos do
+				n, _ := strconv.Atoi(r.FormValue("page"))
+				size, _ := strconv.Atoi(r.FormValue("pagesize"))
+				offset := 0
+				if n > 0 {
+					offset = (n - 1) * size
+				}
+				var data []int
+				for i := offset; i < offset+size && i < tc.Records; i++ {
+					data = append(data, i)
+				}
+				if data == nil {
+					data = []int{}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"page": n, "data": data, "total_records": tc.Total})
+			}))
+			defer srv.Close()
+
+			var records []int
+			if err := newClient(srv.URL).all("/flows", nil, &records); err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != tc.Records || requests != tc.Requests {
+				t.Fatalf("bad: %d records in %d requests", len(records), requests)
+			}
+			for i, r := range records {
+				if r != i {
+					t.Fatalf("bad record %d: %d", i, r)
+				}
+			}
+		})
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	cases := []struct {
+		Name string
+		Args []string
+		Body string
+		Exit int
+	}{
+		{"flow", []string{"run", "flow", "1", "-json"}, `{"passed": false, "steps": [{"name": "login", "status": "failed"}]}`, exitFailed},
+		{"suite", []string{"run", "suite", "1", "-json"},
+			`{"event": "flow", "flow": {"flow_id": 1, "name": "login", "passed": true}}` + "\n" +
+				`{"event": "report", "report": {"passed": true, "total": 1, "succeeded": 1}}` + "\n", exitPassed},
+		{"tag", []string{"run", "tag", "smoke", "-json"},
+			`{"event": "report", "report": {"passed": true, "total": 0}}` + "\n", exitPassed},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.Body)
+			}))
+			defer srv.Close()
+
+			var out, errOut bytes.Buffer
+			args := append([]string{"-server", srv.URL}, tc.Args...)
+			if exit := run(args, &out, &errOut); exit != tc.Exit {
+				t.Fatalf("bad exit %d, want %d: %s%s", exit, tc.Exit, out.String(), errOut.String())
+			}
+			dec := json.NewDecoder(&out)
+			var report map[string]interface{}
+			if err := dec.Decode(&report); err != nil {
+				t.Fatalf("stdout is not json: %v: %s", err, out.String())
+			}
+			if dec.More() {
+				t.Fatalf("trailing output: %s", out.String())
+			}
+			if _, ok := report["passed"]; !ok {
+				t.Fatalf("bad report: %#v", report)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+// listing holds the flags shared by the list subcommands
+type listing struct {
+	page, pagesize int
+	order          string
+	name, tag      string
+	api, host      string
+	status         string
+	json           bool
+}
+
+func (l *listing) register(fs *flag.FlagSet) {
+	fs.IntVar(&l.page, "page", 0, "page requested")
+	fs.IntVar(&l.pagesize, "pagesize", 20, "number of records in a page")
+	fs.StringVar(&l.order, "order", "", "db sort order column")
+	fs.StringVar(&l.name, "name", "", "records whose name contains this")
+	fs.StringVar(&l.tag, "tag", "", "records carrying this tag")
+	fs.StringVar(&l.api, "api", "", "records with a step of this api, REST or GRPC")
+	fs.StringVar(&l.host, "host", "", "records with a step calling this host")
+	fs.StringVar(&l.status, "status", "", "records whose latest run has this status")
+	fs.BoolVar(&l.json, "json", false, "print the page as json")
+}
+
+func (l listing) query() url.Values {
+	q := url.Values{}
+	q.Set("page", strconv.Itoa(l.page))
+	q.Set("pagesize", strconv.Itoa(l.pagesize))
+	for k, v := range map[string]string{
+		"order":  l.order,
+		"name":   l.name,
+		"tag":    l.tag,
+		"api":    l.api,
+		"host":   l.host,
+		"status": l.status,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	return q
+}
+
+func listFlows(c client, args []string, out io.Writer) error {
+	fs := newFlags("flows list")
+	var l listing
+	l.register(fs)
+	if _, err := arguments(fs, args, 0, "no arguments"); err != nil {
+		return err
+	}
+	var p page
+	if err := c.do(http.MethodGet, "/flows", l.query(), nil, &p); err != nil {
+		return err
+	}
+	if l.json {
+		return printJSON(out, p)
+	}
+	var flows []fmodel.Flow
+	if err := json.Unmarshal(p.Data, &flows); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tENGINE\tTAGS\tUPDATED")
+	for _, f := range flows {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", f.ID, f.Name, f.Engine, f.Tags.String, f.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintf(w, "page %d, %d of %d flows\n", p.Page, len(flows), p.TotalRecords)
+	return w.Flush()
+}
+
+func getFlow(c client, args []string, out io.Writer) error {
+	return getRecord(c, "flows get", "/flows/", args, out)
+}
+
+func createFlow(c client, args []string, out io.Writer) error {
+	return putRecord(c, "flows create", "/flows", false, args, out, &fmodel.Flow{})
+}
+
+func updateFlow(c client, args []string, out io.Writer) error {
+	return putRecord(c, "flows update", "/flows", true, args, out, &fmodel.Flow{})
+}
+
+func listTestcases(c client, args []string, out io.Writer) error {
+	fs := newFlags("testcases list")
+	var l listing
+	l.register(fs)
+	flowID := fs.Int("flow", 0, "testcases of this flow")
+	if _, err := arguments(fs, args, 0, "no arguments"); err != nil {
+		return err
+	}
+	q := l.query()
+	if *flowID > 0 {
+		q.Set("flow_id", strconv.Itoa(*flowID))
+	}
+	var p page
+	if err := c.do(http.MethodGet, "/testcases", q, nil, &p); err != nil {
+		return err
+	}
+	if l.json {
+		return printJSON(out, p)
+	}
+	var tests []tmodel.Testcase
+	if err := json.Unmarshal(p.Data, &tests); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFLOW\tSTEP\tNAME\tAPI\tMETHOD\tPATH\tTAGS")
+	for _, t := range tests {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.FlowID, t.TestCaseID, t.Name, t.API, t.Method.String, t.Path, t.Tags.String)
+	}
+	fmt.Fprintf(w, "page %d, %d of %d testcases\n", p.Page, len(tests), p.TotalRecords)
+	return w.Flush()
+}
+
+func getTestcase(c client, args []string, out io.Writer) error {
+	return getRecord(c, "testcases get", "/testcases/", args, out)
+}
+
+func createTestcase(c client, args []string, out io.Writer) error {
+	return putRecord(c, "testcases create", "/testcases", false, args, out, &tmodel.Testcase{})
+}
+
+func updateTestcase(c client, args []string, out io.Writer) error {
+	return putRecord(c, "testcases update", "/testcases", true, args, out, &tmodel.Testcase{})
+}
+
+// getRecord prints a single record as json
+func getRecord(c client, name, path string, args []string, out io.Writer) error {
+	positional, err := arguments(newFlags(name), args, 1, "an id")
+	if err != nil {
+		return err
+	}
+	id, err := identifier(positional[0])
+	if err != nil {
+		return err
+	}
+	var record json.RawMessage
+	if err = c.do(http.MethodGet, path+strconv.Itoa(id), nil, nil, &record); err != nil {
+		return err
+	}
+	return printJSON(out, record)
+}
+
+// putRecord creates a record read from a json file or, when updating,
+// replaces the record having the id given. The file is decoded into record
+// first so that mistakes are reported before reaching the server.
+func putRecord(c client, name, path string, update bool, args []string, out io.Writer, record interface{}) error {
+	fs := newFlags(name)
+	file := fs.String("f", "", "json file of the record, - for stdin")
+	n, names := 0, "no arguments"
+	if update {
+		n, names = 1, "an id"
+	}
+	positional, err := arguments(fs, args, n, names)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%s expects a file given with -f", name)
+	}
+	if err = readFile(*file, record); err != nil {
+		return err
+	}
+	if !update {
+		if err = c.do(http.MethodPost, path, nil, record, record); err != nil {
+			return err
+		}
+		return printJSON(out, record)
+	}
+	id, err := identifier(positional[0])
+	if err != nil {
+		return err
+	}
+	if err = c.do(http.MethodPut, path+"/"+strconv.Itoa(id), nil, record, record); err != nil {
+		return err
+	}
+	return printJSON(out, record)
+}
+
+// readFile decodes a json file, - being stdin
+func readFile(name string, v interface{}) error {
+	var (
+		data []byte
+		err  error
+	)
+	if name == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid json in %s: %v", name, err)
+	}
+	return nil
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
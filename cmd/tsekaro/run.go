@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thejasn/tester/core/stream"
+	"github.com/thejasn/tester/service"
+)
+
+// runEvent is a line of a streamed run
+type runEvent struct {
+	Event   string                   `json:"event"`
+	Flow    *service.SuiteFlowResult `json:"flow"`
+	Report  *service.SuiteReport     `json:"report"`
+	Message string                   `json:"message"`
+}
+
+// runOptions holds the flags of the run subcommands
+type runOptions struct {
+	env     string
+	timeout string
+	json    bool
+}
+
+func (o *runOptions) register(fs *flag.FlagSet, env bool) {
+	if env {
+		fs.StringVar(&o.env, "env", "", "environment to run in")
+	}
+	fs.StringVar(&o.timeout, "timeout", "", "deadline of the run e.g. 5m")
+	fs.BoolVar(&o.json, "json", false, "print the report as json")
+}
+
+func (o runOptions) query() url.Values {
+	q := url.Values{}
+	if o.env != "" {
+		q.Set("environment", o.env)
+	}
+	if o.timeout != "" {
+		q.Set("timeout", o.timeout)
+	}
+	return q
+}
+
+func runFlow(c client, args []string, out io.Writer) error {
+	return runOne(c, "run flow", "/flows/execute/", "flow", true, args, out)
+}
+
+func runTestcase(c client, args []string, out io.Writer) error {
+	return runOne(c, "run testcase", "/testcases/execute/", "testcase", false, args, out)
+}
+
+// runOne runs a flow or a testcase and prints every step of its report.
+// Progress goes to stderr, leaving stdout to the report.
+func runOne(c client, name, path, kind string, env bool, args []string, out io.Writer) error {
+	fs := newFlags(name)
+	var o runOptions
+	o.register(fs, env)
+	positional, err := arguments(fs, args, 1, "an id")
+	if err != nil {
+		return err
+	}
+	id, err := identifier(positional[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "running %s %d\n", kind, id)
+	var report stream.Report
+	if err = c.do(http.MethodGet, path+strconv.Itoa(id), o.query(), nil, &report); err != nil {
+		return err
+	}
+	if o.json {
+		if err = printJSON(out, report); err != nil {
+			return err
+		}
+	} else {
+		printSteps(out, report.Steps, "  ", true)
+		if report.Message != "" {
+			fmt.Fprintf(out, "  %s\n", report.Message)
+		}
+		fmt.Fprintf(out, "%s %d %s in %s\n", kind, id, outcome(report.Passed), round(report.Duration))
+	}
+	if !report.Passed {
+		return errFailed
+	}
+	return nil
+}
+
+func runSuite(c client, args []string, out io.Writer) error {
+	fs := newFlags("run suite")
+	var o runOptions
+	o.register(fs, true)
+	positional, err := arguments(fs, args, 1, "an id")
+	if err != nil {
+		return err
+	}
+	id, err := identifier(positional[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "running suite %d\n", id)
+	return runMany(c, "/suites/execute/"+strconv.Itoa(id), o.query(), o, out)
+}
+
+func runTag(c client, args []string, out io.Writer) error {
+	fs := newFlags("run tag")
+	var o runOptions
+	o.register(fs, true)
+	positional, err := arguments(fs, args, 1, "a tag")
+	if err != nil {
+		return err
+	}
+	q := o.query()
+	q.Set("tag", positional[0])
+	fmt.Fprintf(os.Stderr, "running flows tagged %s\n", positional[0])
+	return runMany(c, "/flows/execute", q, o, out)
+}
+
+// runMany streams a suite or tagged run, printing each flow as soon as it
+// finishes and the failed steps of those which did not pass
+func runMany(c client, path string, q url.Values, o runOptions, out io.Writer) error {
+	q.Set("stream", "true")
+	res, err := c.send(http.MethodGet, path, q, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+	for {
+		var e runEvent
+		if err = dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				return errors.New("the run ended without a report")
+			}
+			return err
+		}
+		switch e.Event {
+		case "flow":
+			if !o.json && e.Flow != nil {
+				printFlow(out, *e.Flow)
+			}
+		case "error":
+			return errors.New(e.Message)
+		case "report":
+			if e.Report == nil {
+				return errors.New("the run ended without a report")
+			}
+			return printSuite(out, *e.Report, o)
+		}
+	}
+}
+
+// printSuite prints the outcome of a suite or tagged run
+func printSuite(out io.Writer, r service.SuiteReport, o runOptions) error {
+	if o.json {
+		if err := printJSON(out, r); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(out, "%d flows, %d passed, %d failed in %s\n", r.Total, r.Succeeded, r.Failed, round(r.Duration))
+	}
+	if !r.Passed {
+		return errFailed
+	}
+	return nil
+}
+
+func printFlow(out io.Writer, f service.SuiteFlowResult) {
+	var d time.Duration
+	if f.Report != nil {
+		d = f.Report.Duration
+	}
+	fmt.Fprintf(out, "%-7s %s (flow %d) %s\n", outcome(f.Passed), f.Name, f.FlowID, round(d))
+	if f.Error != "" {
+		fmt.Fprintf(out, "  %s\n", f.Error)
+	}
+	if f.Report != nil && !f.Passed {
+		printSteps(out, f.Report.Steps, "  ", false)
+		if f.Report.Message != "" {
+			fmt.Fprintf(out, "  %s\n", f.Report.Message)
+		}
+	}
+}
+
+// printSteps prints the steps of a report, only those which did not pass
+// unless all is set, along with why they did not
+func printSteps(out io.Writer, steps []stream.StepResult, indent string, all bool) {
+	for _, s := range steps {
+		bad := s.Status == stream.Failed || s.Status == stream.Errored || s.Status == stream.TimedOut
+		if all || bad {
+			name := s.Name
+			if s.Phase != "" && s.Phase != stream.Main {
+				name = string(s.Phase) + ": " + name
+			}
+			if s.Index != nil {
+				name += "[" + strconv.Itoa(*s.Index) + "]"
+			}
+			fmt.Fprintf(out, "%s%-7s %s %s\n", indent, s.Status, name, round(s.Duration))
+			if s.Message != "" && (bad || s.Status == stream.Skipped) {
+				fmt.Fprintf(out, "%s  %s\n", indent, strings.Replace(s.Message, "\n", "\n"+indent+"  ", -1))
+			}
+			for _, v := range s.Violations {
+				fmt.Fprintf(out, "%s  %s: %s (%s)\n", indent, v.Pointer, v.Message, v.Keyword)
+			}
+		}
+		printSteps(out, s.Steps, indent+"  ", all)
+	}
+}
+
+func outcome(passed bool) string {
+	if passed {
+		return "PASSED"
+	}
+	return "FAILED"
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
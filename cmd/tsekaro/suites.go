@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/guregu/null"
+
+	amodel "github.com/thejasn/tester/domain/auth/model"
+	smodel "github.com/thejasn/tester/domain/suite/model"
+	"github.com/thejasn/tester/service"
+)
+
+func listSuites(c client, args []string, out io.Writer) error {
+	fs := newFlags("suites list")
+	pageNo := fs.Int("page", 0, "page requested")
+	pagesize := fs.Int("pagesize", 20, "number of records in a page")
+	asJSON := fs.Bool("json", false, "print the page as json")
+	if _, err := arguments(fs, args, 0, "no arguments"); err != nil {
+		return err
+	}
+	q := url.Values{}
+	q.Set("page", strconv.Itoa(*pageNo))
+	q.Set("pagesize", strconv.Itoa(*pagesize))
+	var p page
+	if err := c.do(http.MethodGet, "/suites", q, nil, &p); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(out, p)
+	}
+	var suites []smodel.Suite
+	if err := json.Unmarshal(p.Data, &suites); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tFLOWS\tPARALLEL\tENVIRONMENT\tTAGS")
+	for _, s := range suites {
+		fmt.Fprintf(w, "%d\t%s\t%v\t%t\t%s\t%s\n", s.ID, s.Name, s.FlowIDs, s.Parallel, s.Environment.String, s.Tags.String)
+	}
+	fmt.Fprintf(w, "page %d, %d of %d suites\n", p.Page, len(suites), p.TotalRecords)
+	return w.Flush()
+}
+
+// exportSuite writes a suite along with its flows, their testcases and the
+// flows they call as sub-flows. Auth profiles, schemas and protosets are
+// left out, they are referred to by id and expected to exist where the
+// suite is imported. With -auth the auth profiles of the flows are written
+// too, for the suite to be run locally, their credentials being read from
+// the given file since the server never returns them.
+func exportSuite(c client, args []string, out io.Writer) error {
+	fs := newFlags("suites export")
+	file := fs.String("o", "", "file to write the suite to, stdout by default")
+	auth := fs.String("auth", "", "json file of the credentials of the auth profiles of the flows, to include the profiles")
+	positional, err := arguments(fs, args, 1, "an id")
+	if err != nil {
+		return err
+	}
+	id, err := identifier(positional[0])
+	if err != nil {
+		return err
+	}
+
+	var b service.SuiteFile
+	if err = c.do(http.MethodGet, "/suites/"+strconv.Itoa(id), nil, nil, &b.Suite); err != nil {
+		return err
+	}
+	queue := append([]int(nil), b.Suite.FlowIDs...)
+	seen := map[int]bool{}
+	for len(queue) > 0 {
+		flowID := queue[0]
+		queue = queue[1:]
+		if seen[flowID] {
+			continue
+		}
+		seen[flowID] = true
+
+		var f service.SuiteFileFlow
+		if err = c.do(http.MethodGet, "/flows/"+strconv.Itoa(flowID), nil, nil, &f.Flow); err != nil {
+			return err
+		}
+		q := url.Values{}
+		q.Set("flow_id", strconv.Itoa(flowID))
+		q.Set("order", "test_case_id")
+		if err = c.all("/testcases", q, &f.Testcases); err != nil {
+			return err
+		}
+		for _, t := range f.Testcases {
+			if t.SubflowID.Valid {
+				queue = append(queue, int(t.SubflowID.Int64))
+			}
+		}
+		b.Flows = append(b.Flows, f)
+	}
+	if *auth != "" {
+		var credentials []amodel.Profile
+		if err = readFile(*auth, &credentials); err != nil {
+			return err
+		}
+		if b.AuthProfiles, err = authProfiles(c, b.Flows, credentials); err != nil {
+			return err
+		}
+	}
+
+	if *file == "" {
+		return printJSON(out, b)
+	}
+	w, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	if err = printJSON(w, b); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "exported suite %d with %d flows to %s\n", id, len(b.Flows), *file)
+	if len(b.AuthProfiles) > 0 {
+		fmt.Fprintf(out, "the file holds the credentials of %d auth profiles\n", len(b.AuthProfiles))
+	}
+	return nil
+}
+
+// authProfiles fetches the auth profiles of flows, which the server returns
+// without their credentials, and sets those of credentials, profiles
+// matched by id
+func authProfiles(c client, flows []service.SuiteFileFlow, credentials []amodel.Profile) ([]amodel.Profile, error) {
+	byID := make(map[int]amodel.Profile, len(credentials))
+	for _, p := range credentials {
+		byID[p.ID] = p
+	}
+	seen := map[int64]bool{}
+	var profiles []amodel.Profile
+	for _, f := range flows {
+		id := f.Flow.AuthProfileID
+		if !id.Valid || seen[id.Int64] {
+			continue
+		}
+		seen[id.Int64] = true
+		var p amodel.Profile
+		if err := c.do(http.MethodGet, "/auth-profiles/"+strconv.FormatInt(id.Int64, 10), nil, nil, &p); err != nil {
+			return nil, fmt.Errorf("could not export auth profile %d: %w", id.Int64, err)
+		}
+		cred, ok := byID[p.ID]
+		if !ok {
+			return nil, fmt.Errorf("no credentials given for auth profile %d %q", p.ID, p.Name)
+		}
+		p.Password, p.Token, p.KeyValue, p.ClientSecret = cred.Password, cred.Token, cred.KeyValue, cred.ClientSecret
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// importSuite creates the flows of a suite file, then their testcases and
+// finally the suite, rewriting the ids they refer each other by. Records created before a failure are left in place
+// and listed so that they can be removed.
+func importSuite(c client, args []string, out io.Writer) error {
+	fs := newFlags("suites import")
+	file := fs.String("f", "", "json file of an exported suite, - for stdin")
+	if _, err := arguments(fs, args, 0, "no arguments"); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("suites import expects a file given with -f")
+	}
+	var b service.SuiteFile
+	if err := readFile(*file, &b); err != nil {
+		return err
+	}
+
+	ids := make(map[int]int, len(b.Flows))
+	for _, bf := range b.Flows {
+		f := bf.Flow
+		old := f.ID
+		f.ID = 0
+		if err := c.do(http.MethodPost, "/flows", nil, &f, &f); err != nil {
+			return imported(out, ids, fmt.Errorf("could not import flow %q: %w", bf.Flow.Name, err))
+		}
+		ids[old] = f.ID
+	}
+	for _, bf := range b.Flows {
+		for _, t := range bf.Testcases {
+			t.ID = 0
+			t.FlowID = ids[bf.Flow.ID]
+			if t.SubflowID.Valid {
+				if id, ok := ids[int(t.SubflowID.Int64)]; ok {
+					t.SubflowID = null.IntFrom(int64(id))
+				}
+			}
+			if err := c.do(http.MethodPost, "/testcases", nil, &t, &t); err != nil {
+				return imported(out, ids, fmt.Errorf("could not import testcase %q of flow %q: %w", t.Name, bf.Flow.Name, err))
+			}
+		}
+	}
+
+	s := b.Suite
+	s.ID = 0
+	for i, id := range s.FlowIDs {
+		if n, ok := ids[id]; ok {
+			s.FlowIDs[i] = n
+		}
+	}
+	if err := c.do(http.MethodPost, "/suites", nil, &s, &s); err != nil {
+		return imported(out, ids, fmt.Errorf("could not import suite %q: %w", b.Suite.Name, err))
+	}
+	fmt.Fprintf(out, "imported suite %q as %d with %d flows\n", s.Name, s.ID, len(ids))
+	return nil
+}
+
+// imported lists the flows created by an import which failed
+func imported(out io.Writer, ids map[int]int, err error) error {
+	for old, id := range ids {
+		fmt.Fprintf(out, "created flow %d from %d\n", id, old)
+	}
+	return err
+}
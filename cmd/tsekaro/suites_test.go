@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/guregu/null"
+
+	amodel "github.com/thejasn/tester/domain/auth/model"
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	"github.com/thejasn/tester/service"
+)
+
+func TestAuthProfiles(t *testing.T) {
+	flows := []service.SuiteFileFlow{
+		{Flow: fmodel.Flow{ID: 1, AuthProfileID: null.IntFrom(2)}},
+		{Flow: fmodel.Flow{ID: 2, AuthProfileID: null.IntFrom(2)}},
+		{Flow: fmodel.Flow{ID: 3}},
+	}
+	cases := []struct {
+		Name        string
+		Credentials []amodel.Profile
+		Password    string
+		Err         bool
+	}{
+		{"matched", []amodel.Profile{{ID: 2, Password: null.StringFrom("s3cret")}}, "s3cret", false},
+		{"missing", []amodel.Profile{{ID: 5, Password: null.StringFrom("s3cret")}}, "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if !strings.HasSuffix(r.URL.Path, "/auth-profiles/2") {
+					http.NotFound(w, r)
+					return
+				}
+				// the server never returns the credentials
+				fmt.Fprint(w, `{"id": 2, "name": "staging", "type": "basic", "username": "bot"}`)
+			}))
+			defer srv.Close()
+
+			profiles, err := authProfiles(newClient(srv.URL), flows, tc.Credentials)
+			if tc.Err {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", profiles)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requests != 1 || len(profiles) != 1 {
+				t.Fatalf("%d requests, %d profiles", requests, len(profiles))
+			}
+			if p := profiles[0]; p.Username.String != "bot" || p.Password.String != tc.Password {
+				t.Fatalf("bad profile %#v", p)
+			}
+		})
+	}
+}
@@ -3,32 +3,100 @@ package asserter
 import (
 	"fmt"
 
-	"github.com/google/go-cmp/cmp"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/thejasn/tester/core/expr"
 )
 
 type Operations interface {
 	Assert() (bool, string)
 }
 
+// Assertion compares Actual with Expected, Options tunes how documents are
+// compared by EQUAL and SNAPSHOT
 type Assertion struct {
 	Expected interface{}
 	Actual   interface{}
 	Operator string
+	Options  DiffOptions
 }
 
 var (
 	Equal = "EQUAL"
+	// Schema validates Actual against the compiled schema in Expected
+	Schema = "SCHEMA"
+	// Snapshot compares Actual with the Golden in Expected
+	Snapshot = "SNAPSHOT"
+	// Expression evaluates the compiled expression in Expected against the
+	// expr.Env in Actual
+	Expression = "EXPRESSION"
 )
 
+// Result is the detailed outcome of an assertion, Violations lists every
+// reason a value does not conform to a schema or differs from what was
+// expected
+type Result struct {
+	Passed     bool
+	Message    string
+	Violations []Violation
+}
+
 func (a Assertion) Assert() (bool, string) {
+	r := a.Evaluate()
+	return r.Passed, r.Message
+}
+
+func (a Assertion) Evaluate() Result {
 	switch a.Operator {
 	case Equal:
-		if cmp.Equal(a.Expected, a.Actual) {
-			return true, ""
-		} else {
-			return false, fmt.Sprintf("expected %v but found %v", a.Expected, a.Actual)
+		diffs := Diff(a.Expected, a.Actual, a.Options)
+		if len(diffs) == 0 {
+			return Result{Passed: true}
+		}
+		if len(diffs) == 1 && diffs[0].Pointer == "" {
+			return Result{Message: diffs[0].Message}
+		}
+		return Result{Message: Summarize("equality", diffs), Violations: diffs}
+	case Schema:
+		s, ok := a.Expected.(*jsonschema.Schema)
+		if !ok {
+			return Result{Message: "invalid schema"}
+		}
+		violations, err := Validate(s, a.Actual)
+		if err != nil {
+			return Result{Message: err.Error()}
+		}
+		if len(violations) == 0 {
+			return Result{Passed: true}
+		}
+		return Result{Message: Summarize("schema", violations), Violations: violations}
+	case Snapshot:
+		g, ok := a.Expected.(Golden)
+		if !ok {
+			return Result{Message: "invalid snapshot"}
+		}
+		if !g.Recorded {
+			return Result{Passed: true}
+		}
+		diffs := Diff(g.Body, a.Actual, a.Options)
+		if len(diffs) == 0 {
+			return Result{Passed: true}
+		}
+		return Result{Message: Summarize("snapshot", diffs), Violations: diffs}
+	case Expression:
+		e, ok := a.Expected.(*expr.Expr)
+		env, eok := a.Actual.(expr.Env)
+		if !ok || !eok {
+			return Result{Message: "invalid expression"}
+		}
+		holds, err := e.Bool(env)
+		if err != nil {
+			return Result{Message: fmt.Sprintf("could not evaluate %q: %v", e, err)}
+		}
+		if !holds {
+			return Result{Message: fmt.Sprintf("expression %q does not hold", e)}
 		}
+		return Result{Passed: true}
 	default:
-		return false, "invalid operator"
+		return Result{Message: "invalid operator"}
 	}
 }
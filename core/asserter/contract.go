@@ -0,0 +1,150 @@
+package asserter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/thejasn/tester/core/reflect"
+	"google.golang.org/genproto/googleapis/api/annotations"
+)
+
+// CheckContract checks a message against its descriptor. Fields required
+// by convention, either proto2 required or annotated with
+// (google.api.field_behavior) = REQUIRED, must be set, enum values must be
+// known, no unknown field may be present and every google.protobuf.Any
+// must be resolvable, resolved payloads are checked in turn. Pointers use
+// the json names of fields, as rendered in responses.
+func CheckContract(m *dynamic.Message, resolver jsonpb.AnyResolver) []Violation {
+	c := contract{resolver: resolver}
+	c.message("", m)
+	return c.out
+}
+
+type contract struct {
+	resolver jsonpb.AnyResolver
+	out      []Violation
+}
+
+func (c *contract) add(ptr, keyword, format string, args ...interface{}) {
+	c.out = append(c.out, Violation{Pointer: ptr, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *contract) message(ptr string, m *dynamic.Message) {
+	md := m.GetMessageDescriptor()
+	for _, n := range m.GetUnknownFields() {
+		c.add(ptr, "unknown", "unknown field %d in %s", n, md.GetFullyQualifiedName())
+	}
+	if md.GetFullyQualifiedName() == "google.protobuf.Any" {
+		c.any(ptr, m)
+		return
+	}
+	for _, fd := range md.GetFields() {
+		fptr := ptr + "/" + escape(fd.GetJSONName())
+		if !m.HasField(fd) {
+			if required(fd) {
+				c.add(fptr, "required", "required field %s is missing", fd.GetName())
+			}
+			continue
+		}
+		v := m.GetField(fd)
+		switch {
+		case fd.IsMap():
+			entries := v.(map[interface{}]interface{})
+			keys := make([]string, 0, len(entries))
+			values := make(map[string]interface{}, len(entries))
+			for k, e := range entries {
+				key := fmt.Sprint(k)
+				keys = append(keys, key)
+				values[key] = e
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				c.value(fptr+"/"+escape(k), fd.GetMapValueType(), values[k])
+			}
+		case fd.IsRepeated():
+			for i, e := range v.([]interface{}) {
+				c.value(fmt.Sprintf("%s/%d", fptr, i), fd, e)
+			}
+		default:
+			c.value(fptr, fd, v)
+		}
+	}
+}
+
+func (c *contract) value(ptr string, fd *desc.FieldDescriptor, v interface{}) {
+	if et := fd.GetEnumType(); et != nil {
+		if n, ok := v.(int32); ok && et.FindValueByNumber(n) == nil {
+			c.add(ptr, "enum", "unknown value %d of enum %s", n, et.GetFullyQualifiedName())
+		}
+		return
+	}
+	if fd.GetMessageType() == nil {
+		return
+	}
+	pm, ok := v.(proto.Message)
+	if !ok {
+		return
+	}
+	m, err := dynamic.AsDynamicMessage(pm)
+	if err != nil {
+		c.add(ptr, "message", "could not inspect %s: %v", fd.GetMessageType().GetFullyQualifiedName(), err)
+		return
+	}
+	c.message(ptr, m)
+}
+
+// any resolves the payload of a google.protobuf.Any and checks it
+func (c *contract) any(ptr string, m *dynamic.Message) {
+	typeURL, _ := m.GetFieldByName("type_url").(string)
+	value, _ := m.GetFieldByName("value").([]byte)
+	if typeURL == "" && len(value) == 0 {
+		return
+	}
+	resolved, err := c.resolver.Resolve(typeURL)
+	if err != nil || reflect.IsUnresolvedAny(resolved) {
+		c.add(ptr, "any", "type %q can not be resolved", typeURL)
+		return
+	}
+	if err := proto.Unmarshal(value, resolved); err != nil {
+		c.add(ptr, "any", "payload of %q is corrupt: %v", typeURL, err)
+		return
+	}
+	payload, err := dynamic.AsDynamicMessage(resolved)
+	if err != nil {
+		c.add(ptr, "any", "could not inspect %q: %v", typeURL, err)
+		return
+	}
+	c.message(ptr, payload)
+}
+
+// required reports whether a field is required by convention
+func required(fd *desc.FieldDescriptor) bool {
+	if fd.IsRequired() {
+		return true
+	}
+	opts := fd.GetFieldOptions()
+	if opts == nil || !proto.HasExtension(opts, annotations.E_FieldBehavior) {
+		return false
+	}
+	ext, err := proto.GetExtension(opts, annotations.E_FieldBehavior)
+	if err != nil {
+		return false
+	}
+	behaviors, _ := ext.([]annotations.FieldBehavior)
+	for _, b := range behaviors {
+		if b == annotations.FieldBehavior_REQUIRED {
+			return true
+		}
+	}
+	return false
+}
+
+// escape encodes a reference token of a json pointer
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,118 @@
+package asserter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+	treflect "github.com/thejasn/tester/core/reflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const orderProto = `
+syntax = "proto2";
+package test;
+
+import "google/protobuf/any.proto";
+
+enum State {
+  OPEN = 0;
+  CLOSED = 1;
+}
+
+message Order {
+  required string id = 1;
+  optional State state = 2;
+  repeated Item items = 3;
+  optional google.protobuf.Any detail = 4;
+}
+
+message Item {
+  optional string sku = 1;
+  optional State state = 2;
+}
+
+message Newer {
+  optional string id = 1;
+  optional int32 extra = 9;
+}
+`
+
+func TestCheckContract(t *testing.T) {
+	p := protoparse.Parser{Accessor: protoparse.FileContentsFromMap(map[string]string{"order.proto": orderProto})}
+	fds, err := p.ParseFiles("order.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd := fds[0]
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, f := range append(fd.GetDependencies(), fd) {
+		set.File = append(set.File, f.AsFileDescriptorProto())
+	}
+	source, err := treflect.DescriptorSourceFromFileDescriptorSet(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := treflect.AnyResolverFromDescriptorSourceWithFallback(source)
+	md := func(name string) *desc.MessageDescriptor {
+		return fd.FindMessage("test." + name)
+	}
+
+	if got, want := CheckContract(dynamic.NewMessage(md("Order")), resolver), []Violation{
+		{Pointer: "/id", Keyword: "required", Message: "required field id is missing"},
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v but found %+v", want, got)
+	}
+
+	newer := dynamic.NewMessage(md("Newer"))
+	newer.SetFieldByName("id", "1")
+	newer.SetFieldByName("extra", int32(3))
+	b, err := newer.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := dynamic.NewMessage(md("Order"))
+	if err := order.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	order.SetFieldByName("state", int32(7))
+	item := dynamic.NewMessage(md("Item"))
+	item.SetFieldByName("state", int32(1))
+	order.AddRepeatedFieldByName("items", item)
+	item = dynamic.NewMessage(md("Item"))
+	item.SetFieldByName("state", int32(5))
+	order.AddRepeatedFieldByName("items", item)
+
+	detail := dynamic.NewMessage(md("Order").FindFieldByName("detail").GetMessageType())
+	detail.SetFieldByName("type_url", "type.googleapis.com/test.Missing")
+	detail.SetFieldByName("value", []byte{})
+	order.SetFieldByName("detail", detail)
+
+	want := []Violation{
+		{Pointer: "", Keyword: "unknown", Message: "unknown field 9 in test.Order"},
+		{Pointer: "/state", Keyword: "enum", Message: "unknown value 7 of enum test.State"},
+		{Pointer: "/items/1/state", Keyword: "enum", Message: "unknown value 5 of enum test.State"},
+		{Pointer: "/detail", Keyword: "any", Message: `type "type.googleapis.com/test.Missing" can not be resolved`},
+	}
+	if got := CheckContract(order, resolver); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v but found %+v", want, got)
+	}
+
+	b, err = item.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	detail.SetFieldByName("type_url", "type.googleapis.com/test.Item")
+	detail.SetFieldByName("value", b)
+	want = []Violation{
+		{Pointer: "/detail/state", Keyword: "enum", Message: "unknown value 5 of enum test.State"},
+	}
+	order = dynamic.NewMessage(md("Order"))
+	order.SetFieldByName("id", "1")
+	order.SetFieldByName("detail", detail)
+	if got := CheckContract(order, resolver); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v but found %+v", want, got)
+	}
+}
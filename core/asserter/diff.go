@@ -0,0 +1,184 @@
+package asserter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// DiffOptions tunes the comparison of json documents. Ignore lists JSON
+// pointers whose values are left out of the comparison, a "*" segment
+// matches any key or index e.g. "/items/*/id". IgnoreOrder compares arrays
+// as multisets, their elements are then reported at their sorted index.
+// IgnoreExtra accepts fields of the actual value missing from the expected
+// one and IgnoreNumericType compares numbers by value whatever their type.
+type DiffOptions struct {
+	Ignore            []string
+	IgnoreOrder       bool
+	IgnoreExtra       bool
+	IgnoreNumericType bool
+}
+
+// Golden is the expected value of a snapshot assertion. Until a snapshot is
+// recorded any value is accepted.
+type Golden struct {
+	Body     interface{}
+	Recorded bool
+}
+
+// Diff compares two values decoded from json and lists every path at which
+// they differ, keyed "added", "removed" or "changed"
+func Diff(expected, actual interface{}, o DiffOptions) []Violation {
+	r := &diffReporter{}
+	opts := []cmp.Option{cmp.Reporter(r)}
+	if len(o.Ignore) > 0 {
+		opts = append(opts, cmp.FilterPath(func(p cmp.Path) bool {
+			return ignored(pointer(p), o.Ignore)
+		}, cmp.Ignore()))
+	}
+	if o.IgnoreExtra {
+		opts = append(opts, cmp.FilterPath(func(p cmp.Path) bool {
+			if _, ok := p.Last().(cmp.MapIndex); !ok {
+				return false
+			}
+			vx, _ := p.Last().Values()
+			return !vx.IsValid()
+		}, cmp.Ignore()))
+	}
+	if o.IgnoreOrder {
+		opts = append(opts, cmpopts.SortSlices(func(x, y interface{}) bool {
+			return format(reflect.ValueOf(x)) < format(reflect.ValueOf(y))
+		}))
+	}
+	if o.IgnoreNumericType {
+		opts = append(opts, cmp.FilterValues(func(x, y interface{}) bool {
+			_, okx := number(x)
+			_, oky := number(y)
+			return okx && oky
+		}, cmp.Comparer(func(x, y interface{}) bool {
+			fx, _ := number(x)
+			fy, _ := number(y)
+			return fx == fy
+		})))
+	}
+	cmp.Equal(expected, actual, opts...)
+	return r.diffs
+}
+
+// diffReporter collects the differences found by cmp
+type diffReporter struct {
+	path  cmp.Path
+	diffs []Violation
+}
+
+func (r *diffReporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *diffReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+func (r *diffReporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	vx, vy := r.path.Last().Values()
+	v := Violation{Pointer: pointer(r.path)}
+	switch {
+	case !vx.IsValid():
+		v.Keyword, v.Message = "added", fmt.Sprintf("unexpected %s", format(vy))
+	case !vy.IsValid():
+		v.Keyword, v.Message = "removed", fmt.Sprintf("missing %s", format(vx))
+	default:
+		x, y := format(vx), format(vy)
+		if x == y {
+			x, y = fmt.Sprintf("%s (%s)", x, typeOf(vx)), fmt.Sprintf("%s (%s)", y, typeOf(vy))
+		}
+		v.Keyword, v.Message = "changed", fmt.Sprintf("expected %s but found %s", x, y)
+	}
+	r.diffs = append(r.diffs, v)
+}
+
+// pointer renders the json pointer of a path, indexes of elements present
+// on one side only are those of the side holding them
+func pointer(p cmp.Path) string {
+	var b strings.Builder
+	for _, s := range p {
+		switch t := s.(type) {
+		case cmp.MapIndex:
+			key := strings.NewReplacer("~", "~0", "/", "~1").Replace(fmt.Sprint(t.Key().Interface()))
+			b.WriteString("/" + key)
+		case cmp.SliceIndex:
+			i := t.Key()
+			if i < 0 {
+				if x, y := t.SplitKeys(); x >= 0 {
+					i = x
+				} else {
+					i = y
+				}
+			}
+			fmt.Fprintf(&b, "/%d", i)
+		}
+	}
+	return b.String()
+}
+
+// ignored reports whether the pointer matches one of the patterns
+func ignored(ptr string, patterns []string) bool {
+	segs := strings.Split(ptr, "/")
+	for _, p := range patterns {
+		want := strings.Split(p, "/")
+		if len(want) != len(segs) {
+			continue
+		}
+		match := true
+		for i := range want {
+			if want[i] != "*" && want[i] != segs[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func format(v reflect.Value) string {
+	if !v.CanInterface() {
+		return v.String()
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(b)
+}
+
+// number converts any numeric value to a float64
+func number(v interface{}) (float64, bool) {
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(r.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(r.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return r.Float(), true
+	}
+	return 0, false
+}
+
+// typeOf returns the dynamic type of a value held in an interface
+func typeOf(v reflect.Value) reflect.Type {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return v.Elem().Type()
+	}
+	return v.Type()
+}
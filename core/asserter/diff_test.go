@@ -0,0 +1,147 @@
+package asserter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		opts     DiffOptions
+		want     []Violation
+	}{
+		{
+			name:     "equal",
+			expected: `{"id": 1, "tags": ["a"]}`,
+			actual:   `{"tags": ["a"], "id": 1}`,
+		},
+		{
+			name:     "changed",
+			expected: `{"order": {"id": 1, "status": "OPEN"}}`,
+			actual:   `{"order": {"id": 1, "status": "CLOSED"}}`,
+			want: []Violation{
+				{Pointer: "/order/status", Keyword: "changed", Message: `expected "OPEN" but found "CLOSED"`},
+			},
+		},
+		{
+			name:     "added and removed",
+			expected: `{"id": 1, "name": "a"}`,
+			actual:   `{"id": 1, "total": 2}`,
+			want: []Violation{
+				{Pointer: "/name", Keyword: "removed", Message: `missing "a"`},
+				{Pointer: "/total", Keyword: "added", Message: "unexpected 2"},
+			},
+		},
+		{
+			name:     "array element",
+			expected: `{"items": [{"id": 1}, {"id": 2}]}`,
+			actual:   `{"items": [{"id": 1}]}`,
+			want: []Violation{
+				{Pointer: "/items/1", Keyword: "removed", Message: `missing {"id":2}`},
+			},
+		},
+		{
+			name:     "type changed",
+			expected: `{"id": 1}`,
+			actual:   `{"id": "1"}`,
+			want: []Violation{
+				{Pointer: "/id", Keyword: "changed", Message: `expected 1 but found "1"`},
+			},
+		},
+		{
+			name:     "ignored paths",
+			expected: `{"id": 1, "created_at": "2020-01-01", "items": [{"id": 1, "sku": "a"}]}`,
+			actual:   `{"id": 2, "created_at": "2020-02-02", "items": [{"id": 5, "sku": "a"}]}`,
+			opts:     DiffOptions{Ignore: []string{"/id", "/created_at", "/items/*/id"}},
+		},
+		{
+			name:     "array order",
+			expected: `{"tags": ["a", "b", {"id": 1}]}`,
+			actual:   `{"tags": [{"id": 1}, "b", "a"]}`,
+			opts:     DiffOptions{IgnoreOrder: true},
+		},
+		{
+			name:     "array order mismatch",
+			expected: `["a", "b"]`,
+			actual:   `["c", "a"]`,
+			opts:     DiffOptions{IgnoreOrder: true},
+			want: []Violation{
+				{Pointer: "/1", Keyword: "changed", Message: `expected "b" but found "c"`},
+			},
+		},
+		{
+			name:     "extra fields",
+			expected: `{"order": {"id": 1}}`,
+			actual:   `{"order": {"id": 1, "total": 2}, "meta": {}}`,
+			opts:     DiffOptions{IgnoreExtra: true},
+		},
+		{
+			name:     "extra fields still missing",
+			expected: `{"id": 1, "name": "a"}`,
+			actual:   `{"id": 1, "total": 2}`,
+			opts:     DiffOptions{IgnoreExtra: true},
+			want: []Violation{
+				{Pointer: "/name", Keyword: "removed", Message: `missing "a"`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected, actual interface{}
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.actual), &actual); err != nil {
+				t.Fatal(err)
+			}
+			if got := Diff(expected, actual, tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		opts     DiffOptions
+		want     Result
+	}{
+		{
+			name:     "scalar",
+			expected: "OPEN",
+			actual:   "CLOSED",
+			want:     Result{Message: `expected "OPEN" but found "CLOSED"`},
+		},
+		{
+			name:     "numeric type",
+			expected: map[string]interface{}{"count": 2},
+			actual:   map[string]interface{}{"count": 2.0},
+			want: Result{
+				Message:    `1 equality violation(s), first at "/count": expected 2 (int) but found 2 (float64)`,
+				Violations: []Violation{{Pointer: "/count", Keyword: "changed", Message: "expected 2 (int) but found 2 (float64)"}},
+			},
+		},
+		{
+			name:     "numeric type ignored",
+			expected: map[string]interface{}{"count": 2},
+			actual:   map[string]interface{}{"count": 2.0},
+			opts:     DiffOptions{IgnoreNumericType: true},
+			want:     Result{Passed: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assertion{Expected: tt.expected, Actual: tt.actual, Operator: Equal, Options: tt.opts}
+			if got := a.Evaluate(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package asserter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Violation is a single reason a value does not conform to a schema.
+// Pointer is the json pointer of the offending value, empty for the root,
+// and Keyword the location of the failing keyword within the schema.
+type Violation struct {
+	Pointer string `json:"pointer"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// Summarize describes violations of the given kind in a single line
+func Summarize(kind string, violations []Violation) string {
+	return fmt.Sprintf("%d %s violation(s), first at %q: %s", len(violations), kind, violations[0].Pointer, violations[0].Message)
+}
+
+// CompileSchema compiles a json schema. The draft is picked from $schema,
+// draft 7 and 2020-12 among others, and defaults to 2020-12. References to
+// external documents are not resolved.
+func CompileSchema(name string, src []byte) (*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	c.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external schema %q is not allowed", url)
+	}
+	if err := c.AddResource(name, bytes.NewReader(src)); err != nil {
+		return nil, err
+	}
+	return c.Compile(name)
+}
+
+// Validate returns every violation of v against the schema, v is a value
+// decoded from json
+func Validate(s *jsonschema.Schema, v interface{}) ([]Violation, error) {
+	err := s.Validate(v)
+	if err == nil {
+		return nil, nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return nil, err
+	}
+	var out []Violation
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			out = append(out, Violation{Pointer: e.InstanceLocation, Keyword: e.KeywordLocation, Message: e.Message})
+			return
+		}
+		for _, c := range e.Causes {
+			walk(c)
+		}
+	}
+	walk(ve)
+	return out, nil
+}
@@ -0,0 +1,79 @@
+package asserter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []Violation
+	}{
+		{
+			name:   "valid",
+			schema: `{"type": "object", "required": ["id"]}`,
+			value:  `{"id": 1}`,
+		},
+		{
+			name: "draft 7",
+			schema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"required": ["id", "name"],
+				"properties": {"tags": {"type": "array", "items": {"type": "string"}}}
+			}`,
+			value: `{"id": 1, "tags": ["a", 2]}`,
+			want: []Violation{
+				{Pointer: "", Keyword: "/required", Message: "missing properties: 'name'"},
+				{Pointer: "/tags/1", Keyword: "/properties/tags/items/type", Message: "expected string, but got number"},
+			},
+		},
+		{
+			name: "2020-12",
+			schema: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"prefixItems": [{"type": "integer"}],
+				"items": false
+			}`,
+			value: `[1, 2]`,
+			want: []Violation{
+				{Pointer: "/1", Keyword: "/items", Message: "not allowed"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := CompileSchema("schema.json", []byte(tt.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v interface{}
+			if err := json.Unmarshal([]byte(tt.value), &v); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Validate(s, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v but found %+v", tt.want, got)
+			}
+
+			r := Assertion{Expected: s, Actual: v, Operator: Schema}.Evaluate()
+			if r.Passed != (len(tt.want) == 0) || !reflect.DeepEqual(r.Violations, tt.want) {
+				t.Fatalf("unexpected result %+v", r)
+			}
+		})
+	}
+}
+
+func TestSchemaExternalRef(t *testing.T) {
+	_, err := CompileSchema("schema.json", []byte(`{"$ref": "file:///etc/passwd"}`))
+	if err == nil {
+		t.Fatalf("expected external reference to be rejected")
+	}
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Type identifies the authentication scheme of a Profile
+type Type string
+
+const (
+	Basic                   = Type("basic")
+	Bearer                  = Type("bearer")
+	APIKey                  = Type("apikey")
+	OAuth2ClientCredentials = Type("oauth2_client_credentials")
+	OAuth2Password          = Type("oauth2_password")
+)
+
+// Location decides where an API key is sent
+type Location string
+
+const (
+	InHeader = Location("header")
+	InQuery  = Location("query")
+)
+
+// AuthorizationHeader is the header (and grpc metadata key) used by every
+// scheme except API keys
+const AuthorizationHeader = "Authorization"
+
+var (
+	// ErrUnsupportedType is returned for a profile with an unknown Type
+	ErrUnsupportedType = errors.New("unsupported auth type")
+)
+
+// Profile holds everything required to authenticate a request. Only the
+// fields relevant to Type are read.
+type Profile struct {
+	Type Type
+
+	// Basic and OAuth2 password grant
+	Username string
+	Password string
+
+	// Bearer
+	Token string
+
+	// API key
+	KeyName  string
+	KeyValue string
+	KeyIn    Location
+
+	// OAuth2
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// Credential is a resolved profile, ready to be attached to an outgoing
+// request either as a header or as a query parameter
+type Credential struct {
+	Name  string
+	Value string
+	In    Location
+}
+
+// Resolve turns the profile into a Credential. OAuth2 profiles hit the token
+// endpoint only when no valid token is cached.
+func (p Profile) Resolve(ctx context.Context) (Credential, error) {
+	switch p.Type {
+	case Basic:
+		raw := p.Username + ":" + p.Password
+		return Credential{
+			Name:  AuthorizationHeader,
+			Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(raw)),
+			In:    InHeader,
+		}, nil
+	case Bearer:
+		return bearer(p.Token), nil
+	case APIKey:
+		in := p.KeyIn
+		if in == "" {
+			in = InHeader
+		}
+		if p.KeyName == "" {
+			return Credential{}, fmt.Errorf("api key profile is missing a key name")
+		}
+		return Credential{Name: p.KeyName, Value: p.KeyValue, In: in}, nil
+	case OAuth2ClientCredentials, OAuth2Password:
+		token, err := defaultCache.token(ctx, p)
+		if err != nil {
+			return Credential{}, err
+		}
+		return bearer(token), nil
+	default:
+		return Credential{}, fmt.Errorf("%w: %q", ErrUnsupportedType, p.Type)
+	}
+}
+
+// Metadata returns the credential as a grpc header in "name: value" form.
+// Query parameters have no grpc equivalent and are sent as metadata too.
+func (c Credential) Metadata() string {
+	return strings.ToLower(c.Name) + ": " + c.Value
+}
+
+func bearer(token string) Credential {
+	return Credential{
+		Name:  AuthorizationHeader,
+		Value: "Bearer " + token,
+		In:    InHeader,
+	}
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		Input  Profile
+		Output Credential
+	}{
+		{
+			Input: Profile{Type: Basic, Username: "foo", Password: "bar"},
+			Output: Credential{
+				Name:  "Authorization",
+				Value: "Basic Zm9vOmJhcg==",
+				In:    InHeader,
+			},
+		},
+
+		{
+			Input: Profile{Type: Bearer, Token: "abc"},
+			Output: Credential{
+				Name:  "Authorization",
+				Value: "Bearer abc",
+				In:    InHeader,
+			},
+		},
+
+		{
+			Input: Profile{Type: APIKey, KeyName: "X-Api-Key", KeyValue: "secret"},
+			Output: Credential{
+				Name:  "X-Api-Key",
+				Value: "secret",
+				In:    InHeader,
+			},
+		},
+
+		{
+			Input: Profile{Type: APIKey, KeyName: "key", KeyValue: "secret", KeyIn: InQuery},
+			Output: Credential{
+				Name:  "key",
+				Value: "secret",
+				In:    InQuery,
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		actual, err := tc.Input.Resolve(context.Background())
+		if err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		if actual != tc.Output {
+			t.Fatalf("case %d bad: %#v", i, actual)
+		}
+	}
+}
+
+func TestResolveUnsupported(t *testing.T) {
+	if _, err := (Profile{Type: "digest"}).Resolve(context.Background()); err == nil {
+		t.Fatal("expected error for unsupported type")
+	}
+}
+
+func TestTokenCache(t *testing.T) {
+	var grants []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		grants = append(grants, r.PostForm.Get("grant_type"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "token" + r.PostForm.Get("grant_type"),
+			"refresh_token": "refresh",
+			"expires_in":    60,
+		})
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	c := newTokenCache(srv.Client())
+	c.now = func() time.Time { return now }
+
+	p := Profile{
+		Type:         OAuth2ClientCredentials,
+		TokenURL:     srv.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+	}
+
+	for i := 0; i < 2; i++ {
+		tok, err := c.token(context.Background(), p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok != "tokenclient_credentials" {
+			t.Fatalf("bad token: %s", tok)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	tok, err := c.token(context.Background(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok != "tokenrefresh_token" {
+		t.Fatalf("bad token after expiry: %s", tok)
+	}
+
+	if len(grants) != 2 {
+		t.Fatalf("bad grants: %#v", grants)
+	}
+}
+
+func TestTokenCacheKeepsRefreshToken(t *testing.T) {
+	var refreshed []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		res := map[string]interface{}{"access_token": "token", "expires_in": 60}
+		if r.PostForm.Get("grant_type") == "refresh_token" {
+			refreshed = append(refreshed, r.PostForm.Get("refresh_token"))
+		} else {
+			res["refresh_token"] = "refresh"
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	c := newTokenCache(srv.Client())
+	c.now = func() time.Time { return now }
+	p := Profile{Type: OAuth2ClientCredentials, TokenURL: srv.URL, ClientID: "client", ClientSecret: "secret"}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.token(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	if len(refreshed) != 2 || refreshed[0] != "refresh" || refreshed[1] != "refresh" {
+		t.Fatalf("bad refresh tokens: %#v", refreshed)
+	}
+}
+
+func TestTokenCacheKeys(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, secret, _ := r.BasicAuth()
+		if secret == "slow" {
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token" + secret})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := newTokenCache(srv.Client())
+	profile := func(secret string) Profile {
+		return Profile{Type: OAuth2ClientCredentials, TokenURL: srv.URL, ClientID: "client", ClientSecret: secret}
+	}
+
+	go c.token(context.Background(), profile("slow"))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, secret := range []string{"a", "b", "a"} {
+			tok, err := c.token(context.Background(), profile(secret))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tok != "token"+secret {
+				t.Errorf("bad token for secret %s: %s", secret, tok)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("blocked by the token request of another profile")
+	}
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryDelta renews tokens slightly before they actually expire so that a
+// request doesn't race the token's expiry on the wire
+const expiryDelta = 10 * time.Second
+
+var defaultCache = newTokenCache(&http.Client{Timeout: 10 * time.Second})
+
+type token struct {
+	access  string
+	refresh string
+	expiry  time.Time
+}
+
+func (t token) valid(now time.Time) bool {
+	return t.access != "" && (t.expiry.IsZero() || now.Add(expiryDelta).Before(t.expiry))
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+// tokenCache caches OAuth2 tokens per profile for the lifetime of the
+// process, so every step of every flow sharing a profile reuses one token
+// until it expires. Requests for a profile wait on the token being fetched
+// for it without holding up the other profiles.
+type tokenCache struct {
+	client *http.Client
+	now    func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]*cachedToken
+}
+
+// cachedToken is the token of a profile, mu being held while it is fetched
+type cachedToken struct {
+	mu sync.Mutex
+	token
+}
+
+func newTokenCache(client *http.Client) *tokenCache {
+	return &tokenCache{
+		client: client,
+		now:    time.Now,
+		tokens: make(map[string]*cachedToken),
+	}
+}
+
+// cacheKey identifies the token of a profile, the credentials being hashed
+// so that a changed secret or password gets a token of its own
+func cacheKey(p Profile) string {
+	secrets := sha256.Sum256([]byte(p.ClientSecret + "\x00" + p.Password))
+	return strings.Join([]string{string(p.Type), p.TokenURL, p.ClientID, p.Username, strings.Join(p.Scopes, " "), hex.EncodeToString(secrets[:])}, "|")
+}
+
+// entry returns the cached token of a key, adding an empty one if missing
+func (c *tokenCache) entry(key string) *cachedToken {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.tokens[key]
+	if !ok {
+		e = &cachedToken{}
+		c.tokens[key] = e
+	}
+	return e
+}
+
+func (c *tokenCache) token(ctx context.Context, p Profile) (string, error) {
+	if p.TokenURL == "" {
+		return "", fmt.Errorf("oauth2 profile is missing a token url")
+	}
+	e := c.entry(cacheKey(p))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.valid(c.now()) {
+		return e.access, nil
+	}
+
+	var t token
+	var err error
+	if e.refresh != "" {
+		t, err = c.fetch(ctx, p, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {e.refresh},
+		})
+		if err == nil && t.refresh == "" {
+			// the server may keep the refresh token, leaving it out
+			t.refresh = e.refresh
+		}
+	}
+	if e.refresh == "" || err != nil {
+		t, err = c.fetch(ctx, p, grant(p))
+	}
+	if err != nil {
+		e.token = token{}
+		return "", err
+	}
+	e.token = t
+	return t.access, nil
+}
+
+func grant(p Profile) url.Values {
+	v := url.Values{}
+	switch p.Type {
+	case OAuth2Password:
+		v.Set("grant_type", "password")
+		v.Set("username", p.Username)
+		v.Set("password", p.Password)
+	default:
+		v.Set("grant_type", "client_credentials")
+	}
+	if len(p.Scopes) > 0 {
+		v.Set("scope", strings.Join(p.Scopes, " "))
+	}
+	return v
+}
+
+func (c *tokenCache) fetch(ctx context.Context, p Profile, form url.Values) (token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return token{}, fmt.Errorf("oauth2 token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return token{}, err
+	}
+
+	var tr tokenResponse
+	if err = json.Unmarshal(b, &tr); err != nil {
+		return token{}, fmt.Errorf("oauth2 token response is not json (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || tr.AccessToken == "" {
+		return token{}, fmt.Errorf("oauth2 token request failed with status %d: %s %s", resp.StatusCode, tr.Error, tr.Description)
+	}
+
+	t := token{access: tr.AccessToken, refresh: tr.RefreshToken}
+	if tr.ExpiresIn > 0 {
+		t.expiry = c.now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
@@ -1,12 +1,62 @@
 package client
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+)
+
+// DefaultTimeout bounds a call whose context carries no deadline
+const DefaultTimeout = 10 * time.Second
 
 type Runner interface {
 	GetIdentifier() string
 	Build(context.Context) error
-	Invoke() (string, error)
+	Invoke(context.Context) (Response, error)
 	Clear()
 }
 
+// Response is the outcome of a single invocation. Status holds the http
+// status code for REST and the grpc status code for GRPC. Body is json
+// whenever the protocol allows it, Raw holds the body in the format the
+// request was made in when that differs. Violations are the contract
+// violations found by the runner itself and Timing what the runner measured.
+// Request describes the call made, it is set even when the call failed.
+type Response struct {
+	Status     int
+	Body       string
+	Raw        string
+	Violations []asserter.Violation
+	Timing     Timing
+	Request    *Request
+}
+
+// Request describes a call as made by a runner, for reports. URL is the
+// host and port of the server for GRPC and Method the full name of the rpc.
+// Credentials of auth profiles are left out.
+type Request struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// Timing is the latency of an invocation. FirstByte is the time until the
+// first byte of the response arrived, it is left empty when the protocol
+// doesn't expose it.
+type Timing struct {
+	Total     time.Duration
+	FirstByte time.Duration
+}
+
 type RunnerOpts func(Runner)
+
+// WithDeadline returns ctx unchanged when it already has a deadline and
+// bounds it by DefaultTimeout otherwise
+func WithDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, DefaultTimeout)
+}
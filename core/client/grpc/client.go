@@ -2,11 +2,11 @@ package grpc
 
 import (
 	"context"
-	"fmt"
 	"strings"
-	"time"
 
+	"github.com/thejasn/tester/core/auth"
 	"github.com/thejasn/tester/core/client"
+	"github.com/thejasn/tester/core/reflect"
 
 	"github.com/pkg/errors"
 	"github.com/thejasn/tester/pkg/log"
@@ -14,18 +14,25 @@ import (
 )
 
 type Config struct {
-	ctx     context.Context
 	rc      ReflectClientBuilder
 	key     string
 	host    string
 	port    string
 	method  string
 	request string
+	format  reflect.Format
+	auth    *auth.Profile
+	headers map[string]string
+
+	contract  bool
+	published reflect.DescriptorSource
 }
 
-func NewConfig(ctx context.Context, key, host, port string) *Config {
+// NewConfig creates a runner for the given target. Dialing and invoking
+// are bound by the deadline of the context passed to Build and Invoke, or
+// client.DefaultTimeout when it has none.
+func NewConfig(key, host, port string) *Config {
 	return &Config{
-		ctx:  ctx,
 		key:  key,
 		host: host,
 		port: port,
@@ -42,43 +49,103 @@ func WithRequest(req string) client.RunnerOpts {
 	}
 }
 
+// WithFormat sets the format of the request, one of json, text or binary.
+// The response is reported in the same format.
+func WithFormat(format reflect.Format) client.RunnerOpts {
+	return func(p client.Runner) {
+		p.(*Config).format = format
+	}
+}
+
+// WithContract checks successful responses against their descriptor, taken
+// from the published protos when given and from the server otherwise
+func WithContract(published reflect.DescriptorSource) client.RunnerOpts {
+	return func(p client.Runner) {
+		p.(*Config).contract = true
+		p.(*Config).published = published
+	}
+}
+
+// WithHeaders sends the given request metadata along with the call
+func WithHeaders(headers map[string]string) client.RunnerOpts {
+	return func(p client.Runner) {
+		p.(*Config).headers = headers
+	}
+}
+
 func WithMethod(method string) client.RunnerOpts {
 	return func(p client.Runner) {
 		p.(*Config).method = method
 	}
 }
 
+// WithAuth sends the resolved profile as request metadata, e.g.
+// "authorization: Bearer <token>"
+func WithAuth(profile auth.Profile) client.RunnerOpts {
+	return func(p client.Runner) {
+		p.(*Config).auth = &profile
+	}
+}
+
 func (p *Config) Clear() {
 	p.method = ""
 	p.request = ""
+	p.format = ""
+	p.auth = nil
+	p.headers = nil
+	p.contract = false
+	p.published = nil
 }
 
 func (p *Config) Build(ctx context.Context) error {
-	dial := func() *grpc.ClientConn {
+	dial := func() (*grpc.ClientConn, error) {
 		clientBuilder := GrpcClientBuilder{}
-		dialTime := 10 * time.Second
-		ctx, cancel := context.WithTimeout(p.ctx, dialTime)
+		ctx, cancel := client.WithDeadline(ctx)
 		defer cancel()
 		clientBuilder.WithContext(ctx)
 		cc, err := clientBuilder.GetConn(p.host, p.port)
 		if err != nil {
-			log.GetLogger(ctx).Fatal(errors.Wrapf(err, "Failed to dial target host %q and port %q", p.host, p.port))
+			return nil, errors.Wrapf(err, "Failed to dial target host %q and port %q", p.host, p.port)
 		}
-		return cc
+		return cc, nil
+	}
+	cc, err := dial()
+	if err != nil {
+		return err
 	}
 	p.rc = ReflectClientBuilder{}
-	p.rc.WithClientConn(dial())
-	p.rc.WithContext(p.ctx)
+	p.rc.WithClientConn(cc)
 	p.rc.WithPayload(strings.NewReader(p.request))
+	p.rc.WithFormat(p.format)
+	if p.contract {
+		p.rc.WithContract(p.published)
+	}
+	if len(p.headers) > 0 {
+		headers := make(multiString, 0, len(p.headers))
+		for k, v := range p.headers {
+			headers = append(headers, k+": "+v)
+		}
+		p.rc.WithAdditionalHeaders(headers)
+	}
+	if p.auth != nil {
+		cred, err := p.auth.Resolve(ctx)
+		if err != nil {
+			return err
+		}
+		p.rc.WithRPCHeaders(multiString{cred.Metadata()})
+	}
 	log.GetLogger(ctx).Debugf("Rest Client Config: %+v", p)
 	return nil
 }
 
-func (p *Config) Invoke() (string, error) {
-	r, err := p.rc.InvokeRPC(p.method)
+func (p *Config) Invoke(ctx context.Context) (client.Response, error) {
+	ctx, cancel := client.WithDeadline(ctx)
+	defer cancel()
+	req := &client.Request{Method: p.method, URL: strings.Join([]string{p.host, p.port}, ":"), Headers: p.headers, Body: p.request}
+	resp, err := p.rc.InvokeRPC(ctx, p.method)
 	if err != nil {
-		fmt.Println(err)
-		return "", errors.Wrapf(err, "Error invoking method %q", p.method)
+		return client.Response{Request: req}, errors.Wrapf(err, "Error invoking method %q", p.method)
 	}
-	return r, nil
+	resp.Request = req
+	return resp, nil
 }
@@ -2,20 +2,31 @@ package grpc
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
 	"github.com/jhump/protoreflect/grpcreflect"
 	"github.com/pkg/errors"
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
 	"github.com/thejasn/tester/core/reflect"
-	"github.com/thejasn/tester/pkg/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	protoV2 "google.golang.org/protobuf/proto"
 )
 
 type ReflectClientBuilder struct {
-	ctx         context.Context
 	in          io.Reader
+	format      reflect.Format
+	contract    bool
+	published   reflect.DescriptorSource
 	cc          *grpc.ClientConn
 	addlHeaders multiString
 	rpcHeaders  multiString
@@ -23,11 +34,6 @@ type ReflectClientBuilder struct {
 
 type multiString []string
 
-// WithContext set the context to be used in the dial
-func (b *ReflectClientBuilder) WithContext(ctx context.Context) {
-	b.ctx = ctx
-}
-
 func (r *ReflectClientBuilder) WithPayload(in io.Reader) {
 	r.in = in
 }
@@ -44,34 +50,128 @@ func (r *ReflectClientBuilder) WithAdditionalHeaders(headers multiString) {
 	r.addlHeaders = headers
 }
 
-func (r *ReflectClientBuilder) InvokeRPC(methodName string) (string, error) {
-	if r.ctx == nil {
-		r.ctx = context.Background()
-	}
+// WithFormat sets the format of the payload, responses are rendered in the
+// same format. Defaults to json.
+func (r *ReflectClientBuilder) WithFormat(format reflect.Format) {
+	r.format = format
+}
 
-	refClient := grpcreflect.NewClient(r.ctx, reflectpb.NewServerReflectionClient(r.cc))
-	descSource := reflect.DescriptorSourceFromServer(r.ctx, refClient)
+// WithContract checks successful responses against their descriptor, taken
+// from published, when not nil, or from the server
+func (r *ReflectClientBuilder) WithContract(published reflect.DescriptorSource) {
+	r.contract = true
+	r.published = published
+}
 
-	rf, formatter, err := reflect.RequestParserAndFormatterFor(reflect.Format(reflect.FormatJSON), descSource, true, r.in)
-	if err != nil {
-		log.GetLogger(r.ctx).Error(errors.Wrapf(err, "Failed to construct request parser and formatter for %s", reflect.FormatJSON))
-	}
-	h := reflect.NewDefaultEventHandler(os.Stdout, descSource, formatter, true)
+// formattingHandler renders responses as json, which assertions and the
+// flow context rely on, and keeps a copy in the format of the payload. The
+// time the request headers were sent starts the clock of the call, leaving
+// method resolution out.
+type formattingHandler struct {
+	*reflect.DefaultEventHandler
+	formatter reflect.Formatter
+	raw       string
+	resp      protoV2.Message
+	sent      time.Time
+}
 
-	resp, err := reflect.InvokeRPC(r.ctx, descSource, r.cc, methodName, append(r.addlHeaders, r.rpcHeaders...), h, rf.Next)
+func (h *formattingHandler) OnSendHeaders(md metadata.MD) {
+	h.sent = time.Now()
+	h.DefaultEventHandler.OnSendHeaders(md)
+}
 
-	reset := func() {
-		if refClient != nil {
-			refClient.Reset()
-			refClient = nil
-		}
+func (h *formattingHandler) OnReceiveResponse(resp protoV2.Message) (string, error) {
+	h.resp = resp
+	str, err := h.DefaultEventHandler.OnReceiveResponse(resp)
+	if err != nil || h.formatter == nil {
+		return str, err
+	}
+	if h.raw, err = h.formatter(proto.MessageV1(resp)); err != nil {
+		return "", fmt.Errorf("Failed to format response message %+v: %w", resp, err)
+	}
+	return str, nil
+}
+
+// InvokeRPC invokes the given method and returns the response along with the
+// grpc status code. For a non-OK status the response is the status itself,
+// formatted like any other message. The deadline of ctx, if any, is sent
+// along with the call.
+func (r *ReflectClientBuilder) InvokeRPC(ctx context.Context, methodName string) (client.Response, error) {
+	refClient := grpcreflect.NewClient(ctx, reflectpb.NewServerReflectionClient(r.cc))
+	defer func() {
+		refClient.Reset()
 		if r.cc != nil {
 			r.cc.Close()
 			r.cc = nil
 		}
+	}()
+	descSource := reflect.DescriptorSourceFromServer(ctx, refClient)
+
+	format := r.format
+	if format == "" {
+		format = reflect.FormatJSON
+	}
+	rf, formatter, err := reflect.RequestParserAndFormatterFor(format, descSource, true, r.in)
+	if err != nil {
+		return client.Response{}, errors.Wrapf(err, "Failed to construct request parser and formatter for %s", format)
+	}
+	h := &formattingHandler{}
+	if format == reflect.FormatJSON {
+		h.DefaultEventHandler = reflect.NewDefaultEventHandler(os.Stdout, descSource, formatter, true)
+	} else {
+		_, jsonFormatter, err := reflect.RequestParserAndFormatterFor(reflect.FormatJSON, descSource, true, nil)
+		if err != nil {
+			return client.Response{}, err
+		}
+		h.DefaultEventHandler = reflect.NewDefaultEventHandler(os.Stdout, descSource, jsonFormatter, true)
+		h.formatter = formatter
 	}
 
-	defer reset()
+	resp, err := reflect.InvokeRPC(ctx, descSource, r.cc, methodName, append(r.addlHeaders, r.rpcHeaders...), h, rf.Next)
+	if err != nil || h.Status == nil {
+		return client.Response{Status: int(codes.Unknown), Body: resp, Raw: h.raw}, err
+	}
+	out := client.Response{Status: int(h.Status.Code()), Body: resp, Raw: h.raw}
+	if !h.sent.IsZero() {
+		out.Timing.Total = time.Since(h.sent)
+	}
+	if r.contract && h.Status.Code() == codes.OK && h.resp != nil {
+		if out.Violations, err = r.checkContract(descSource, h.resp); err != nil {
+			return client.Response{}, err
+		}
+	}
+	return out, nil
+}
 
-	return resp, err
+// checkContract checks the response against its descriptor. With published
+// protos the response is decoded again with their definition of the message,
+// so that fields the server added show up as unknown.
+func (r *ReflectClientBuilder) checkContract(server reflect.DescriptorSource, resp protoV2.Message) ([]asserter.Violation, error) {
+	m, err := dynamic.AsDynamicMessage(proto.MessageV1(resp))
+	if err != nil {
+		return nil, err
+	}
+	source := server
+	if r.published != nil {
+		source = r.published
+		name := m.GetMessageDescriptor().GetFullyQualifiedName()
+		d, err := source.FindSymbol(name)
+		if err != nil {
+			return nil, fmt.Errorf("published protos do not define %s: %w", name, err)
+		}
+		md, ok := d.(*desc.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("published protos define %s as something else than a message", name)
+		}
+		b, err := m.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		published := dynamic.NewMessage(md)
+		if err := published.Unmarshal(b); err != nil {
+			return nil, fmt.Errorf("response does not decode as published %s: %w", name, err)
+		}
+		m = published
+	}
+	return asserter.CheckContract(m, reflect.AnyResolverFromDescriptorSourceWithFallback(source)), nil
 }
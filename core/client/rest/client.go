@@ -5,15 +5,16 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"time"
 
+	"github.com/thejasn/tester/core/auth"
 	"github.com/thejasn/tester/core/client"
 	"github.com/thejasn/tester/pkg/log"
 )
 
 type Config struct {
-	ctx     context.Context
 	client  *http.Client
 	request *http.Request
 	headers map[string]string
@@ -21,15 +22,16 @@ type Config struct {
 	body    string
 	baseURL string
 	url     string
+	auth    *auth.Profile
 }
 
-func NewRestConfig(ctx context.Context, baseURL string) *Config {
+// NewRestConfig creates a runner for the given base url. Requests are bound
+// by the deadline of the context they are invoked with, or
+// client.DefaultTimeout when it has none.
+func NewRestConfig(baseURL string) *Config {
 	return &Config{
-		ctx:     ctx,
 		baseURL: baseURL,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client:  &http.Client{},
 	}
 }
 
@@ -57,6 +59,12 @@ func WithUriPath(url string) client.RunnerOpts {
 	}
 }
 
+func WithAuth(profile auth.Profile) client.RunnerOpts {
+	return func(p client.Runner) {
+		p.(*Config).auth = &profile
+	}
+}
+
 func (c Config) GetIdentifier() string {
 	return "1"
 }
@@ -65,9 +73,9 @@ func (c *Config) Build(ctx context.Context) error {
 	var err error
 	var req *http.Request
 	if c.method == "POST" {
-		req, err = http.NewRequestWithContext(c.ctx, c.method, strings.Join([]string{c.baseURL, c.url}, ""), strings.NewReader(c.body))
+		req, err = http.NewRequestWithContext(ctx, c.method, strings.Join([]string{c.baseURL, c.url}, ""), strings.NewReader(c.body))
 	} else if c.method == "GET" {
-		req, err = http.NewRequestWithContext(c.ctx, c.method, strings.Join([]string{c.baseURL, c.url}, ""), nil)
+		req, err = http.NewRequestWithContext(ctx, c.method, strings.Join([]string{c.baseURL, c.url}, ""), nil)
 	} else {
 		return errors.New("unsupported method")
 	}
@@ -78,21 +86,45 @@ func (c *Config) Build(ctx context.Context) error {
 	for k, v := range c.headers {
 		c.request.Header.Add(k, v)
 	}
+	if c.auth != nil {
+		cred, err := c.auth.Resolve(ctx)
+		if err != nil {
+			return err
+		}
+		if cred.In == auth.InQuery {
+			q := c.request.URL.Query()
+			q.Set(cred.Name, cred.Value)
+			c.request.URL.RawQuery = q.Encode()
+		} else {
+			c.request.Header.Set(cred.Name, cred.Value)
+		}
+	}
 	log.GetLogger(ctx).Debugf("Rest Client Config: %+v", c)
 	return nil
 }
 
-func (c *Config) Invoke() (string, error) {
-	resp, err := c.client.Do(c.request)
+func (c *Config) Invoke(ctx context.Context) (client.Response, error) {
+	ctx, cancel := client.WithDeadline(ctx)
+	defer cancel()
+	var timing client.Timing
+	start := time.Now()
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			timing.FirstByte = time.Since(start)
+		},
+	})
+	req := &client.Request{Method: c.method, URL: c.baseURL + c.url, Headers: c.headers, Body: c.body}
+	resp, err := c.client.Do(c.request.WithContext(ctx))
 	if err != nil {
-		return "", err
+		return client.Response{Request: req}, err
 	}
 	defer resp.Body.Close()
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return client.Response{Request: req}, err
 	}
-	return string(b), nil
+	timing.Total = time.Since(start)
+	return client.Response{Status: resp.StatusCode, Body: string(b), Timing: timing, Request: req}, nil
 }
 
 func (c *Config) Clear() {
@@ -101,4 +133,5 @@ func (c *Config) Clear() {
 	c.method = ""
 	c.url = ""
 	c.request = nil
+	c.auth = nil
 }
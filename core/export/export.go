@@ -0,0 +1,133 @@
+// Package export renders the report of a recorded run for other tools:
+// JUnit XML and TAP for CI servers, a standalone HTML page for people and
+// plain json. A run is rendered as a suite, each of its steps as a test.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thejasn/tester/core/stream"
+)
+
+// Format is an export format
+type Format string
+
+const (
+	JUnit = Format("junit")
+	TAP   = Format("tap")
+	JSON  = Format("json")
+	HTML  = Format("html")
+)
+
+// Run is a recorded run as exported, Name is the name of the flow or of
+// the testcase which ran
+type Run struct {
+	ID      int           `json:"id"`
+	Name    string        `json:"name"`
+	Source  string        `json:"source,omitempty"`
+	Started time.Time     `json:"started_at"`
+	Report  stream.Report `json:"report"`
+}
+
+// ParseFormat checks a format, json being the default
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return JSON, nil
+	case JUnit, TAP, JSON, HTML:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, expected junit, tap, json or html", s)
+}
+
+// ContentType returns the media type of a format
+func (f Format) ContentType() string {
+	switch f {
+	case JUnit:
+		return "application/xml; charset=utf-8"
+	case TAP:
+		return "text/plain; charset=utf-8"
+	case HTML:
+		return "text/html; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Extension returns the file extension of a format
+func (f Format) Extension() string {
+	switch f {
+	case JUnit:
+		return ".xml"
+	case TAP:
+		return ".tap"
+	case HTML:
+		return ".html"
+	}
+	return ".json"
+}
+
+// Write renders a run in the given format
+func Write(w io.Writer, f Format, r Run) error {
+	switch f {
+	case JUnit:
+		return writeJUnit(w, r)
+	case TAP:
+		return writeTAP(w, r)
+	case HTML:
+		return writeHTML(w, r)
+	case JSON:
+		return json.NewEncoder(w).Encode(r)
+	}
+	return fmt.Errorf("unsupported format %q", f)
+}
+
+// test is a step of a run as listed by the exports, steps of sub-flows are
+// listed after the step calling them
+type test struct {
+	Name string
+	stream.StepResult
+}
+
+// tests lists the steps of a report. Steps of the setup and teardown are
+// prefixed with their phase, iterations of a loop are suffixed with their
+// index and steps of a sub-flow are prefixed with the calling step.
+func tests(steps []stream.StepResult, prefix string) []test {
+	var out []test
+	for _, s := range steps {
+		name := s.Name
+		if s.Phase != "" && s.Phase != stream.Main {
+			name = string(s.Phase) + ": " + name
+		}
+		if s.Index != nil {
+			name += "[" + strconv.Itoa(*s.Index) + "]"
+		}
+		name = prefix + name
+		out = append(out, test{Name: name, StepResult: s})
+		out = append(out, tests(s.Steps, name+" / ")...)
+	}
+	return out
+}
+
+// failed tells whether a step counts as a failure, errors and timeouts
+// included
+func failed(s stream.StepResult) bool {
+	return s.Status == stream.Failed || s.Status == stream.Errored || s.Status == stream.TimedOut
+}
+
+// details describes why a step did not pass, one line per violation
+func details(s stream.StepResult) string {
+	lines := []string{s.Message}
+	for _, v := range s.Violations {
+		lines = append(lines, fmt.Sprintf("%s: %s (%s)", v.Pointer, v.Message, v.Keyword))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
+	"github.com/thejasn/tester/core/stream"
+)
+
+func run() Run {
+	one := 1
+	return Run{
+		ID:      7,
+		Name:    "orders",
+		Source:  "schedule",
+		Started: time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC),
+		Report: stream.Report{
+			Passed:   false,
+			Duration: 1500 * time.Millisecond,
+			Steps: []stream.StepResult{
+				{ID: 1, Name: "tenant", Phase: stream.Setup, Status: stream.Passed, Duration: 100 * time.Millisecond},
+				{
+					ID: 2, Name: "create", Status: stream.Failed, Message: "expected 201 but found 500", Code: 500,
+					Request:  &client.Request{Method: "POST", URL: "http://orders/v1", Headers: map[string]string{"x-tenant": "3"}, Body: `{"sku":"a"}`},
+					Response: `{"error":"<boom>"}`,
+					Violations: []asserter.Violation{
+						{Pointer: "/id", Keyword: "required", Message: "missing id"},
+					},
+				},
+				{ID: 3, Name: "list", Index: &one, Status: stream.Errored, Message: "connection refused"},
+				{ID: 4, Name: "pay", Status: stream.Skipped, Message: "flow halted # after create"},
+				{ID: 5, Name: "login", Status: stream.Passed, Steps: []stream.StepResult{
+					{ID: 1, Name: "token", Status: stream.Passed},
+				}},
+			},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := []struct {
+		In   string
+		Want Format
+		Ext  string
+		Err  bool
+	}{
+		{In: "", Want: JSON, Ext: ".json"},
+		{In: "JUnit", Want: JUnit, Ext: ".xml"},
+		{In: "tap", Want: TAP, Ext: ".tap"},
+		{In: "html", Want: HTML, Ext: ".html"},
+		{In: "csv", Err: true},
+	}
+	for _, c := range cases {
+		got, err := ParseFormat(c.In)
+		if (err != nil) != c.Err || got != c.Want {
+			t.Errorf("ParseFormat(%q) = %q, %v", c.In, got, err)
+		}
+		if !c.Err && got.Extension() != c.Ext {
+			t.Errorf("%q.Extension() = %q, expected %q", got, got.Extension(), c.Ext)
+		}
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, JUnit, run()); err != nil {
+		t.Fatal(err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid xml: %v\n%s", err, b.String())
+	}
+	if doc.Tests != 6 || doc.Failures != 1 || doc.Errors != 1 || doc.Skipped != 1 {
+		t.Errorf("unexpected counts %+v", doc)
+	}
+	s := doc.Suites[0]
+	names := []string{"setup: tenant", "create", "list[1]", "pay", "login", "login / token"}
+	for i, name := range names {
+		if s.Cases[i].Name != name {
+			t.Errorf("case %d is %q, expected %q", i, s.Cases[i].Name, name)
+		}
+	}
+	f := s.Cases[1].Failure
+	if f == nil || f.Message != "expected 201 but found 500" || !strings.Contains(f.Text, "/id: missing id (required)") {
+		t.Errorf("unexpected failure %+v", f)
+	}
+	if s.Cases[2].Error == nil || s.Cases[3].Skipped == nil || s.Time != "1.500" {
+		t.Errorf("unexpected cases %+v", s.Cases)
+	}
+}
+
+func TestJUnitBudget(t *testing.T) {
+	r := Run{Name: "orders", Report: stream.Report{Message: "run took 2s, over its budget of 1s"}}
+	var b bytes.Buffer
+	if err := Write(&b, JUnit, r); err != nil {
+		t.Fatal(err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Tests != 1 || doc.Failures != 1 || doc.Suites[0].Cases[0].Name != "run" {
+		t.Errorf("unexpected suite %+v", doc)
+	}
+}
+
+func TestTAP(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, TAP, run()); err != nil {
+		t.Fatal(err)
+	}
+	want := `TAP version 13
+1..6
+# orders (run 7)
+ok 1 - setup: tenant
+not ok 2 - create
+  ---
+  status: FAILED
+  message: "expected 201 but found 500\n/id: missing id (required)"
+  duration_s: 0.000
+  ...
+not ok 3 - list[1]
+  ---
+  status: ERROR
+  message: "connection refused"
+  duration_s: 0.000
+  ...
+ok 4 - pay # SKIP flow halted \# after create
+ok 5 - login
+ok 6 - login / token
+`
+	if got := b.String(); got != want {
+		t.Errorf("unexpected tap\n%s\nexpected\n%s", got, want)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, HTML, run()); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+	for _, want := range []string{
+		"<title>orders - run 7</title>",
+		"POST http://orders/v1",
+		"x-tenant: 3",
+		"<h4>Response 500</h4>",
+		"&#34;error&#34;: &#34;&lt;boom&gt;&#34;",
+		"<td>/id</td><td>required</td><td>missing id</td>",
+		"setup: tenant",
+		"list[1]",
+		"token",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %q\n%s", want, page)
+		}
+	}
+}
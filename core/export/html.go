@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thejasn/tester/core/stream"
+)
+
+// page is a standalone html document, styles are inlined so that the
+// report can be archived or mailed as a single file
+var page = template.Must(template.New("run").Funcs(template.FuncMap{
+	"pretty": pretty,
+	"lower":  func(s stream.Status) string { return strings.ToLower(string(s)) },
+	"since":  func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+	"date":   func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} - run {{.ID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+td, th { padding: .2em .8em .2em 0; text-align: left; vertical-align: top; }
+pre { background: #f4f4f4; padding: .6em; overflow-x: auto; white-space: pre-wrap; }
+details { border-left: 4px solid #ccc; margin: .4em 0; padding: .2em .8em; }
+summary { cursor: pointer; }
+.passed { border-color: #2e7d32; } .passed .status { color: #2e7d32; }
+.failed, .error, .timeout { border-color: #c62828; } .failed .status, .error .status, .timeout .status { color: #c62828; }
+.skipped { border-color: #9e9e9e; } .skipped .status { color: #757575; }
+.status { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<table>
+<tr><th>Run</th><td>{{.ID}}</td></tr>
+<tr><th>Outcome</th><td class="status">{{if .Report.Passed}}PASSED{{else}}FAILED{{end}}</td></tr>
+{{- if not .Started.IsZero}}
+<tr><th>Started</th><td>{{date .Started}}</td></tr>
+{{- end}}
+<tr><th>Duration</th><td>{{since .Report.Duration}}</td></tr>
+{{- if .Source}}
+<tr><th>Source</th><td>{{.Source}}</td></tr>
+{{- end}}
+{{- if .Report.Message}}
+<tr><th>Message</th><td>{{.Report.Message}}</td></tr>
+{{- end}}
+</table>
+<h2>Steps</h2>
+{{template "steps" .Report.Steps}}
+</body>
+</html>
+{{define "steps"}}{{range .}}
+<details class="{{lower .Status}}"{{if or (eq .Status "FAILED") (eq .Status "ERROR") (eq .Status "TIMEOUT")}} open{{end}}>
+<summary><span class="status">{{.Status}}</span> {{if .Phase}}{{.Phase}}: {{end}}{{.Name}}{{if .Index}}[{{.Index}}]{{end}} <small>{{since .Duration}}</small></summary>
+{{- if .Message}}
+<p>{{.Message}}</p>
+{{- end}}
+{{- if .Violations}}
+<table>
+<tr><th>Pointer</th><th>Keyword</th><th>Message</th></tr>
+{{- range .Violations}}
+<tr><td>{{.Pointer}}</td><td>{{.Keyword}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Request}}
+<h4>Request</h4>
+<pre>{{.Method}} {{.URL}}
+{{- range $k, $v := .Headers}}
+{{$k}}: {{$v}}
+{{- end}}
+{{- if .Body}}
+
+{{pretty .Body}}
+{{- end}}</pre>
+{{- end}}
+{{- if or .Code .Response}}
+<h4>Response{{if .Code}} {{.Code}}{{end}}</h4>
+{{- if .Response}}
+<pre>{{pretty .Response}}</pre>
+{{- end}}
+{{- end}}
+{{- if .Attempts}}
+<h4>Attempts</h4>
+<table>
+<tr><th>Status</th><th>Code</th><th>Duration</th><th>Message</th></tr>
+{{- range .Attempts}}
+<tr><td>{{.Status}}</td><td>{{if .Code}}{{.Code}}{{end}}</td><td>{{since .Duration}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Steps}}
+{{template "steps" .Steps}}
+{{- end}}
+</details>
+{{- end}}{{end}}`))
+
+// writeHTML renders the run as a standalone page listing every step along
+// with its request and response, failed steps being unfolded
+func writeHTML(w io.Writer, r Run) error {
+	return page.Execute(w, r)
+}
+
+// pretty indents a json body, other bodies are left as they are
+func pretty(body string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(body), "", "  "); err != nil {
+		return body
+	}
+	return out.String()
+}
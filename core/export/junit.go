@@ -0,0 +1,121 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/thejasn/tester/core/stream"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	ID         int             `xml:"id,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit renders the run as a single suite, failed assertions being
+// failures and errors or timeouts errors. A run failing on its own, e.g.
+// over its budget, gets an extra test carrying the failure.
+func writeJUnit(w io.Writer, r Run) error {
+	suite := junitSuite{
+		Name: r.Name,
+		ID:   r.ID,
+		Time: seconds(r.Report.Duration),
+		Properties: []junitProperty{
+			{Name: "run_id", Value: strconv.Itoa(r.ID)},
+		},
+	}
+	if !r.Started.IsZero() {
+		suite.Timestamp = r.Started.UTC().Format(time.RFC3339)
+	}
+	if r.Source != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "source", Value: r.Source})
+	}
+	for _, t := range tests(r.Report.Steps, "") {
+		c := junitCase{Name: t.Name, ClassName: r.Name, Time: seconds(t.Duration), SystemOut: t.Response}
+		problem := &junitProblem{Message: t.Message, Type: string(t.Status), Text: details(t.StepResult)}
+		switch t.Status {
+		case stream.Failed:
+			c.Failure = problem
+			suite.Failures++
+		case stream.Errored, stream.TimedOut:
+			c.Error = problem
+			suite.Errors++
+		case stream.Skipped:
+			c.Skipped = &junitProblem{Message: t.Message}
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	if r.Report.Message != "" {
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      "run",
+			ClassName: r.Name,
+			Time:      seconds(r.Report.Duration),
+			Failure:   &junitProblem{Message: r.Report.Message, Type: string(stream.Failed), Text: r.Report.Message},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.Cases)
+
+	doc := junitSuites{
+		Name:     r.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/thejasn/tester/core/stream"
+)
+
+// writeTAP renders the run as a TAP version 13 stream, failures carrying a
+// yaml block with their status and message and skipped steps a SKIP
+// directive
+func writeTAP(w io.Writer, r Run) error {
+	b := bufio.NewWriter(w)
+	all := tests(r.Report.Steps, "")
+	n := len(all)
+	if r.Report.Message != "" {
+		n++
+	}
+	fmt.Fprintf(b, "TAP version 13\n1..%d\n", n)
+	fmt.Fprintf(b, "# %s (run %d)\n", oneLine(r.Name), r.ID)
+	for i, t := range all {
+		switch {
+		case t.Status == stream.Skipped:
+			fmt.Fprintf(b, "ok %d - %s # SKIP %s\n", i+1, oneLine(t.Name), oneLine(t.Message))
+		case failed(t.StepResult):
+			fmt.Fprintf(b, "not ok %d - %s\n", i+1, oneLine(t.Name))
+			diagnostic(b, t.Status, details(t.StepResult), t.Duration.Seconds())
+		default:
+			fmt.Fprintf(b, "ok %d - %s\n", i+1, oneLine(t.Name))
+		}
+	}
+	if r.Report.Message != "" {
+		fmt.Fprintf(b, "not ok %d - run\n", n)
+		diagnostic(b, stream.Failed, r.Report.Message, r.Report.Duration.Seconds())
+	}
+	return b.Flush()
+}
+
+// diagnostic writes the yaml block following a failed test, strings are
+// quoted as json which yaml reads as well
+func diagnostic(w io.Writer, status stream.Status, message string, duration float64) {
+	m, _ := json.Marshal(message)
+	fmt.Fprintf(w, "  ---\n  status: %s\n  message: %s\n  duration_s: %.3f\n  ...\n", status, m, duration)
+}
+
+// oneLine keeps a description on the line of its test, # starting a
+// directive in TAP
+func oneLine(s string) string {
+	return strings.NewReplacer("\n", " ", "\r", " ", "#", `\#`).Replace(s)
+}
@@ -0,0 +1,264 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+type literal struct {
+	v interface{}
+}
+
+func (l literal) eval(Env) (interface{}, error) {
+	return l.v, nil
+}
+
+type path string
+
+func (p path) eval(env Env) (interface{}, error) {
+	return normalize(env.Value(string(p))), nil
+}
+
+type array []node
+
+func (a array) eval(env Env) (interface{}, error) {
+	out := make([]interface{}, len(a))
+	for i, n := range a {
+		v, err := n.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func (u unary) eval(env Env) (interface{}, error) {
+	v, err := u.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if u.op == "!" {
+		return !Truthy(v), nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", describe(v))
+	}
+	return -f, nil
+}
+
+type call struct {
+	name string
+	fn   func(args []interface{}) (interface{}, error)
+	args []node
+}
+
+func (c call) eval(env Env) (interface{}, error) {
+	args := make([]interface{}, len(c.args))
+	for i, n := range c.args {
+		v, err := n.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := c.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.name, err)
+	}
+	return v, nil
+}
+
+type binary struct {
+	op       string
+	lhs, rhs node
+}
+
+func (b binary) eval(env Env) (interface{}, error) {
+	lhs, err := b.lhs.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch b.op {
+	case "&&":
+		if !Truthy(lhs) {
+			return false, nil
+		}
+	case "||":
+		if Truthy(lhs) {
+			return true, nil
+		}
+	}
+	rhs, err := b.rhs.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch b.op {
+	case "&&", "||":
+		return Truthy(rhs), nil
+	case "==":
+		return equal(lhs, rhs), nil
+	case "!=":
+		return !equal(lhs, rhs), nil
+	case "<", "<=", ">", ">=":
+		return compare(b.op, lhs, rhs)
+	}
+	return arithmetic(b.op, lhs, rhs)
+}
+
+// equal compares values by their content, dates by the instant they denote
+func equal(lhs, rhs interface{}) bool {
+	lt, lok := lhs.(time.Time)
+	rt, rok := rhs.(time.Time)
+	if lok && rok {
+		return lt.Equal(rt)
+	}
+	return reflect.DeepEqual(lhs, rhs)
+}
+
+// compare orders two numbers, strings or dates
+func compare(op string, lhs, rhs interface{}) (bool, error) {
+	var c int
+	switch l := lhs.(type) {
+	case float64:
+		r, ok := rhs.(float64)
+		if !ok {
+			return false, mismatch(op, lhs, rhs)
+		}
+		c = order(l < r, l > r)
+	case string:
+		r, ok := rhs.(string)
+		if !ok {
+			return false, mismatch(op, lhs, rhs)
+		}
+		c = order(l < r, l > r)
+	case time.Time:
+		r, ok := rhs.(time.Time)
+		if !ok {
+			return false, mismatch(op, lhs, rhs)
+		}
+		c = order(l.Before(r), l.After(r))
+	default:
+		return false, mismatch(op, lhs, rhs)
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+func order(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// arithmetic applies an arithmetic operator. Strings are concatenated with
+// +, milliseconds can be added to or subtracted from dates and subtracting
+// two dates yields the milliseconds between them.
+func arithmetic(op string, lhs, rhs interface{}) (interface{}, error) {
+	switch l := lhs.(type) {
+	case float64:
+		r, ok := rhs.(float64)
+		if !ok {
+			break
+		}
+		switch op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/":
+			if r == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return l / r, nil
+		case "%":
+			if r == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return math.Mod(l, r), nil
+		}
+	case string:
+		if r, ok := rhs.(string); ok && op == "+" {
+			return l + r, nil
+		}
+	case time.Time:
+		switch r := rhs.(type) {
+		case float64:
+			d := time.Duration(r * float64(time.Millisecond))
+			switch op {
+			case "+":
+				return l.Add(d), nil
+			case "-":
+				return l.Add(-d), nil
+			}
+		case time.Time:
+			if op == "-" {
+				return float64(l.Sub(r)) / float64(time.Millisecond), nil
+			}
+		}
+	}
+	return nil, mismatch(op, lhs, rhs)
+}
+
+func mismatch(op string, lhs, rhs interface{}) error {
+	return fmt.Errorf("cannot apply %s to %s and %s", op, describe(lhs), describe(rhs))
+}
+
+// describe renders a value along with its type for error messages
+func describe(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", t)
+	case float64:
+		return fmt.Sprintf("number %v", t)
+	case bool:
+		return fmt.Sprintf("bool %v", t)
+	case time.Time:
+		return "date " + t.Format(time.RFC3339Nano)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// normalize converts the numbers an environment may return to float64
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case int:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	}
+	return v
+}
@@ -0,0 +1,278 @@
+// Package expr implements the expression language used by assertions, step
+// conditions and variable extraction. Values are looked up with gjson paths
+// and combined with typed arithmetic, comparisons, boolean logic and a set
+// of functions, for example
+//
+//	body.items.#(status=="open")#|len >= 2 && status == 200
+//
+// Numbers are float64 like decoded json, duration literals such as 300ms
+// are numbers of milliseconds and dates are produced by date() and now().
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Env resolves the paths of an expression, nil is returned when nothing
+// matches
+type Env interface {
+	Value(path string) interface{}
+}
+
+// Expr is a compiled expression, it can be evaluated any number of times
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses an expression, calls are checked against the known
+// functions so that typos are reported before anything is evaluated
+func Compile(src string) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parse(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval evaluates the expression against env
+func (e *Expr) Eval(env Env) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// Bool evaluates the expression as a condition, see Truthy
+func (e *Expr) Bool(env Env) (bool, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(v), nil
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Evaluate compiles and evaluates a condition in one go
+func Evaluate(src string, env Env) (bool, error) {
+	e, err := Compile(src)
+	if err != nil {
+		return false, err
+	}
+	return e.Bool(env)
+}
+
+// Truthy reports whether a value holds, anything other than null, false, 0,
+// "" and empty arrays or objects does
+func Truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		return t != ""
+	case []interface{}:
+		return len(t) > 0
+	case map[string]interface{}:
+		return len(t) > 0
+	}
+	return true
+}
+
+// binding power of the binary operators, higher binds tighter
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tokOp || t.text != op {
+		return unexpected(t, op)
+	}
+	return nil
+}
+
+// parse reads a binary expression whose operators bind tighter than min
+func (p *parser) parse(min int) (node, error) {
+	lhs, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokOp || !ok || prec <= min {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.parse(prec)
+		if err != nil {
+			return nil, err
+		}
+		lhs = binary{op: t.text, lhs: lhs, rhs: rhs}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if t := p.peek(); t.kind == tokOp && (t.text == "!" || t.text == "-") {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op: t.text, operand: operand}, nil
+	}
+	return p.postfix()
+}
+
+// postfix reads an operand followed by any number of pipes, x | f(y) being
+// the same as f(x, y)
+func (p *parser) postfix() (node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if t := p.peek(); t.kind != tokOp || t.text != "|" {
+			return n, nil
+		}
+		p.next()
+		t := p.next()
+		if t.kind != tokPath {
+			return nil, unexpected(t, "function")
+		}
+		args := []node{n}
+		if q := p.peek(); q.kind == tokOp && q.text == "(" {
+			p.next()
+			more, err := p.list(")")
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, more...)
+		}
+		if n, err = newCall(t, args); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return literal{t.num}, nil
+	case tokString:
+		return literal{t.text}, nil
+	case tokPath:
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		case "null":
+			return literal{nil}, nil
+		}
+		if q := p.peek(); q.kind == tokOp && q.text == "(" {
+			p.next()
+			args, err := p.list(")")
+			if err != nil {
+				return nil, err
+			}
+			return newCall(t, args)
+		}
+		return path(t.text), nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			n, err := p.parse(0)
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			items, err := p.list("]")
+			if err != nil {
+				return nil, err
+			}
+			return array(items), nil
+		}
+	}
+	return nil, unexpected(t, "a value")
+}
+
+// list reads comma separated expressions up to the closing token
+func (p *parser) list(end string) ([]node, error) {
+	var out []node
+	if t := p.peek(); t.kind == tokOp && t.text == end {
+		p.next()
+		return out, nil
+	}
+	for {
+		n, err := p.parse(0)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+		t := p.next()
+		if t.kind == tokOp && t.text == end {
+			return out, nil
+		}
+		if t.kind != tokOp || t.text != "," {
+			return nil, unexpected(t, end)
+		}
+	}
+}
+
+func newCall(t token, args []node) (node, error) {
+	fn, ok := functions[t.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at %d", t.text, t.pos)
+	}
+	if len(args) < fn.min || fn.max >= 0 && len(args) > fn.max {
+		return nil, fmt.Errorf("wrong number of arguments for %s at %d", t.text, t.pos)
+	}
+	return call{name: t.text, fn: fn.call, args: args}, nil
+}
+
+func unexpected(t token, want string) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("expected %s but the expression ended", want)
+	}
+	return fmt.Errorf("expected %s but found %q at %d", want, t.text, t.pos)
+}
@@ -0,0 +1,123 @@
+package expr
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tidwall/gjson"
+)
+
+type doc map[string]interface{}
+
+func (d doc) Value(path string) interface{} {
+	b, _ := json.Marshal(d)
+	return gjson.GetBytes(b, path).Value()
+}
+
+func testDoc() doc {
+	return doc{
+		"status": 200,
+		"body": map[string]interface{}{
+			"name": "Foo Bar",
+			"items": []interface{}{
+				map[string]interface{}{"id": 1, "status": "open", "price": 2.5},
+				map[string]interface{}{"id": 2, "status": "closed", "price": 4},
+				map[string]interface{}{"id": 3, "status": "open", "price": 1},
+			},
+			"created": "2021-03-01T10:00:00Z",
+		},
+		"steps": map[string]interface{}{
+			"create-user": map[string]interface{}{"status": 201},
+		},
+	}
+}
+
+func TestEval(t *testing.T) {
+	created := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		Input  string
+		Output interface{}
+	}{
+		{`body.items.#(status=="open")#|len >= 2 && status == 200`, true},
+		{`body.items.#(status=="open")#.id`, []interface{}{1.0, 3.0}},
+		{`body.items.#`, 3.0},
+		{`steps.create-user.status`, 201.0},
+		{`status - 100 * 2`, 0.0},
+		{`(status - 100) * 2`, 200.0},
+		{`-status % 7`, -4.0},
+		{`body.items.0.price + body.items.1.price`, 6.5},
+		{`"id-" + string(body.items.2.id)`, "id-3"},
+		{`body.name | lower | starts_with("foo")`, true},
+		{`contains(body.name, "Bar") && !contains(body.items.#.id, 4)`, true},
+		{`upper(trim("  a "))`, "A"},
+		{`matches(body.name, "^Foo\\s")`, true},
+		{`split("a,b", ",")`, []interface{}{"a", "b"}},
+		{`join(body.items.#.status, "/")`, "open/closed/open"},
+		{`replace(body.name, "Bar", "Baz")`, "Foo Baz"},
+		{`number("4") / 8`, 0.5},
+		{`round(2.5) + floor(1.7) + ceil(0.2) + abs(-1)`, 6.0},
+		{`date(body.created)`, created},
+		{`date(body.created) + 1h`, created.Add(time.Hour)},
+		{`date(body.created) - date("2021-03-01") == 10h`, true},
+		{`date("01/03/2021", "02/01/2006") < date(body.created)`, true},
+		{`unix(date(body.created))`, float64(created.Unix())},
+		{`date(unix(date(body.created))) == date(body.created)`, true},
+		{`now() > date(body.created)`, true},
+		{`status == 404 || body.missing`, false},
+		{`body.missing == null && !body.missing`, true},
+		{`len(body.missing) == 0 && len("héllo") == 5`, true},
+		{`[1, "a"] == [1, 'a']`, true},
+		{`1.5s == 1500`, true},
+		{`'it\'s'`, "it's"},
+		{`status == 200 && body.items.#(price>2)#|len == 2`, true},
+	}
+
+	env := testDoc()
+	for _, tc := range cases {
+		e, err := Compile(tc.Input)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.Input, err)
+		}
+		actual, err := e.Eval(env)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.Input, err)
+		}
+		if !cmp.Equal(actual, tc.Output) {
+			t.Fatalf("%s: expected %v but found %v", tc.Input, tc.Output, actual)
+		}
+	}
+}
+
+func TestEvalInvalid(t *testing.T) {
+	cases := []struct {
+		Input string
+		Error string
+	}{
+		{``, "empty expression"},
+		{`status ==`, "expected a value but the expression ended"},
+		{`(status`, "expected ) but the expression ended"},
+		{`status 200`, `unexpected "200" at 7`},
+		{`nope(status)`, `unknown function "nope" at 0`},
+		{`len(status, 1)`, "wrong number of arguments for len at 0"},
+		{`"open`, "unterminated string at 0"},
+		{`status > "a"`, `cannot apply > to number 200 and string "a"`},
+		{`body.name - 1`, `cannot apply - to string "Foo Bar" and number 1`},
+		{`status / 0`, "division by zero"},
+		{`len(status)`, "len: no length for number 200"},
+		{`date("yesterday")`, `date: cannot parse "yesterday" as a date`},
+		{`status | 1`, `expected function but found "1" at 9`},
+	}
+
+	env := testDoc()
+	for _, tc := range cases {
+		_, err := Compile(tc.Input)
+		if err == nil {
+			_, err = Evaluate(tc.Input, env)
+		}
+		if err == nil || err.Error() != tc.Error {
+			t.Fatalf("%s: expected error %q but found %v", tc.Input, tc.Error, err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type function struct {
+	min, max int
+	call     func(args []interface{}) (interface{}, error)
+}
+
+// dateLayouts are tried in order by date when no layout is given
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// functions available to expressions, a negative max takes any number of
+// arguments:
+//
+//	len(x)                 length of a string, array or object
+//	lower(s), upper(s)     changes the case of a string
+//	trim(s)                strips surrounding white space
+//	contains(x, y)         substring of a string or element of an array
+//	starts_with(s, p)      prefix test
+//	ends_with(s, p)        suffix test
+//	matches(s, re)         regular expression test
+//	replace(s, old, new)   replaces every occurrence of old
+//	split(s, sep)          splits a string into an array
+//	join(a, sep)           joins an array of strings
+//	number(x)              parses a string as a number
+//	string(x)              formats a value as a string
+//	abs(n), floor(n), ceil(n), round(n)
+//	date(x[, layout])      parses a date, numbers being unix seconds
+//	now()                  the current date
+//	unix(d)                seconds since the epoch of a date
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"len":         {1, 1, length},
+		"lower":       {1, 1, strFunc(strings.ToLower)},
+		"upper":       {1, 1, strFunc(strings.ToUpper)},
+		"trim":        {1, 1, strFunc(strings.TrimSpace)},
+		"contains":    {2, 2, contains},
+		"starts_with": {2, 2, strPredicate(strings.HasPrefix)},
+		"ends_with":   {2, 2, strPredicate(strings.HasSuffix)},
+		"matches":     {2, 2, matches},
+		"replace":     {3, 3, replace},
+		"split":       {2, 2, split},
+		"join":        {2, 2, join},
+		"number":      {1, 1, number},
+		"string":      {1, 1, toString},
+		"abs":         {1, 1, numFunc(math.Abs)},
+		"floor":       {1, 1, numFunc(math.Floor)},
+		"ceil":        {1, 1, numFunc(math.Ceil)},
+		"round":       {1, 1, numFunc(math.Round)},
+		"date":        {1, 2, date},
+		"now":         {0, 0, func([]interface{}) (interface{}, error) { return time.Now(), nil }},
+		"unix":        {1, 1, unix},
+	}
+}
+
+func length(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case string:
+		return float64(len([]rune(t))), nil
+	case []interface{}:
+		return float64(len(t)), nil
+	case map[string]interface{}:
+		return float64(len(t)), nil
+	case nil:
+		return float64(0), nil
+	}
+	return nil, fmt.Errorf("no length for %s", describe(args[0]))
+}
+
+func contains(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case string:
+		s, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string but found %s", describe(args[1]))
+		}
+		return strings.Contains(t, s), nil
+	case []interface{}:
+		for _, e := range t {
+			if equal(normalize(e), args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case nil:
+		return false, nil
+	}
+	return nil, fmt.Errorf("expected a string or an array but found %s", describe(args[0]))
+}
+
+func matches(args []interface{}) (interface{}, error) {
+	s, err := stringArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(s[1])
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(s[0]), nil
+}
+
+func replace(args []interface{}) (interface{}, error) {
+	s, err := stringArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ReplaceAll(s[0], s[1], s[2]), nil
+}
+
+func split(args []interface{}) (interface{}, error) {
+	s, err := stringArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(s[0], s[1])
+	out := make([]interface{}, len(parts))
+	for i, p := range parts {
+		out[i] = p
+	}
+	return out, nil
+}
+
+func join(args []interface{}) (interface{}, error) {
+	items, ok := args[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array but found %s", describe(args[0]))
+	}
+	sep, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string but found %s", describe(args[1]))
+	}
+	parts := make([]string, len(items))
+	for i, e := range items {
+		v, _ := toString([]interface{}{e})
+		parts[i] = v.(string)
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func number(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case float64:
+		return t, nil
+	case bool:
+		if t {
+			return float64(1), nil
+		}
+		return float64(0), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", t)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to a number", describe(args[0]))
+}
+
+func toString(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case time.Time:
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return fmt.Sprint(args[0]), nil
+}
+
+func date(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case time.Time:
+		return t, nil
+	case float64:
+		sec, frac := math.Modf(t)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	case string:
+		if len(args) == 2 {
+			layout, ok := args[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a layout but found %s", describe(args[1]))
+			}
+			return time.Parse(layout, t)
+		}
+		for _, layout := range dateLayouts {
+			if d, err := time.Parse(layout, t); err == nil {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot parse %q as a date", t)
+	}
+	return nil, fmt.Errorf("cannot convert %s to a date", describe(args[0]))
+}
+
+func unix(args []interface{}) (interface{}, error) {
+	d, err := date(args[:1])
+	if err != nil {
+		return nil, err
+	}
+	return float64(d.(time.Time).UnixNano()) / 1e9, nil
+}
+
+func strFunc(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, err := stringArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return f(s[0]), nil
+	}
+}
+
+func strPredicate(f func(string, string) bool) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, err := stringArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return f(s[0], s[1]), nil
+	}
+}
+
+func numFunc(f func(float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		n, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a number but found %s", describe(args[0]))
+		}
+		return f(n), nil
+	}
+}
+
+// stringArgs asserts that every argument is a string
+func stringArgs(args []interface{}) ([]string, error) {
+	out := make([]string, len(args))
+	for i, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string but found %s", describe(a))
+		}
+		out[i] = s
+	}
+	return out, nil
+}
@@ -0,0 +1,188 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type kind int
+
+const (
+	tokEOF kind = iota
+	tokNumber
+	tokString
+	tokPath
+	tokOp
+)
+
+type token struct {
+	kind kind
+	text string
+	num  float64
+	pos  int
+}
+
+// operators are matched longest first
+var operators = []string{"||", "&&", "==", "!=", ">=", "<=", ">", "<", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ",", "|"}
+
+// lex splits an expression into tokens. Paths are scanned as a whole so that
+// gjson queries such as items.#(status=="open")#.id stay intact.
+func lex(src string) ([]token, error) {
+	var out []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			t, n, err := lexNumber(src, i)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, t)
+			i = n
+		case c == '"' || c == '\'':
+			s, n, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, token{kind: tokString, text: s, pos: i})
+			i = n
+		case pathStart(c):
+			n, err := lexPath(src, i)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, token{kind: tokPath, text: src[i:n], pos: i})
+			i = n
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			out = append(out, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(out, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// lexNumber scans a number or a duration literal such as 300ms, durations
+// are numbers of milliseconds
+func lexNumber(src string, i int) (token, int, error) {
+	n := i
+	for n < len(src) && (src[n] >= '0' && src[n] <= '9' || src[n] == '.') {
+		n++
+	}
+	if n < len(src) && isLetter(src[n]) {
+		for n < len(src) && (isLetter(src[n]) || src[n] >= '0' && src[n] <= '9' || src[n] == '.') {
+			n++
+		}
+		d, err := time.ParseDuration(src[i:n])
+		if err != nil {
+			return token{}, 0, fmt.Errorf("invalid number %q", src[i:n])
+		}
+		return token{kind: tokNumber, text: src[i:n], num: float64(d) / float64(time.Millisecond), pos: i}, n, nil
+	}
+	f, err := strconv.ParseFloat(src[i:n], 64)
+	if err != nil {
+		return token{}, 0, fmt.Errorf("invalid number %q", src[i:n])
+	}
+	return token{kind: tokNumber, text: src[i:n], num: f, pos: i}, n, nil
+}
+
+// lexString scans a quoted string, a backslash escapes the next character
+func lexString(src string, i int) (string, int, error) {
+	quote := src[i]
+	var b strings.Builder
+	for n := i + 1; n < len(src); n++ {
+		switch src[n] {
+		case '\\':
+			if n+1 < len(src) {
+				n++
+				b.WriteByte(src[n])
+			}
+		case quote:
+			return b.String(), n + 1, nil
+		default:
+			b.WriteByte(src[n])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at %d", i)
+}
+
+// lexPath scans a gjson path. Queries within #(...) are kept whole, a dash
+// is part of the path when it joins two names, as in steps.create-user, and
+// * and ? are wildcards when next to a dot.
+func lexPath(src string, i int) (int, error) {
+	n := i
+	for n < len(src) {
+		c := src[n]
+		switch {
+		case pathChar(c):
+			n++
+		case c == '\\' && n+1 < len(src):
+			n += 2
+		case c == '(' && n > i && src[n-1] == '#':
+			end, err := closing(src, n)
+			if err != nil {
+				return 0, err
+			}
+			n = end + 1
+		case c == '-' && n > i && pathChar(src[n-1]) && n+1 < len(src) && isLetter(src[n+1]):
+			n++
+		case (c == '*' || c == '?') && (src[n-1] == '.' || n+1 < len(src) && src[n+1] == '.'):
+			n++
+		default:
+			return n, nil
+		}
+	}
+	return n, nil
+}
+
+// closing returns the index of the parenthesis closing the one at i
+func closing(src string, i int) (int, error) {
+	depth := 0
+	var quote byte
+	for n := i; n < len(src); n++ {
+		c := src[n]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				n++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return n, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced query at %d", i)
+}
+
+func pathStart(c byte) bool {
+	return isLetter(c) || c == '_' || c == '@' || c == '#' || c == '$'
+}
+
+func pathChar(c byte) bool {
+	return pathStart(c) || c >= '0' && c <= '9' || c == '.'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -119,6 +119,48 @@ func (f *textRequestParser) NumRequests() int {
 	return f.requestCount
 }
 
+// maxBinaryMessageSize bounds a single base64 encoded message read by the
+// binary request parser
+const maxBinaryMessageSize = 16 * 1024 * 1024
+
+type binaryRequestParser struct {
+	s            *bufio.Scanner
+	requestCount int
+}
+
+// NewBinaryRequestParser returns a RequestParser that reads messages in the
+// protobuf binary wire format, encoded as standard base64, from the given
+// reader.
+//
+// Input data that contains more than one message should separate the
+// encoded messages with whitespace. If the given reader has no data, the
+// returned parser will return io.EOF on the very first call.
+func NewBinaryRequestParser(in io.Reader) RequestParser {
+	s := bufio.NewScanner(in)
+	s.Buffer(nil, maxBinaryMessageSize)
+	s.Split(bufio.ScanWords)
+	return &binaryRequestParser{s: s}
+}
+
+func (f *binaryRequestParser) Next(m protoV2.Message) error {
+	if !f.s.Scan() {
+		if err := f.s.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	b, err := base64.StdEncoding.DecodeString(f.s.Text())
+	if err != nil {
+		return fmt.Errorf("request is not valid base64: %w", err)
+	}
+	f.requestCount++
+	return proto.Unmarshal(b, proto.MessageV1(m))
+}
+
+func (f *binaryRequestParser) NumRequests() int {
+	return f.requestCount
+}
+
 // Formatter translates messages into string representations.
 type Formatter func(proto.Message) (string, error)
 
@@ -136,10 +178,81 @@ func NewJSONFormatter(emitDefaults bool, resolver jsonpb.AnyResolver) Formatter
 
 }
 
+// NewTextFormatter returns a formatter that returns strings in the protobuf
+// text format. If includeSeparator is true then, when invoked to format
+// multiple messages, all messages after the first one will be prefixed with
+// the ASCII 'Record Separator' character (0x1E).
+func NewTextFormatter(includeSeparator bool) Formatter {
+	tf := textFormatter{useSeparator: includeSeparator}
+	return tf.format
+}
+
+type textFormatter struct {
+	useSeparator bool
+	numFormatted int
+}
+
+var protoTextMarshaler = proto.TextMarshaler{ExpandAny: true}
+
+func (tf *textFormatter) format(m proto.Message) (string, error) {
+	var buf bytes.Buffer
+	if tf.useSeparator && tf.numFormatted > 0 {
+		if err := buf.WriteByte(textSeparatorChar); err != nil {
+			return "", err
+		}
+	}
+
+	// If message implements MarshalText method (such as a *dynamic.Message),
+	// it won't get details about whether or not to format to text compactly
+	// or with indentation. So first see if the message also implements a
+	// MarshalTextIndent method and use that instead if available.
+	type indentMarshaler interface {
+		MarshalTextIndent() ([]byte, error)
+	}
+
+	if indenter, ok := m.(indentMarshaler); ok {
+		b, err := indenter.MarshalTextIndent()
+		if err != nil {
+			return "", err
+		}
+		if _, err := buf.Write(b); err != nil {
+			return "", err
+		}
+	} else if err := protoTextMarshaler.Marshal(&buf, m); err != nil {
+		return "", err
+	}
+
+	// no trailing newline needed
+	str := buf.String()
+	if len(str) > 0 && str[len(str)-1] == '\n' {
+		str = str[:len(str)-1]
+	}
+
+	tf.numFormatted++
+
+	return str, nil
+}
+
+// NewBinaryFormatter returns a formatter that returns messages in the
+// protobuf binary wire format, encoded as standard base64.
+func NewBinaryFormatter() Formatter {
+	return func(m proto.Message) (string, error) {
+		b, err := proto.Marshal(m)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+}
+
 type Format string
 
 const (
 	FormatJSON = Format("json")
+	FormatText = Format("text")
+	// FormatBinary is the protobuf wire format, encoded as base64 so that
+	// it can be stored and reported as text
+	FormatBinary = Format("binary")
 )
 
 // AnyResolverFromDescriptorSource returns an AnyResolver that will search for
@@ -254,6 +367,13 @@ func (r anyResolverWithFallback) Resolve(typeUrl string) (proto.Message, error)
 	return proto.MessageV1(a.New()), nil
 }
 
+// IsUnresolvedAny reports whether the message is the fallback value of
+// AnyResolverFromDescriptorSourceWithFallback for a type it could not find
+func IsUnresolvedAny(m proto.Message) bool {
+	_, ok := m.(*unknownAny)
+	return ok
+}
+
 type unknownAny struct {
 	TypeUrl string `json:"@type"`
 	Error   string `json:"@error"`
@@ -291,14 +411,18 @@ var _ proto.Message = (*unknownAny)(nil)
 
 // RequestParserAndFormatterFor returns a request parser and formatter for the
 // given format. The given descriptor source may be used for parsing message
-// data (if needed by the format). The flags emitJSONDefaultFields and
-// includeTextSeparator are options for JSON and protobuf text formats,
-// respectively. Requests will be parsed from the given in.
+// data (if needed by the format). The flag emitJSONDefaultFields is an
+// option for the JSON format, the text format always separates multiple
+// messages. Requests will be parsed from the given in.
 func RequestParserAndFormatterFor(format Format, descSource DescriptorSource, emitJSONDefaultFields bool, in io.Reader) (RequestParser, Formatter, error) {
 	switch format {
 	case FormatJSON:
 		resolver := AnyResolverFromDescriptorSourceWithFallback(descSource)
 		return NewJSONRequestParser(in, resolver), NewJSONFormatter(emitJSONDefaultFields, anyResolverWithFallback{AnyResolver: resolver}), nil
+	case FormatText:
+		return NewTextRequestParser(in), NewTextFormatter(true), nil
+	case FormatBinary:
+		return NewBinaryRequestParser(in), NewBinaryFormatter(), nil
 	default:
 		return nil, nil, fmt.Errorf("unknown format: %s", format)
 	}
@@ -0,0 +1,64 @@
+package reflect
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestBinaryFormat(t *testing.T) {
+	encoded, err := NewBinaryFormatter()(proto.MessageV1(wrapperspb.String("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "CgVoZWxsbw==" {
+		t.Fatalf("unexpected encoding %q", encoded)
+	}
+
+	p := NewBinaryRequestParser(strings.NewReader(encoded + "\n" + encoded))
+	for i := 0; i < 2; i++ {
+		var m wrapperspb.StringValue
+		if err := p.Next(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Value != "hello" {
+			t.Fatalf("expected hello but found %q", m.Value)
+		}
+	}
+	if err := p.Next(&wrapperspb.StringValue{}); err != io.EOF {
+		t.Fatalf("expected EOF but found %v", err)
+	}
+	if p.NumRequests() != 2 {
+		t.Fatalf("expected 2 requests but found %d", p.NumRequests())
+	}
+
+	if err := NewBinaryRequestParser(strings.NewReader("not base64!")).Next(&wrapperspb.StringValue{}); err == nil {
+		t.Fatalf("expected invalid base64 to be rejected")
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	f := NewTextFormatter(true)
+	first, err := f(proto.MessageV1(wrapperspb.String("a")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f(proto.MessageV1(wrapperspb.String("b")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewTextRequestParser(strings.NewReader(first + second))
+	for _, want := range []string{"a", "b"} {
+		var m wrapperspb.StringValue
+		if err := p.Next(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Value != want {
+			t.Fatalf("expected %q but found %q", want, m.Value)
+		}
+	}
+}
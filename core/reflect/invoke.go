@@ -133,11 +133,11 @@ func invokeUnary(ctx context.Context, stub grpcdynamic.Stub, md *desc.MethodDesc
 		return "", fmt.Errorf("grpc call for %q failed: %v", md.GetFullyQualifiedName(), err)
 	}
 
-	var r string
+	handler.OnReceiveTrailers(stat, respTrailers)
 	if stat.Code() == codes.OK {
 		return handler.OnReceiveResponse(protov1.MessageV2(resp))
 	}
-	return r, nil
+	return handler.OnReceiveResponse(protov1.MessageV2(stat.Proto()))
 }
 
 type notFoundError string
@@ -0,0 +1,190 @@
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"go.starlark.net/starlark"
+)
+
+// globals returns the functions available to every script:
+//
+//   value(path)          resolves a gjson path against the flow context
+//   set(name, value)     defines a flow variable
+//   fail(msg)            fails the script
+//   check(cond, msg)     fails the script unless cond holds
+//   encode(value)        encodes a value as json
+//   decode(str)          decodes json
+func globals(c Context, failure *string) starlark.StringDict {
+	return starlark.StringDict{
+		"value": starlark.NewBuiltin("value", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var path string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &path); err != nil {
+				return nil, err
+			}
+			return ToStarlark(c.Value(path))
+		}),
+		"set": starlark.NewBuiltin("set", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var name string
+			var v starlark.Value
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &name, &v); err != nil {
+				return nil, err
+			}
+			gv, err := FromStarlark(v)
+			if err != nil {
+				return nil, err
+			}
+			c.Set(name, gv)
+			return starlark.None, nil
+		}),
+		"fail": starlark.NewBuiltin("fail", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var msg string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &msg); err != nil {
+				return nil, err
+			}
+			*failure = msg
+			return nil, errFailed
+		}),
+		"check": starlark.NewBuiltin("check", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var cond starlark.Value
+			var msg string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &cond, &msg); err != nil {
+				return nil, err
+			}
+			if !cond.Truth() {
+				*failure = msg
+				return nil, errFailed
+			}
+			return starlark.None, nil
+		}),
+		"encode": starlark.NewBuiltin("encode", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var v starlark.Value
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &v); err != nil {
+				return nil, err
+			}
+			gv, err := FromStarlark(v)
+			if err != nil {
+				return nil, err
+			}
+			out, err := json.Marshal(gv)
+			if err != nil {
+				return nil, err
+			}
+			return starlark.String(out), nil
+		}),
+		"decode": starlark.NewBuiltin("decode", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var s string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &s); err != nil {
+				return nil, err
+			}
+			var v interface{}
+			if err := json.Unmarshal([]byte(s), &v); err != nil {
+				return nil, fmt.Errorf("decode: %w", err)
+			}
+			return ToStarlark(v)
+		}),
+	}
+}
+
+// ToStarlark converts a value decoded from json into a Starlark value.
+// Integral numbers become ints so that they can be used as indexes.
+func ToStarlark(v interface{}) (starlark.Value, error) {
+	switch t := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(t), nil
+	case string:
+		return starlark.String(t), nil
+	case int:
+		return starlark.MakeInt(t), nil
+	case int64:
+		return starlark.MakeInt64(t), nil
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return starlark.MakeInt64(int64(t)), nil
+		}
+		return starlark.Float(t), nil
+	case []interface{}:
+		elems := make([]starlark.Value, len(t))
+		for i, e := range t {
+			sv, err := ToStarlark(e)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = sv
+		}
+		return starlark.NewList(elems), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d := starlark.NewDict(len(t))
+		for _, k := range keys {
+			sv, err := ToStarlark(t[k])
+			if err != nil {
+				return nil, err
+			}
+			d.SetKey(starlark.String(k), sv)
+		}
+		return d, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %T for scripts", v)
+	}
+	var decoded interface{}
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		return nil, err
+	}
+	return ToStarlark(decoded)
+}
+
+// FromStarlark converts a Starlark value into the types json decodes to,
+// numbers being float64
+func FromStarlark(v starlark.Value) (interface{}, error) {
+	switch t := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(t), nil
+	case starlark.String:
+		return string(t), nil
+	case starlark.Int:
+		if i, ok := t.Int64(); ok {
+			return float64(i), nil
+		}
+		return float64(t.Float()), nil
+	case starlark.Float:
+		return float64(t), nil
+	case starlark.Indexable:
+		out := make([]interface{}, t.Len())
+		for i := range out {
+			e, err := FromStarlark(t.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	case *starlark.Dict:
+		out := make(map[string]interface{}, t.Len())
+		for _, item := range t.Items() {
+			k, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings, found %s", item[0].Type())
+			}
+			e, err := FromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			out[string(k)] = e
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s from scripts", v.Type())
+}
@@ -0,0 +1,188 @@
+// Package script runs the hooks of a testcase, written in Starlark, in a
+// sandbox: scripts cannot load modules or reach the host, and every run is
+// bounded in time and in execution steps.
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/pkg/log"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+)
+
+const (
+	// DefaultTimeout bounds a script whose limits were left unset
+	DefaultTimeout = time.Second
+	// DefaultMaxSteps bounds the execution steps of a script whose limits
+	// were left unset
+	DefaultMaxSteps = 1000000
+)
+
+// Context is the part of the flow context scripts have access to, Value
+// resolves a gjson path and Set defines a variable
+type Context interface {
+	Value(path string) interface{}
+	Set(name string, v interface{})
+}
+
+// Limits bounds a single run of a script
+type Limits struct {
+	Timeout  time.Duration
+	MaxSteps uint64
+}
+
+// Request is what a pre-request hook can change before it is sent
+type Request struct {
+	Method  string
+	Path    string
+	Body    string
+	Headers map[string]string
+}
+
+// Script is a compiled script, it can be run any number of times
+type Script struct {
+	name   string
+	prog   *starlark.Program
+	limits Limits
+}
+
+func init() {
+	// scripts are written top to bottom like test code, let them loop and
+	// branch outside of functions
+	resolve.AllowGlobalReassign = true
+}
+
+// errFailed marks a script stopped by fail or check
+var errFailed = errors.New("script failed")
+
+// Compile parses a script, names available to it are checked here so that
+// typos are reported before anything runs
+func Compile(name, src string, limits Limits) (*Script, error) {
+	if limits.Timeout <= 0 {
+		limits.Timeout = DefaultTimeout
+	}
+	if limits.MaxSteps == 0 {
+		limits.MaxSteps = DefaultMaxSteps
+	}
+	predeclared := globals(nil, nil)
+	predeclared["request"] = starlark.None
+	predeclared["response"] = starlark.None
+	_, prog, err := starlark.SourceProgram(name, src, predeclared.Has)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script %q: %w", name, err)
+	}
+	return &Script{name: name, prog: prog, limits: limits}, nil
+}
+
+// Before runs the script as a pre-request hook. The request is exposed as
+// the mutable dict `request` with the keys method, path, body and headers.
+func (s *Script) Before(ctx context.Context, c Context, req *Request) error {
+	headers := new(starlark.Dict)
+	for k, v := range req.Headers {
+		headers.SetKey(starlark.String(k), starlark.String(v))
+	}
+	r := new(starlark.Dict)
+	r.SetKey(starlark.String("method"), starlark.String(req.Method))
+	r.SetKey(starlark.String("path"), starlark.String(req.Path))
+	r.SetKey(starlark.String("body"), starlark.String(req.Body))
+	r.SetKey(starlark.String("headers"), headers)
+
+	var failure string
+	predeclared := globals(c, &failure)
+	predeclared["request"] = r
+	if err := s.exec(ctx, predeclared); err != nil {
+		if failure != "" {
+			return fmt.Errorf("script %q failed: %s", s.name, failure)
+		}
+		return err
+	}
+
+	v, err := FromStarlark(r)
+	if err != nil {
+		return fmt.Errorf("script %q left an invalid request: %w", s.name, err)
+	}
+	m := v.(map[string]interface{})
+	var ok bool
+	if req.Method, ok = m["method"].(string); !ok {
+		return fmt.Errorf("script %q left an invalid request method", s.name)
+	}
+	if req.Path, ok = m["path"].(string); !ok {
+		return fmt.Errorf("script %q left an invalid request path", s.name)
+	}
+	if req.Body, ok = m["body"].(string); !ok {
+		return fmt.Errorf("script %q left an invalid request body, use encode for objects", s.name)
+	}
+	hs, ok := m["headers"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("script %q left invalid request headers", s.name)
+	}
+	req.Headers = make(map[string]string, len(hs))
+	for k, v := range hs {
+		if req.Headers[k], ok = v.(string); !ok {
+			return fmt.Errorf("script %q left a non string value for header %q", s.name, k)
+		}
+	}
+	return nil
+}
+
+// After runs the script as a post-response check, the response is exposed
+// as the frozen dict `response` with the keys status and body. The result
+// fails when the script calls fail or a check does not hold, any other
+// error is returned as is.
+func (s *Script) After(ctx context.Context, c Context, status int, body interface{}) (asserter.Result, error) {
+	b, err := ToStarlark(body)
+	if err != nil {
+		return asserter.Result{}, err
+	}
+	r := new(starlark.Dict)
+	r.SetKey(starlark.String("status"), starlark.MakeInt(status))
+	r.SetKey(starlark.String("body"), b)
+	r.Freeze()
+
+	var failure string
+	predeclared := globals(c, &failure)
+	predeclared["response"] = r
+	if err := s.exec(ctx, predeclared); err != nil {
+		if failure != "" {
+			return asserter.Result{Message: failure}, nil
+		}
+		return asserter.Result{}, err
+	}
+	return asserter.Result{Passed: true}, nil
+}
+
+// exec runs the program in a fresh thread, cancelled once the time limit
+// or the deadline of ctx passes
+func (s *Script) exec(ctx context.Context, predeclared starlark.StringDict) error {
+	thread := &starlark.Thread{
+		Name: s.name,
+		Print: func(_ *starlark.Thread, msg string) {
+			log.GetLogger(ctx).Debugf("script %s: %s", s.name, msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(s.limits.MaxSteps)
+
+	ctx, cancel := context.WithTimeout(ctx, s.limits.Timeout)
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(fmt.Sprintf("script exceeded its time limit of %s", s.limits.Timeout))
+		case <-done:
+		}
+	}()
+
+	_, err := s.prog.Init(thread, predeclared)
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return fmt.Errorf("script %q: %s", s.name, evalErr.Msg)
+	}
+	return err
+}
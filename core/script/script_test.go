@@ -0,0 +1,165 @@
+package script
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+)
+
+type vars map[string]interface{}
+
+func (v vars) Value(path string) interface{} { return v[path] }
+
+func (v vars) Set(name string, value interface{}) { v[name] = value }
+
+func TestBefore(t *testing.T) {
+	s, err := Compile("sign", `
+body = decode(request["body"])
+body["user"] = value("steps.login.body.user")
+request["body"] = encode(body)
+request["path"] = request["path"] + "?v=2"
+request["headers"]["x-user"] = body["user"]
+`, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := vars{"steps.login.body.user": "thejas"}
+	req := Request{Method: "POST", Path: "/orders", Body: `{"id": 1}`}
+	if err := s.Before(context.Background(), c, &req); err != nil {
+		t.Fatal(err)
+	}
+	want := Request{
+		Method:  "POST",
+		Path:    "/orders?v=2",
+		Body:    `{"id":1,"user":"thejas"}`,
+		Headers: map[string]string{"x-user": "thejas"},
+	}
+	if !reflect.DeepEqual(req, want) {
+		t.Fatalf("expected %+v but found %+v", want, req)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	body := map[string]interface{}{
+		"total": 7.5,
+		"items": []interface{}{
+			map[string]interface{}{"price": 2.5},
+			map[string]interface{}{"price": 5.0},
+		},
+	}
+	tests := []struct {
+		name string
+		src  string
+		want asserter.Result
+		vars vars
+		err  string
+	}{
+		{
+			name: "sum of line items",
+			src: `
+total = 0
+for item in response["body"]["items"]:
+    total += item["price"]
+check(total == response["body"]["total"], "line items do not add up to the total")
+set("count", len(response["body"]["items"]))
+`,
+			want: asserter.Result{Passed: true},
+			vars: vars{"count": 2.0},
+		},
+		{
+			name: "check",
+			src:  `check(response["status"] == 201, "expected status 201 but found %d" % response["status"])`,
+			want: asserter.Result{Message: "expected status 201 but found 200"},
+			vars: vars{},
+		},
+		{
+			name: "fail",
+			src:  `fail("no")`,
+			want: asserter.Result{Message: "no"},
+			vars: vars{},
+		},
+		{
+			name: "runtime error",
+			src:  `response["body"]["missing"]`,
+			err:  `key "missing" not in dict`,
+			vars: vars{},
+		},
+		{
+			name: "frozen response",
+			src:  `response["body"]["total"] = 1`,
+			err:  "frozen",
+			vars: vars{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile(tt.name, tt.src, Limits{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := vars{}
+			got, err := s.After(context.Background(), c, 200, body)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q but found %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v but found %+v", tt.want, got)
+			}
+			if !reflect.DeepEqual(c, tt.vars) {
+				t.Fatalf("expected variables %v but found %v", tt.vars, c)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	if _, err := Compile("typo", `chek(True, "")`, Limits{}); err == nil {
+		t.Fatal("expected undefined name to be reported")
+	}
+	if _, err := Compile("load", `load("os.star", "exec")`, Limits{}); err != nil {
+		t.Fatal(err)
+	}
+
+	loop := `
+n = 0
+for i in range(100000000):
+    n += i
+`
+	s, err := Compile("steps", loop, Limits{MaxSteps: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.After(context.Background(), vars{}, 200, nil); err == nil || !strings.Contains(err.Error(), "too many steps") {
+		t.Fatalf("expected step limit error but found %v", err)
+	}
+
+	s, err = Compile("slow", loop, Limits{Timeout: 20 * time.Millisecond, MaxSteps: 1 << 62})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := s.After(context.Background(), vars{}, 200, nil); err == nil || !strings.Contains(err.Error(), "time limit") {
+		t.Fatalf("expected time limit error but found %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("script ran past its time limit")
+	}
+
+	s, err = Compile("load", `load("os.star", "exec")`, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.After(context.Background(), vars{}, 200, nil); err == nil {
+		t.Fatal("expected load to be refused")
+	}
+}
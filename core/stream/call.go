@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/thejasn/tester/core/client"
+	"github.com/thejasn/tester/core/expr"
+)
+
+// Call runs another flow as a single step. The sub-flow gets a context of
+// its own where Inputs, expressions evaluated against the calling flow, are
+// set as variables. Once it passed, its Exports are evaluated against that
+// context and become the body of the calling step, so that later steps can
+// refer to them as steps.<name>.body.<export>. Engine returns the engine
+// running the main steps of the sub-flow within the given context.
+type Call struct {
+	FlowID  int
+	Inputs  map[string]string
+	Exports map[string]string
+	Phases  Phases
+	Engine  func(Context) Engine
+}
+
+// run executes the sub-flow, the response carries its exports as json
+func (c *Call) run(ctx context.Context, parent Context) (Report, client.Response, error) {
+	child := NewInMemoryContext()
+	if err := assign(c.Inputs, parent, child.Set); err != nil {
+		return Report{}, client.Response{}, fmt.Errorf("invalid input of flow %d, %v", c.FlowID, err)
+	}
+	r := c.Phases.Run(ctx, child, c.Engine(child))
+	if !r.Passed {
+		return r, client.Response{}, nil
+	}
+	exports := make(map[string]interface{}, len(c.Exports))
+	err := assign(c.Exports, child, func(name string, v interface{}) {
+		exports[name] = v
+	})
+	if err != nil {
+		return r, client.Response{}, fmt.Errorf("invalid export of flow %d, %v", c.FlowID, err)
+	}
+	b, err := json.Marshal(exports)
+	if err != nil {
+		return r, client.Response{}, err
+	}
+	return r, client.Response{Body: string(b)}, nil
+}
+
+// assign evaluates expressions against c and hands their values to set, in
+// the order of their names
+func assign(exprs map[string]string, c Context, set func(string, interface{})) error {
+	names := make([]string, 0, len(exprs))
+	for name := range exprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e, err := expr.Compile(exprs[name])
+		if err != nil {
+			return fmt.Errorf("%q: %v", name, err)
+		}
+		v, err := e.Eval(c)
+		if err != nil {
+			return fmt.Errorf("%q: %v", name, err)
+		}
+		set(name, v)
+	}
+	return nil
+}
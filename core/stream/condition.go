@@ -0,0 +1,12 @@
+package stream
+
+import (
+	"github.com/thejasn/tester/core/expr"
+)
+
+// Evaluate resolves a step condition against the flow context, for example
+// `steps.check.status == 404`. Conditions are expressions of package expr,
+// a value holds when it is anything other than null, false, 0 or "".
+func Evaluate(condition string, c Context) (bool, error) {
+	return expr.Evaluate(condition, c)
+}
@@ -0,0 +1,53 @@
+package stream
+
+import (
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	c := NewInMemoryContext()
+	c.Bind("check", 404, map[string]interface{}{
+		"name":  "foo",
+		"count": 2,
+		"ok":    false,
+	})
+
+	cases := []struct {
+		Input  string
+		Output bool
+	}{
+		{"steps.check.status == 404", true},
+		{"steps.check.status != 404", false},
+		{"steps.check.status >= 400 && steps.check.status < 500", true},
+		{"steps.check.status == 200 || steps.check.body.name == 'foo'", true},
+		{`steps.check.body.name == "foo && bar"`, false},
+		{"steps.check.body.count > 1", true},
+		{"steps.check.body.ok", false},
+		{"!steps.check.body.ok", true},
+		{"steps.missing.status == null", true},
+		{"steps.missing", false},
+		{"steps.check.status > 1s", false},
+		{"steps.check.status < 1.5s", true},
+	}
+
+	for _, tc := range cases {
+		actual, err := Evaluate(tc.Input, c)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.Input, err)
+		}
+		if actual != tc.Output {
+			t.Fatalf("%s: bad: %v", tc.Input, actual)
+		}
+	}
+}
+
+func TestEvaluateInvalid(t *testing.T) {
+	c := NewInMemoryContext()
+	c.Bind("check", 200, "plain")
+
+	for _, expr := range []string{"", "steps.check.body > 1"} {
+		if _, err := Evaluate(expr, c); err == nil {
+			t.Fatalf("%q: expected error", expr)
+		}
+	}
+}
@@ -2,42 +2,75 @@ package stream
 
 import (
 	"encoding/json"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/thejasn/tester/core/util/flatmap"
 	"github.com/tidwall/gjson"
 )
 
+// Context is the state shared by the steps of a flow. Implementations must
+// be safe for concurrent use.
 type Context interface {
 	Mapper(int, string) string
 	Store(int, interface{})
 	Get(int) interface{}
+	// Bind records the outcome of a named step, making it addressable as
+	// steps.<name>.status and steps.<name>.body
+	Bind(name string, status int, body interface{})
+	// Set defines a variable, such as the current item of a loop
+	Set(name string, v interface{})
+	// Value resolves a gjson path against the bound steps and variables,
+	// nil is returned when nothing matches
+	Value(path string) interface{}
+	// Render replaces every {{path}} in the input with its value
+	Render(string) string
+	// Fork returns a context sharing the bound steps but with its own copy
+	// of the variables, so that concurrent branches don't clobber each other
+	Fork() Context
+	// Vars returns a copy of the variables
+	Vars() map[string]interface{}
+}
+
+type store struct {
+	mu    sync.RWMutex
+	ctx   map[int]interface{}
+	steps map[string]interface{}
 }
 
 type InMemoryContext struct {
-	ctx map[int]interface{}
+	*store
+	vars map[string]interface{}
 }
 
 func NewInMemoryContext() *InMemoryContext {
 	return &InMemoryContext{
-		ctx: make(map[int]interface{}),
+		store: &store{
+			ctx:   make(map[int]interface{}),
+			steps: make(map[string]interface{}),
+		},
+		vars: make(map[string]interface{}),
 	}
 }
 
-func (c InMemoryContext) Mapper(actionID int, input string) string {
+func (c *InMemoryContext) Mapper(actionID int, input string) string {
 	if gjson.Valid(input) {
 		if m, ok := gjson.Parse(input).Value().(map[string]interface{}); ok {
 			newMap := map[string]interface{}{
 				"CONST": m,
 			}
 			fm := flatmap.Flatten(newMap)
+			c.mu.RLock()
 			src, err := json.Marshal(c.ctx)
+			c.mu.RUnlock()
 			if err != nil {
 				panic(err)
 			}
 			for k, v := range fm {
 				path := strings.TrimPrefix(v, "$")
-				newVal := gjson.Get(string(src), strings.Join([]string{string(actionID), path}, ".")).String()
+				newVal := gjson.Get(string(src), strings.Join([]string{strconv.Itoa(actionID), path}, ".")).String()
 				fm[k] = newVal
 			}
 			if result, ok := flatmap.Expand(fm, "CONST").(map[string]interface{}); ok {
@@ -53,9 +86,82 @@ func (c InMemoryContext) Mapper(actionID int, input string) string {
 }
 
 func (c *InMemoryContext) Store(k int, v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.ctx[k] = v
 }
 
-func (c InMemoryContext) Get(k int) interface{} {
+func (c *InMemoryContext) Get(k int) interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.ctx[k]
 }
+
+func (c *InMemoryContext) Bind(name string, status int, body interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps[name] = map[string]interface{}{
+		"status": status,
+		"body":   body,
+	}
+}
+
+func (c *InMemoryContext) Set(name string, v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vars[name] = v
+}
+
+func (c *InMemoryContext) Value(path string) interface{} {
+	c.mu.RLock()
+	doc := map[string]interface{}{
+		"steps": c.steps,
+	}
+	for k, v := range c.vars {
+		doc[k] = v
+	}
+	src, err := json.Marshal(doc)
+	c.mu.RUnlock()
+	if err != nil {
+		return nil
+	}
+	return gjson.GetBytes(src, path).Value()
+}
+
+var placeholder = regexp.MustCompile(`{{\s*([^{}]+?)\s*}}`)
+
+// Render substitutes placeholders, strings are inserted as is and any other
+// value as json. Unknown paths render as an empty string.
+func (c *InMemoryContext) Render(input string) string {
+	return placeholder.ReplaceAllStringFunc(input, func(m string) string {
+		v := c.Value(placeholder.FindStringSubmatch(m)[1])
+		switch t := v.(type) {
+		case nil:
+			return ""
+		case string:
+			return t
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	})
+}
+
+func (c *InMemoryContext) Fork() Context {
+	return &InMemoryContext{
+		store: c.store,
+		vars:  c.Vars(),
+	}
+}
+
+func (c *InMemoryContext) Vars() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	vars := make(map[string]interface{}, len(c.vars))
+	for k, v := range c.vars {
+		vars[k] = v
+	}
+	return vars
+}
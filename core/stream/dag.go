@@ -0,0 +1,243 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultParallelism bounds a DAG whose parallelism was left unset
+const DefaultParallelism = 4
+
+// DAG runs nodes as soon as the steps they need have completed, with at
+// most Parallelism nodes in flight. Groups and loops are scheduled as a
+// single unit which needs whatever its steps need. A unit sees the variables
+// defined by the units it needs, directly or not, and every variable reaches
+// Ctx once the run is over.
+type DAG struct {
+	Parallelism int
+	Ctx         Context
+	Report      Report
+}
+
+func NewDAGFlow(parallelism int) DAG {
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	return DAG{
+		Parallelism: parallelism,
+		Ctx:         NewInMemoryContext(),
+	}
+}
+
+// unit is a node scheduled by the DAG along with its bookkeeping
+type unit struct {
+	node     Node
+	ctx      Context
+	name     string
+	needs    []string
+	pending  int
+	blocked  string
+	report   Report
+	finished time.Duration
+	previous int
+	done     bool
+}
+
+// Run executes the nodes and returns a report listing steps in the order
+// they were declared. Steps whose dependencies failed or were skipped are
+// skipped themselves, as are steps caught in a dependency cycle.
+func (d *DAG) Run(ctx context.Context, nodes ...Node) Report {
+	start := time.Now()
+	units, provides, err := plan(nodes)
+	if err != nil {
+		for _, s := range steps(nodes) {
+			d.Report.add(StepResult{ID: s.ID, Name: s.Name, Status: Errored, Message: err.Error()})
+		}
+		return d.Report.Evaluate()
+	}
+
+	dependents := make([][]int, len(units))
+	for i, u := range units {
+		for _, n := range u.needs {
+			p := provides[n]
+			dependents[p] = append(dependents[p], i)
+			u.pending++
+		}
+	}
+
+	type result struct {
+		idx    int
+		report Report
+	}
+	results := make(chan result)
+	sem := make(chan struct{}, d.Parallelism)
+	running := 0
+	keys := new(int64)
+
+	var ready []int
+	for i, u := range units {
+		if u.pending == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	for len(ready) > 0 || running > 0 {
+		for len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			u := units[i]
+			if u.blocked != "" {
+				u.report = skipAll(u.node, u.blocked)
+				ready = append(ready, d.complete(units, dependents, provides, i)...)
+				continue
+			}
+			running++
+			u.ctx = d.scope(units, provides, u)
+			go func(i int, u *unit) {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				l := newLinear(u.ctx)
+				l.keys = keys
+				results <- result{idx: i, report: l.Run(ctx, u.node)}
+			}(i, u)
+		}
+		if running == 0 {
+			continue
+		}
+		r := <-results
+		running--
+		units[r.idx].report = r.report
+		ready = append(ready, d.complete(units, dependents, provides, r.idx)...)
+	}
+
+	for _, u := range units {
+		if !u.done {
+			u.report = skipAll(u.node, "dependency cycle between "+strings.Join(u.needs, ", "))
+		}
+		d.Report.Steps = append(d.Report.Steps, u.report.Steps...)
+		if u.ctx != nil {
+			for k, v := range u.ctx.Vars() {
+				d.Ctx.Set(k, v)
+			}
+		}
+	}
+	d.Report.CriticalPath = criticalPath(units)
+	d.Report.Duration = time.Since(start)
+	return d.Report.Evaluate()
+}
+
+// scope returns the context a unit runs in, a fork of Ctx holding the
+// variables of the units it needs. Those units have completed, their own
+// context holding the variables of the units they needed in turn.
+func (d *DAG) scope(units []*unit, provides map[string]int, u *unit) Context {
+	c := d.Ctx.Fork()
+	for _, n := range u.needs {
+		if p := units[provides[n]]; p.ctx != nil {
+			for k, v := range p.ctx.Vars() {
+				c.Set(k, v)
+			}
+		}
+	}
+	return c
+}
+
+// complete marks a unit as done and returns the dependents it released. A
+// dependent is blocked when a step it needs did not pass.
+func (d *DAG) complete(units []*unit, dependents [][]int, provides map[string]int, i int) []int {
+	u := units[i]
+	u.done = true
+	for _, s := range u.report.Steps {
+		u.finished += s.Duration
+	}
+	if prev := u.previous; prev >= 0 {
+		u.finished += units[prev].finished
+	}
+
+	failed := make(map[string]bool)
+	for _, s := range u.report.Steps {
+		if s.Status != Passed {
+			failed[s.Name] = true
+		}
+	}
+
+	var released []int
+	for _, j := range dependents[i] {
+		dep := units[j]
+		for _, n := range dep.needs {
+			if provides[n] == i && failed[n] && dep.blocked == "" {
+				dep.blocked = fmt.Sprintf("dependency %q did not pass", n)
+			}
+		}
+		if dep.previous < 0 || units[dep.previous].finished < u.finished {
+			dep.previous = i
+		}
+		dep.pending--
+		if dep.pending == 0 {
+			released = append(released, j)
+		}
+	}
+	return released
+}
+
+// plan turns nodes into units, resolving which unit provides every step
+func plan(nodes []Node) ([]*unit, map[string]int, error) {
+	units := make([]*unit, 0, len(nodes))
+	provides := make(map[string]int)
+	for _, n := range nodes {
+		inner := steps([]Node{n})
+		if len(inner) == 0 {
+			continue
+		}
+		u := &unit{node: n, name: inner[0].Name, previous: -1}
+		for _, s := range inner {
+			if _, ok := provides[s.Name]; ok {
+				return nil, nil, fmt.Errorf("step name %q is not unique", s.Name)
+			}
+			provides[s.Name] = len(units)
+		}
+		units = append(units, u)
+	}
+	for _, u := range units {
+		seen := make(map[string]bool)
+		for _, s := range steps([]Node{u.node}) {
+			for _, n := range s.Needs {
+				p, ok := provides[n]
+				if !ok {
+					return nil, nil, fmt.Errorf("step %q needs unknown step %q", s.Name, n)
+				}
+				if units[p] == u || seen[n] {
+					continue
+				}
+				seen[n] = true
+				u.needs = append(u.needs, n)
+			}
+		}
+	}
+	return units, provides, nil
+}
+
+func skipAll(n Node, msg string) Report {
+	var r Report
+	for _, s := range steps([]Node{n}) {
+		r.add(StepResult{ID: s.ID, Name: s.Name, Status: Skipped, Message: msg})
+	}
+	return r
+}
+
+// criticalPath follows the slowest chain of dependencies back from the unit
+// that finished last
+func criticalPath(units []*unit) []string {
+	last := -1
+	for i, u := range units {
+		if last < 0 || u.finished > units[last].finished {
+			last = i
+		}
+	}
+	var path []string
+	for i := last; i >= 0; i = units[i].previous {
+		path = append([]string{units[i].name}, path...)
+	}
+	return path
+}
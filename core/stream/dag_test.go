@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
+	"github.com/thejasn/tester/core/tester"
+)
+
+// sleeper returns a step that takes d to respond, tracking how many steps
+// run at the same time
+func sleeper(name string, d time.Duration, inflight, peak *int32, needs ...string) Step {
+	return Step{
+		Name:  name,
+		Needs: needs,
+		Exec: func(Context) tester.Executor {
+			return func(context.Context) (string, client.Response, error) {
+				n := atomic.AddInt32(inflight, 1)
+				for {
+					p := atomic.LoadInt32(peak)
+					if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(d)
+				atomic.AddInt32(inflight, -1)
+				return name, client.Response{Status: 200, Body: `{}`}, nil
+			}
+		},
+	}
+}
+
+func TestDAG(t *testing.T) {
+	var inflight, peak int32
+	d := NewDAGFlow(2)
+	r := d.Run(context.Background(),
+		sleeper("login", 10*time.Millisecond, &inflight, &peak),
+		sleeper("cart", 30*time.Millisecond, &inflight, &peak, "login"),
+		sleeper("profile", 10*time.Millisecond, &inflight, &peak, "login"),
+		sleeper("checkout", 10*time.Millisecond, &inflight, &peak, "cart", "profile"),
+	)
+
+	if !r.Passed {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+	if peak != 2 {
+		t.Fatalf("bad parallelism: %d", peak)
+	}
+	expected := []string{"login", "cart", "checkout"}
+	if !reflect.DeepEqual(r.CriticalPath, expected) {
+		t.Fatalf("bad critical path: %#v", r.CriticalPath)
+	}
+}
+
+func TestDAGBlocked(t *testing.T) {
+	var inflight, peak int32
+	d := NewDAGFlow(4)
+	r := d.Run(context.Background(),
+		echo("login", `{"ok": false}`, asserter.Assertion{Expected: true, Actual: "ok", Operator: asserter.Equal}),
+		sleeper("cart", 0, &inflight, &peak, "login"),
+		sleeper("other", 0, &inflight, &peak),
+		sleeper("a", 0, &inflight, &peak, "b"),
+		sleeper("b", 0, &inflight, &peak, "a"),
+	)
+
+	expected := []Status{Failed, Skipped, Passed, Skipped, Skipped}
+	if !reflect.DeepEqual(statuses(r), expected) {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+}
+
+func TestDAGUnknownNeed(t *testing.T) {
+	d := NewDAGFlow(1)
+	r := d.Run(context.Background(), Step{Name: "a", Needs: []string{"missing"}})
+	if r.Passed || r.Steps[0].Status != Errored {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+}
+
+func TestDAGVars(t *testing.T) {
+	login := echo("login", `{"token": "abc"}`)
+	login.Extract = map[string]string{"token": "body.token"}
+	cart := echo("cart", `{"token": "{{token}}"}`, asserter.Assertion{Expected: "abc", Actual: "token", Operator: asserter.Equal})
+	cart.Needs = []string{"login"}
+	checkout := echo("checkout", `{"token": "{{token}}"}`, asserter.Assertion{Expected: "abc", Actual: "token", Operator: asserter.Equal})
+	checkout.Needs = []string{"cart"}
+	other := echo("other", `{"other": true}`)
+
+	d := NewDAGFlow(2)
+	r := d.Run(context.Background(), login, cart, checkout, other)
+	if !r.Passed {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+	if v := d.Ctx.Value("token"); v != "abc" {
+		t.Fatalf("bad var: %#v", v)
+	}
+	for k := 1; k <= 4; k++ {
+		if d.Ctx.Get(k) == nil {
+			t.Fatalf("no step stored under %d", k)
+		}
+	}
+}
@@ -1,48 +1,410 @@
 package stream
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
 	"github.com/thejasn/tester/core/tester"
 	"github.com/tidwall/gjson"
 )
 
+// Engine runs the nodes of a flow and reports on every step it visits. The
+// deadline of ctx bounds the whole run, steps still running when it passes
+// are reported as timed out and the remaining ones are skipped.
 type Engine interface {
-	Execute(tester.Executor) Engine
+	Run(ctx context.Context, nodes ...Node) Report
 }
 
+// Node is an element of a flow, one of Step, Group or Loop
+type Node interface {
+	node()
+}
+
+// Outcome decides what happens when the condition of a step is not met
+type Outcome string
+
+const (
+	// Skip moves on to the next step
+	Skip = Outcome("skip")
+	// Stop ends the flow without failing it
+	Stop = Outcome("stop")
+)
+
+// Step is a single testcase within a flow. When, if set, is evaluated
+// against the flow context before the step runs. Exec is handed the context
+// so that request templates can be rendered right before execution.
+// Timeout, if set, bounds every attempt of the step. Verify, if set, checks
+// the response once the assertions passed and may define variables. SLA is
+// a condition on the latency of each attempt, such as
+// `duration < 300ms && ttfb < 100ms`. Extract maps variables to expressions
+// evaluated against the response of a passing attempt. Call, if set, runs a
+// sub-flow in place of Exec.
+type Step struct {
+	ID         int
+	Name       string
+	Needs      []string
+	When       string
+	Otherwise  Outcome
+	Exec       func(Context) tester.Executor
+	Assertions []asserter.Assertion
+	Retry      Retry
+	Timeout    time.Duration
+	Verify     func(ctx context.Context, c Context, status int, body interface{}) (asserter.Result, error)
+	SLA        string
+	Extract    map[string]string
+	Call       *Call
+}
+
+func (Step) node() {}
+
+// Group is an if/else block, Then runs when the condition holds and Else
+// runs otherwise
+type Group struct {
+	When string
+	Then []Node
+	Else []Node
+}
+
+func (Group) node() {}
+
 type Linear struct {
+	keys       *int64
 	currentKey int
+	halted     string
+	index      *int
 	Ctx        Context
+	Report     Report
 }
 
 func NewLinearFlow() Linear {
+	return newLinear(NewInMemoryContext())
+}
+
+func newLinear(c Context) Linear {
 	return Linear{
-		currentKey: 1,
-		Ctx:        NewInMemoryContext(),
+		keys: new(int64),
+		Ctx:  c,
+	}
+}
+
+// Run executes the nodes strictly in order
+func (l *Linear) Run(ctx context.Context, nodes ...Node) Report {
+	start := time.Now()
+	l.run(ctx, nodes)
+	l.Report.Duration += time.Since(start)
+	return l.Report.Evaluate()
+}
+
+func (l *Linear) run(ctx context.Context, nodes []Node) {
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case Step:
+			l.Execute(ctx, t)
+		case Group:
+			l.Branch(ctx, t)
+		case Loop:
+			l.Repeat(ctx, t)
+		}
+	}
+}
+
+func (l *Linear) Execute(ctx context.Context, s Step) *Linear {
+	if err := ctx.Err(); err != nil && l.halted == "" {
+		l.halted = "flow interrupted, " + err.Error()
+	}
+	if l.halted != "" {
+		l.record(s, Skipped, l.halted, time.Time{})
+		return l
 	}
+	if s.When != "" {
+		ok, err := Evaluate(s.When, l.Ctx)
+		if err != nil {
+			l.fail(s, Errored, fmt.Sprintf("invalid condition %q: %v", s.When, err), time.Time{})
+			return l
+		}
+		if !ok {
+			msg := fmt.Sprintf("condition %q not met", s.When)
+			l.record(s, Skipped, msg, time.Time{})
+			if s.Otherwise == Stop {
+				l.halted = "flow stopped, " + msg
+			}
+			return l
+		}
+	}
+
+	// steps are stored under the order they ran in, counted across the units
+	// of a DAG
+	l.currentKey = int(atomic.AddInt64(l.keys, 1))
+	start := time.Now()
+	deadline := start.Add(s.Retry.PollFor)
+	var attempts []Attempt
+	var response string
+	for n := 1; ; n++ {
+		var a Attempt
+		a, response = l.attempt(ctx, s)
+		attempts = append(attempts, a)
+		if !s.Retry.again(a, n, deadline) || !sleep(ctx, s.Retry.wait(n+1)) {
+			break
+		}
+	}
+
+	last := attempts[len(attempts)-1]
+	r := StepResult{
+		ID:         s.ID,
+		Name:       s.Name,
+		Index:      l.index,
+		Status:     last.Status,
+		Message:    last.Message,
+		Started:    start,
+		Duration:   time.Since(start),
+		Latency:    last.Latency,
+		FirstByte:  last.FirstByte,
+		Request:    last.request,
+		Code:       last.Code,
+		Response:   response,
+		Violations: last.Violations,
+		Steps:      last.steps,
+	}
+	if s.Retry.MaxAttempts > 1 || s.Retry.PollFor > 0 {
+		r.Attempts = attempts
+	}
+	l.Report.add(r)
+	if r.Status != Passed {
+		l.halted = fmt.Sprintf("flow halted after step %q", s.Name)
+	}
+	return l
 }
 
-func (l *Linear) Execute(actionId int, fn tester.Executor, actions ...asserter.Assertion) *Linear {
-	_, content, _ := fn()
-	var dest map[string]interface{}
-	err := json.Unmarshal([]byte(content), &dest)
+// attempt executes the step once, binding its response and evaluating its
+// assertions against the value at their path, or the whole body when the
+// path is empty, and then its verification and SLA. Expression assertions
+// and extractions see the response as status and body. An attempt running
+// past its deadline is timed out whatever the runner returned and a panic
+// errors the attempt. The response is returned as reported.
+func (l *Linear) attempt(ctx context.Context, s Step) (a Attempt, response string) {
+	a = Attempt{Started: time.Now(), Status: Passed}
+	defer func() {
+		if v := recover(); v != nil {
+			a.Status, a.Message = Errored, fmt.Sprintf("step panicked: %v", v)
+		}
+		a.Duration = time.Since(a.Started)
+	}()
+
+	actx := ctx
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		actx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	var resp client.Response
+	var err error
+	if s.Call != nil {
+		var sub Report
+		sub, resp, err = s.Call.run(actx, l.Ctx)
+		a.steps = sub.Steps
+		if err == nil && !sub.Passed {
+			a.Status, a.Message = Failed, fmt.Sprintf("flow %d did not pass", s.Call.FlowID)
+			if sub.Message != "" {
+				a.Message += ", " + sub.Message
+			}
+			return a, response
+		}
+	} else {
+		_, resp, err = s.Exec(l.Ctx)(actx)
+	}
+	a.request = resp.Request
+	if actx.Err() == context.DeadlineExceeded {
+		a.Status, a.Message = TimedOut, fmt.Sprintf("step timed out after %s", s.Timeout)
+		if ctx.Err() != nil {
+			a.Message = "flow deadline exceeded"
+		}
+		return a, response
+	}
 	if err != nil {
-		panic(err)
+		a.Status, a.Message = Errored, err.Error()
+		return a, response
+	}
+	a.Code = resp.Status
+	a.Latency, a.FirstByte = resp.Timing.Total, resp.Timing.FirstByte
+	if a.Latency == 0 {
+		a.Latency = time.Since(a.Started)
+	}
+	response = resp.Body
+	if resp.Raw != "" {
+		response = resp.Raw
+	}
+	var dest interface{}
+	if err = json.Unmarshal([]byte(resp.Body), &dest); err != nil {
+		dest = resp.Body
 	}
 	l.Ctx.Store(l.currentKey, dest)
-	for _, a := range actions {
-		src, err := json.Marshal(l.Ctx.Get(actionId))
+	l.Ctx.Bind(s.Name, resp.Status, dest)
+
+	if len(resp.Violations) > 0 {
+		a.Status, a.Message, a.Violations = Failed, asserter.Summarize("contract", resp.Violations), resp.Violations
+		return a, response
+	}
+
+	src, err := json.Marshal(dest)
+	if err != nil {
+		a.Status, a.Message = Errored, err.Error()
+		return a, response
+	}
+	scope := l.Ctx.Fork()
+	scope.Set("status", resp.Status)
+	scope.Set("body", dest)
+	for _, as := range s.Assertions {
+		if as.Operator == asserter.Expression {
+			as.Actual = scope
+		} else if path := as.Actual.(string); path != "" {
+			as.Actual = gjson.GetBytes(src, path).Value()
+		} else {
+			as.Actual = dest
+		}
+		if r := as.Evaluate(); !r.Passed {
+			a.Status, a.Message, a.Violations = Failed, r.Message, r.Violations
+			return a, response
+		}
+	}
+	if s.Verify != nil {
+		r, err := s.Verify(actx, l.Ctx, resp.Status, dest)
 		if err != nil {
-			panic(err)
+			a.Status, a.Message = Errored, err.Error()
+			return a, response
 		}
-		a.Actual = gjson.Get(string(src), a.Actual.(string)).Value()
-		ok, msg := a.Assert()
-		if !ok {
-			panic(msg)
+		if !r.Passed {
+			a.Status, a.Message, a.Violations = Failed, r.Message, r.Violations
+			return a, response
 		}
 	}
-	l.currentKey++
+	if s.SLA != "" {
+		if a.Status, a.Message = l.checkSLA(s.SLA, a); a.Status != Passed {
+			return a, response
+		}
+	}
+	if err = assign(s.Extract, scope, l.Ctx.Set); err != nil {
+		a.Status, a.Message = Errored, fmt.Sprintf("could not extract %v", err)
+	}
+	return a, response
+}
+
+// checkSLA evaluates the SLA of a step against the timings of an attempt,
+// in milliseconds. The time to first byte is left unset when the runner did
+// not measure it.
+func (l *Linear) checkSLA(sla string, a Attempt) (Status, string) {
+	c := l.Ctx.Fork()
+	c.Set("duration", milliseconds(a.Latency))
+	if a.FirstByte > 0 {
+		c.Set("ttfb", milliseconds(a.FirstByte))
+	}
+	ok, err := Evaluate(sla, c)
+	if err != nil {
+		return Errored, fmt.Sprintf("invalid sla %q: %v", sla, err)
+	}
+	if !ok {
+		msg := fmt.Sprintf("sla %q not met, took %s", sla, a.Latency)
+		if a.FirstByte > 0 {
+			msg += fmt.Sprintf(" with first byte after %s", a.FirstByte)
+		}
+		return Failed, msg
+	}
+	return Passed, ""
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Branch runs either side of the group depending on its condition, steps of
+// the side not taken are reported as skipped
+func (l *Linear) Branch(ctx context.Context, g Group) *Linear {
+	taken, skipped := g.Then, g.Else
+	if l.halted == "" {
+		ok, err := Evaluate(g.When, l.Ctx)
+		if err != nil {
+			msg := fmt.Sprintf("invalid condition %q: %v", g.When, err)
+			l.Report.add(StepResult{Name: g.When, Status: Errored, Message: msg})
+			l.halted = msg
+		} else if !ok {
+			taken, skipped = g.Else, g.Then
+		}
+	}
+	l.run(ctx, taken)
+	for _, s := range steps(skipped) {
+		l.record(s, Skipped, "branch not taken", time.Time{})
+	}
+	return l
+}
+
+// Repeat runs the nodes of the loop once per item, every iteration is
+// reported with its index
+func (l *Linear) Repeat(ctx context.Context, lp Loop) *Linear {
+	if l.halted != "" {
+		for _, s := range steps(lp.Nodes) {
+			l.record(s, Skipped, l.halted, time.Time{})
+		}
+		return l
+	}
+	items, err := lp.Items(l.Ctx)
+	if err != nil {
+		l.Report.add(StepResult{Name: lp.Over, Status: Errored, Message: err.Error()})
+		l.halted = fmt.Sprintf("flow halted after loop over %q", lp.Over)
+		return l
+	}
+
+	// a nested loop hands the item and index of the enclosing one back
+	outer := l.index
+	vars := l.Ctx.Vars()
+	item, index := vars["item"], vars["index"]
+	defer func() {
+		l.index = outer
+		l.Ctx.Set("item", item)
+		l.Ctx.Set("index", index)
+	}()
+	for i, item := range items {
+		i := i
+		l.index = &i
+		l.Ctx.Set("item", item)
+		l.Ctx.Set("index", i)
+		l.run(ctx, lp.Nodes)
+	}
 	return l
 }
+
+// record adds the step to the report, start is zero for steps that never ran
+func (l *Linear) record(s Step, status Status, msg string, start time.Time) {
+	r := StepResult{ID: s.ID, Name: s.Name, Index: l.index, Status: status, Message: msg}
+	if !start.IsZero() {
+		r.Started = start
+		r.Duration = time.Since(start)
+	}
+	l.Report.add(r)
+}
+
+// fail records the step and halts the flow, remaining steps are skipped
+func (l *Linear) fail(s Step, status Status, msg string, start time.Time) {
+	l.record(s, status, msg, start)
+	l.halted = fmt.Sprintf("flow halted after step %q", s.Name)
+}
+
+// steps flattens nodes into the steps they contain
+func steps(nodes []Node) []Step {
+	var out []Step
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case Step:
+			out = append(out, t)
+		case Group:
+			out = append(out, steps(t.Then)...)
+			out = append(out, steps(t.Else)...)
+		case Loop:
+			out = append(out, steps(t.Nodes)...)
+		}
+	}
+	return out
+}
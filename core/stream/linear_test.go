@@ -0,0 +1,348 @@
+package stream
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
+	"github.com/thejasn/tester/core/expr"
+	"github.com/thejasn/tester/core/tester"
+)
+
+// echo returns a step responding with its rendered body
+func echo(name, body string, assertions ...asserter.Assertion) Step {
+	return Step{
+		Name: name,
+		Exec: func(c Context) tester.Executor {
+			rendered := c.Render(body)
+			return func(context.Context) (string, client.Response, error) {
+				return name, client.Response{Status: 200, Body: rendered}, nil
+			}
+		},
+		Assertions: assertions,
+	}
+}
+
+func statuses(r Report) []Status {
+	var out []Status
+	for _, s := range r.Steps {
+		out = append(out, s.Status)
+	}
+	return out
+}
+
+func TestLinearConditions(t *testing.T) {
+	l := NewLinearFlow()
+	create := echo("create", `{}`)
+	create.When = "steps.check.body.found == false"
+	r := l.Run(context.Background(),
+		echo("check", `{"found": true}`),
+		create,
+		Group{
+			When: "steps.check.body.found",
+			Then: []Node{echo("then", `{}`)},
+			Else: []Node{echo("else", `{}`)},
+		},
+		echo("fail", `{"a": 1}`, asserter.Assertion{Expected: 2.0, Actual: "a", Operator: asserter.Equal}),
+		echo("after", `{}`),
+	)
+
+	expected := []Status{Passed, Skipped, Passed, Skipped, Failed, Skipped}
+	if !reflect.DeepEqual(statuses(r), expected) {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+	if r.Passed {
+		t.Fatal("report should fail")
+	}
+}
+
+func TestLinearStop(t *testing.T) {
+	l := NewLinearFlow()
+	stop := echo("stop", `{}`)
+	stop.When = "steps.check.body.found == false"
+	stop.Otherwise = Stop
+
+	r := l.Run(context.Background(), echo("check", `{"found": true}`), stop, echo("after", `{}`))
+
+	expected := []Status{Passed, Skipped, Skipped}
+	if !reflect.DeepEqual(statuses(r), expected) {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+	if !r.Passed {
+		t.Fatal("stopped report should pass")
+	}
+}
+
+func TestLinearLoop(t *testing.T) {
+	l := NewLinearFlow()
+	r := l.Run(context.Background(),
+		echo("list", `{"items": [{"id": "a"}, {"id": "b"}]}`),
+		Loop{
+			Over: "steps.list.body.items.#.id",
+			Nodes: []Node{
+				echo("get", `{"id": "{{item}}", "index": {{index}}}`,
+					asserter.Assertion{Expected: "b", Actual: "id", Operator: asserter.Equal}),
+			},
+		},
+	)
+
+	if len(r.Steps) != 3 {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+	if r.Steps[1].Status != Failed || *r.Steps[1].Index != 0 {
+		t.Fatalf("bad first iteration: %#v", r.Steps[1])
+	}
+	if r.Steps[2].Status != Skipped || *r.Steps[2].Index != 1 {
+		t.Fatalf("bad second iteration: %#v", r.Steps[2])
+	}
+}
+
+func TestLoopItems(t *testing.T) {
+	c := NewInMemoryContext()
+	cases := []struct {
+		Input  string
+		Output []interface{}
+	}{
+		{`["a", 1]`, []interface{}{"a", 1.0}},
+		{"1..3", []interface{}{1.0, 2.0, 3.0}},
+		{"-1..-1", []interface{}{-1.0}},
+	}
+
+	for _, tc := range cases {
+		actual, err := Loop{Over: tc.Input}.Items(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, tc.Output) {
+			t.Fatalf("%s: bad: %#v", tc.Input, actual)
+		}
+	}
+}
+
+func TestLoopItemsInvalid(t *testing.T) {
+	c := NewInMemoryContext()
+	for _, over := range []string{
+		"5..1",
+		"0..99999999999999999999",
+		"0..10000",
+		"-9223372036854775808..9223372036854775807",
+		`["a"`,
+		"steps.missing",
+	} {
+		if items, err := (Loop{Over: over}).Items(c); err == nil {
+			t.Fatalf("%s: expected an error, got %d items", over, len(items))
+		}
+	}
+}
+
+func TestNestedLoop(t *testing.T) {
+	l := NewLinearFlow()
+	r := l.Run(context.Background(),
+		Loop{
+			Over: `["a", "b"]`,
+			Nodes: []Node{
+				Loop{Over: "1..2", Nodes: []Node{echo("inner", `{}`)}},
+				echo("outer", `{"item": "{{item}}", "index": {{index}}}`,
+					asserter.Assertion{Expected: "a", Actual: "item", Operator: asserter.Equal},
+					asserter.Assertion{Expected: 0.0, Actual: "index", Operator: asserter.Equal}),
+			},
+		},
+	)
+
+	if len(r.Steps) != 6 || r.Steps[2].Status != Passed || *r.Steps[2].Index != 0 {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+}
+
+// blocker returns a step that responds only once its context is done
+func blocker(name string) Step {
+	return Step{
+		Name: name,
+		Exec: func(Context) tester.Executor {
+			return func(ctx context.Context) (string, client.Response, error) {
+				<-ctx.Done()
+				return "", client.Response{}, ctx.Err()
+			}
+		},
+	}
+}
+
+func TestLinearTimeout(t *testing.T) {
+	slow := blocker("slow")
+	slow.Timeout = 20 * time.Millisecond
+
+	l := NewLinearFlow()
+	r := l.Run(context.Background(), echo("first", `{}`), slow, echo("after", `{}`))
+	if r.Passed {
+		t.Fatalf("expected timed out flow to fail")
+	}
+	want := []Status{Passed, TimedOut, Skipped}
+	if got := statuses(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but found %v", want, got)
+	}
+	if r.Steps[1].Message != "step timed out after 20ms" {
+		t.Fatalf("unexpected message %q", r.Steps[1].Message)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	l = NewLinearFlow()
+	r = l.Run(ctx, blocker("slow"), echo("after", `{}`))
+	want = []Status{TimedOut, Skipped}
+	if got := statuses(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but found %v", want, got)
+	}
+	if r.Steps[0].Message != "flow deadline exceeded" {
+		t.Fatalf("unexpected message %q", r.Steps[0].Message)
+	}
+}
+
+// timed returns a step whose runner reports the given timings
+func timed(name string, timing client.Timing) Step {
+	return Step{
+		Name: name,
+		Exec: func(Context) tester.Executor {
+			return func(context.Context) (string, client.Response, error) {
+				return name, client.Response{Status: 200, Body: `{}`, Timing: timing}, nil
+			}
+		},
+	}
+}
+
+func TestLinearSLA(t *testing.T) {
+	fast := timed("fast", client.Timing{Total: 120 * time.Millisecond, FirstByte: 40 * time.Millisecond})
+	fast.SLA = "duration < 300ms && ttfb < 100ms"
+	slow := timed("slow", client.Timing{Total: 450 * time.Millisecond})
+	slow.SLA = "duration < 300ms"
+	unmeasured := timed("unmeasured", client.Timing{Total: 10 * time.Millisecond})
+	unmeasured.SLA = "ttfb < 100ms"
+
+	l := NewLinearFlow()
+	r := l.Run(context.Background(), fast, slow)
+	want := []Status{Passed, Failed}
+	if got := statuses(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but found %v", want, got)
+	}
+	if r.Steps[0].Latency != 120*time.Millisecond || r.Steps[0].FirstByte != 40*time.Millisecond {
+		t.Fatalf("unexpected timings %v, %v", r.Steps[0].Latency, r.Steps[0].FirstByte)
+	}
+	if r.Steps[1].Message != `sla "duration < 300ms" not met, took 450ms` {
+		t.Fatalf("unexpected message %q", r.Steps[1].Message)
+	}
+
+	l = NewLinearFlow()
+	r = l.Run(context.Background(), unmeasured)
+	if got := statuses(r); !reflect.DeepEqual(got, []Status{Errored}) {
+		t.Fatalf("expected unmeasured ttfb to error but found %v", got)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	r := Report{Duration: 2 * time.Second, Budget: time.Second, Steps: []StepResult{{Status: Passed}}}
+	if r.Evaluate().Passed {
+		t.Fatal("expected run over budget to fail")
+	}
+	if r.Message != "run took 2s, over its budget of 1s" {
+		t.Fatalf("unexpected message %q", r.Message)
+	}
+	r.Budget = 3 * time.Second
+	if !r.Evaluate().Passed || r.Message != "" {
+		t.Fatalf("expected run within budget to pass, %q", r.Message)
+	}
+}
+
+func TestLinearVerify(t *testing.T) {
+	order := echo("order", `{"total": 3, "items": [1, 2]}`)
+	order.Verify = func(_ context.Context, c Context, status int, body interface{}) (asserter.Result, error) {
+		c.Set("count", len(body.(map[string]interface{})["items"].([]interface{})))
+		return asserter.Result{Passed: true}, nil
+	}
+	check := echo("check", `{}`)
+	check.Verify = func(context.Context, Context, int, interface{}) (asserter.Result, error) {
+		return asserter.Result{Message: "line items do not add up"}, nil
+	}
+
+	l := NewLinearFlow()
+	r := l.Run(context.Background(), order, echo("count", `{{count}}`), check)
+	want := []Status{Passed, Passed, Failed}
+	if got := statuses(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but found %v", want, got)
+	}
+	if v := l.Ctx.Value("steps.count.body"); v != 2.0 {
+		t.Fatalf("expected variable to be rendered, found %v", v)
+	}
+	if r.Steps[2].Message != "line items do not add up" {
+		t.Fatalf("unexpected message %q", r.Steps[2].Message)
+	}
+}
+
+func TestLinearExpressions(t *testing.T) {
+	holds, err := expr.Compile(`status == 200 && body.items.#(open)#|len == 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := echo("list", `{"items": [{"id": 4, "open": true}, {"id": 7, "open": true}]}`,
+		asserter.Assertion{Expected: holds, Operator: asserter.Expression})
+	list.Extract = map[string]string{
+		"first": "body.items.0.id",
+		"total": "body.items.#.id|join(',')",
+	}
+	fails, err := expr.Compile(`body.total == 3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := echo("sum", `{"total": {{first}}, "ids": "{{total}}"}`,
+		asserter.Assertion{Expected: fails, Operator: asserter.Expression})
+
+	l := NewLinearFlow()
+	r := l.Run(context.Background(), list, sum)
+	want := []Status{Passed, Failed}
+	if got := statuses(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but found %v: %+v", want, got, r.Steps)
+	}
+	if v := l.Ctx.Value("steps.sum.body.ids"); v != "4,7" {
+		t.Fatalf("expected extracted variables to be rendered, found %v", v)
+	}
+	if msg := r.Steps[1].Message; msg != `expression "body.total == 3" does not hold` {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestLinearCall(t *testing.T) {
+	engine := func(c Context) Engine {
+		l := newLinear(c)
+		return &l
+	}
+	login := Step{Name: "login", Call: &Call{
+		FlowID:  2,
+		Inputs:  map[string]string{"user": `"admin-" + tenant`},
+		Exports: map[string]string{"token": "steps.auth.body.token"},
+		Phases:  Phases{Main: []Node{echo("auth", `{"token": "t-{{user}}"}`)}},
+		Engine:  engine,
+	}}
+	broken := Step{Name: "broken", Call: &Call{
+		FlowID: 3,
+		Phases: Phases{Main: []Node{echo("auth", `{}`, asserter.Assertion{Expected: 1.0, Actual: "id", Operator: asserter.Equal})}},
+		Engine: engine,
+	}}
+
+	l := NewLinearFlow()
+	l.Ctx.Set("tenant", "acme")
+	r := l.Run(context.Background(), login, echo("me", `{{steps.login.body.token}}`), broken)
+	want := []Status{Passed, Passed, Failed}
+	if got := statuses(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but found %v: %+v", want, got, r.Steps)
+	}
+	if v := l.Ctx.Value("steps.me.body"); v != "t-admin-acme" {
+		t.Fatalf("expected exports to be bound, found %v", v)
+	}
+	if n := len(r.Steps[0].Steps); n != 1 || r.Steps[0].Steps[0].Name != "auth" {
+		t.Fatalf("expected nested steps, found %+v", r.Steps[0].Steps)
+	}
+	if r.Steps[2].Message != "flow 3 did not pass" || r.Steps[2].Steps[0].Status != Failed {
+		t.Fatalf("unexpected result %+v", r.Steps[2])
+	}
+}
@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var numericRange = regexp.MustCompile(`^(-?\d+)\s*\.\.\s*(-?\d+)$`)
+
+// maxRangeItems bounds the number of items of a numeric range
+const maxRangeItems = 10000
+
+// Loop repeats its nodes once for every item of Over, which is one of
+//   - a literal json array, e.g. ["a", "b"]
+//   - an inclusive ascending numeric range of at most 10000 items, e.g. 1..5
+//   - a path into the flow context, e.g. steps.list.body.items.#.id
+//
+// The current item and its position are available to templates and
+// conditions as {{item}} and {{index}}.
+type Loop struct {
+	Over  string
+	Nodes []Node
+}
+
+func (Loop) node() {}
+
+// Items resolves the values to iterate over
+func (lp Loop) Items(c Context) ([]interface{}, error) {
+	over := strings.TrimSpace(lp.Over)
+	if strings.HasPrefix(over, "[") {
+		var items []interface{}
+		if err := json.Unmarshal([]byte(over), &items); err != nil {
+			return nil, fmt.Errorf("invalid loop array %q: %w", over, err)
+		}
+		return items, nil
+	}
+	if m := numericRange.FindStringSubmatch(over); m != nil {
+		from, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid loop range %q: %w", over, err)
+		}
+		to, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid loop range %q: %w", over, err)
+		}
+		if from > to {
+			return nil, fmt.Errorf("invalid loop range %q, %d is greater than %d", over, from, to)
+		}
+		// to-from may wrap around as an int but is exact as an unsigned value
+		if uint64(to-from) >= maxRangeItems {
+			return nil, fmt.Errorf("invalid loop range %q, more than %d items", over, maxRangeItems)
+		}
+		items := make([]interface{}, 0, to-from+1)
+		for i := from; i <= to; i++ {
+			items = append(items, float64(i))
+		}
+		return items, nil
+	}
+	switch v := c.Value(over).(type) {
+	case []interface{}:
+		return v, nil
+	case nil:
+		return nil, fmt.Errorf("loop source %q did not resolve to anything", over)
+	default:
+		return nil, fmt.Errorf("loop source %q resolved to %v which is not an array", over, v)
+	}
+}
@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"context"
+	"time"
+)
+
+// TeardownTimeout bounds the teardown steps of a run, they are not bound by
+// the deadline of the run itself so that they get to clean up after it
+const TeardownTimeout = 30 * time.Second
+
+// Phase names the part of a flow a step belongs to
+type Phase string
+
+const (
+	Setup    = Phase("setup")
+	Main     = Phase("main")
+	Teardown = Phase("teardown")
+)
+
+// Phases splits a flow into setup steps, run first and in order, the main
+// steps and teardown steps. The main steps are skipped when the setup did
+// not pass, teardown steps always run, even after a failure or once the
+// deadline of the run passed.
+type Phases struct {
+	Setup    []Node
+	Main     []Node
+	Teardown []Node
+}
+
+// Run runs the phases within the flow context c, the main steps with the
+// given engine which must run within c as well so that every phase sees
+// the variables and responses of the previous ones
+func (p Phases) Run(ctx context.Context, c Context, e Engine) Report {
+	start := time.Now()
+	var r Report
+
+	setup := newLinear(c)
+	for _, s := range setup.Run(ctx, p.Setup...).Steps {
+		s.Phase = Setup
+		r.add(s)
+	}
+
+	if setup.halted == "" {
+		main := e.Run(ctx, p.Main...)
+		r.Steps = append(r.Steps, main.Steps...)
+		r.CriticalPath = main.CriticalPath
+	} else {
+		for _, s := range steps(p.Main) {
+			r.add(StepResult{ID: s.ID, Name: s.Name, Status: Skipped, Message: "setup did not pass"})
+		}
+	}
+
+	if len(p.Teardown) > 0 {
+		tctx, cancel := context.WithTimeout(detach(ctx), TeardownTimeout)
+		defer cancel()
+		// a failed cleanup must not prevent the next one
+		for _, n := range p.Teardown {
+			teardown := newLinear(c)
+			for _, s := range teardown.Run(tctx, n).Steps {
+				s.Phase = Teardown
+				r.add(s)
+			}
+		}
+	}
+	r.Duration = time.Since(start)
+	return r.Evaluate()
+}
+
+// detached keeps the values of a context but drops its deadline and
+// cancellation
+type detached struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/tester"
+)
+
+func TestPhases(t *testing.T) {
+	panics := Step{Name: "panics", Exec: func(Context) tester.Executor {
+		panic("boom")
+	}}
+	failing := echo("check", `{}`, asserter.Assertion{Expected: 1.0, Actual: "id", Operator: asserter.Equal})
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		Name     string
+		Ctx      context.Context
+		Phases   Phases
+		Statuses []Status
+		Phase    []Phase
+		Passed   bool
+	}{
+		{
+			Name: "main failure",
+			Ctx:  context.Background(),
+			Phases: Phases{
+				Setup:    []Node{echo("tenant", `{"id": 3}`)},
+				Main:     []Node{panics, echo("after", `{}`)},
+				Teardown: []Node{failing, echo("delete", `{{steps.tenant.body.id}}`)},
+			},
+			Statuses: []Status{Passed, Errored, Skipped, Failed, Passed},
+			Phase:    []Phase{Setup, "", "", Teardown, Teardown},
+		},
+		{
+			Name: "setup failure",
+			Ctx:  context.Background(),
+			Phases: Phases{
+				Setup:    []Node{failing, echo("tenant", `{}`)},
+				Main:     []Node{echo("main", `{}`)},
+				Teardown: []Node{echo("delete", `{}`)},
+			},
+			Statuses: []Status{Failed, Skipped, Skipped, Passed},
+			Phase:    []Phase{Setup, Setup, "", Teardown},
+		},
+		{
+			Name: "deadline",
+			Ctx:  cancelled,
+			Phases: Phases{
+				Main:     []Node{echo("main", `{}`)},
+				Teardown: []Node{echo("delete", `{}`)},
+			},
+			Statuses: []Status{Skipped, Passed},
+			Phase:    []Phase{"", Teardown},
+			Passed:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		c := NewInMemoryContext()
+		l := newLinear(c)
+		r := tc.Phases.Run(tc.Ctx, c, &l)
+		if got := statuses(r); !reflect.DeepEqual(got, tc.Statuses) {
+			t.Fatalf("%s: expected %v but found %v: %+v", tc.Name, tc.Statuses, got, r.Steps)
+		}
+		var phases []Phase
+		for _, s := range r.Steps {
+			phases = append(phases, s.Phase)
+		}
+		if !reflect.DeepEqual(phases, tc.Phase) {
+			t.Fatalf("%s: expected phases %v but found %v", tc.Name, tc.Phase, phases)
+		}
+		if r.Passed != tc.Passed {
+			t.Fatalf("%s: expected passed to be %v", tc.Name, tc.Passed)
+		}
+	}
+}
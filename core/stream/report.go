@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
+)
+
+// Status is the outcome of a single step
+type Status string
+
+const (
+	Passed  = Status("PASSED")
+	Failed  = Status("FAILED")
+	Errored = Status("ERROR")
+	Skipped = Status("SKIPPED")
+	// TimedOut is reported for a step cut short by its own timeout or by the
+	// deadline of the flow or run
+	TimedOut = Status("TIMEOUT")
+)
+
+// StepResult records what happened to a step during a run, Started and
+// Duration are left empty for steps that never ran. Latency and FirstByte
+// are the timings of the last attempt. Request describes the call made by
+// the last attempt, Code and Response are the status code and body of its
+// response, in the format the request was made in, and Violations the
+// schema violations that failed it. Steps are the steps of a sub-flow called by the
+// step, as reported by its last attempt. Phase is left empty for the main
+// steps of a flow.
+type StepResult struct {
+	ID         int                  `json:"id"`
+	Name       string               `json:"name"`
+	Index      *int                 `json:"index,omitempty"`
+	Phase      Phase                `json:"phase,omitempty"`
+	Status     Status               `json:"status"`
+	Message    string               `json:"message,omitempty"`
+	Started    time.Time            `json:"started"`
+	Duration   time.Duration        `json:"duration"`
+	Latency    time.Duration        `json:"latency,omitempty"`
+	FirstByte  time.Duration        `json:"ttfb,omitempty"`
+	Request    *client.Request      `json:"request,omitempty"`
+	Code       int                  `json:"code,omitempty"`
+	Response   string               `json:"response,omitempty"`
+	Violations []asserter.Violation `json:"violations,omitempty"`
+	Attempts   []Attempt            `json:"attempts,omitempty"`
+	Steps      []StepResult         `json:"steps,omitempty"`
+}
+
+// Report is the outcome of an engine run, steps are listed in the order
+// they were declared. CriticalPath names the chain of steps that bounded
+// the duration of a DAG run. A run taking longer than its Budget fails
+// even though all of its steps passed.
+type Report struct {
+	Passed       bool          `json:"passed"`
+	Message      string        `json:"message,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Budget       time.Duration `json:"budget,omitempty"`
+	CriticalPath []string      `json:"critical_path,omitempty"`
+	Steps        []StepResult  `json:"steps"`
+}
+
+func (r *Report) add(s StepResult) {
+	r.Steps = append(r.Steps, s)
+}
+
+// Evaluate marks the report as passed when no step failed, errored or
+// timed out and the run kept within its budget
+func (r *Report) Evaluate() Report {
+	r.Passed, r.Message = true, ""
+	for _, s := range r.Steps {
+		if s.Status == Failed || s.Status == Errored || s.Status == TimedOut {
+			r.Passed = false
+		}
+	}
+	if r.Budget > 0 && r.Duration > r.Budget {
+		r.Passed = false
+		r.Message = fmt.Sprintf("run took %s, over its budget of %s", r.Duration, r.Budget)
+	}
+	return *r
+}
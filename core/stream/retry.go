@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
+)
+
+// Backoff decides how the delay between attempts grows
+type Backoff string
+
+const (
+	Fixed       = Backoff("fixed")
+	Exponential = Backoff("exponential")
+)
+
+// Retry is the retry policy of a step. A step is attempted again, up to
+// MaxAttempts times in total, when it errored and OnError is set or when the
+// response status is one of OnStatus. With PollFor set the step is also
+// repeated while its assertions fail, until they pass or PollFor elapses.
+type Retry struct {
+	MaxAttempts int
+	Backoff     Backoff
+	Delay       time.Duration
+	MaxDelay    time.Duration
+	OnStatus    []int
+	OnError     bool
+	PollFor     time.Duration
+}
+
+// Attempt is a single try of a step. Latency is the duration of the call as
+// measured by the runner and FirstByte the time until the first byte of the
+// response, when the runner measures it.
+type Attempt struct {
+	Started    time.Time            `json:"started"`
+	Duration   time.Duration        `json:"duration"`
+	Latency    time.Duration        `json:"latency,omitempty"`
+	FirstByte  time.Duration        `json:"ttfb,omitempty"`
+	Status     Status               `json:"status"`
+	Code       int                  `json:"code,omitempty"`
+	Message    string               `json:"message,omitempty"`
+	Violations []asserter.Violation `json:"violations,omitempty"`
+	steps      []StepResult
+	request    *client.Request
+}
+
+// wait returns the delay before the given attempt, starting from 2
+func (r Retry) wait(attempt int) time.Duration {
+	d := r.Delay
+	if r.Backoff == Exponential {
+		for i := 2; i < attempt; i++ {
+			d *= 2
+			if r.MaxDelay > 0 && d >= r.MaxDelay {
+				break
+			}
+		}
+	}
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		d = r.MaxDelay
+	}
+	return d
+}
+
+// sleep waits for d, it returns false when ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// again reports whether another attempt should follow the given one
+func (r Retry) again(a Attempt, attempt int, deadline time.Time) bool {
+	if a.Status == Passed {
+		return false
+	}
+	if r.MaxAttempts > 0 && attempt >= r.MaxAttempts {
+		return false
+	}
+	if r.PollFor > 0 {
+		if time.Now().Add(r.wait(attempt + 1)).After(deadline) {
+			return false
+		}
+		if a.Status == Failed {
+			return true
+		}
+	} else if r.MaxAttempts <= 1 {
+		return false
+	}
+	if (a.Status == Errored || a.Status == TimedOut) && a.Code == 0 {
+		return r.OnError
+	}
+	for _, c := range r.OnStatus {
+		if c == a.Code {
+			return true
+		}
+	}
+	return false
+}
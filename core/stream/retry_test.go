@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/client"
+	"github.com/thejasn/tester/core/tester"
+)
+
+// flaky returns a step that answers with the given responses in turn, a nil
+// response stands for a transport error
+func flaky(responses ...*client.Response) Step {
+	n := 0
+	return Step{
+		Name: "flaky",
+		Exec: func(Context) tester.Executor {
+			return func(context.Context) (string, client.Response, error) {
+				r := responses[n]
+				if n < len(responses)-1 {
+					n++
+				}
+				if r == nil {
+					return "", client.Response{}, errors.New("connection refused")
+				}
+				return "flaky", *r, nil
+			}
+		},
+		Assertions: []asserter.Assertion{{Expected: "done", Actual: "state", Operator: asserter.Equal}},
+	}
+}
+
+func TestRetry(t *testing.T) {
+	s := flaky(nil, &client.Response{Status: 503, Body: `{}`}, &client.Response{Status: 200, Body: `{"state": "done"}`})
+	s.Retry = Retry{MaxAttempts: 3, OnError: true, OnStatus: []int{503}, Delay: time.Millisecond}
+
+	l := NewLinearFlow()
+	r := l.Run(context.Background(), s)
+	if !r.Passed || len(r.Steps[0].Attempts) != 3 {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+	expected := []Status{Errored, Failed, Passed}
+	for i, a := range r.Steps[0].Attempts {
+		if a.Status != expected[i] {
+			t.Fatalf("bad attempt %d: %#v", i, a)
+		}
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	s := flaky(&client.Response{Status: 503, Body: `{}`})
+	s.Retry = Retry{MaxAttempts: 2, OnStatus: []int{503}}
+
+	l := NewLinearFlow()
+	r := l.Run(context.Background(), s)
+	if r.Passed || len(r.Steps[0].Attempts) != 2 {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	pending := &client.Response{Status: 200, Body: `{"state": "pending"}`}
+	s := flaky(pending, pending, &client.Response{Status: 200, Body: `{"state": "done"}`})
+	s.Retry = Retry{PollFor: time.Second, Delay: time.Millisecond}
+
+	l := NewLinearFlow()
+	r := l.Run(context.Background(), s)
+	if !r.Passed || len(r.Steps[0].Attempts) != 3 {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+
+	s = flaky(pending)
+	s.Retry = Retry{PollFor: 20 * time.Millisecond, Delay: 5 * time.Millisecond}
+	l = NewLinearFlow()
+	r = l.Run(context.Background(), s)
+	if r.Passed || len(r.Steps[0].Attempts) < 2 {
+		t.Fatalf("bad: %#v", r.Steps)
+	}
+}
+
+func TestWait(t *testing.T) {
+	r := Retry{Backoff: Exponential, Delay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	expected := []time.Duration{100, 200, 300, 300}
+	for i, e := range expected {
+		if d := r.wait(i + 2); d != e*time.Millisecond {
+			t.Fatalf("attempt %d: bad: %s", i+2, d)
+		}
+	}
+}
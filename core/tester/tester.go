@@ -8,42 +8,44 @@ import (
 	"github.com/thejasn/tester/core/client"
 )
 
-type Executor func() (string, string, error)
+// Executor builds and invokes a runner, ctx bounds the whole call including
+// dialing and resolving credentials
+type Executor func(ctx context.Context) (string, client.Response, error)
 
-func GrpcExecutor(ctx context.Context, cc client.Runner, opts ...client.RunnerOpts) Executor {
-	return func() (string, string, error) {
+func GrpcExecutor(cc client.Runner, opts ...client.RunnerOpts) Executor {
+	return func(ctx context.Context) (string, client.Response, error) {
 		for _, opt := range opts {
 			opt(cc)
 		}
 		err := cc.Build(ctx)
 		if err != nil {
-			return "", "", err
+			return "", client.Response{}, err
 		}
-		jsonStr, err := cc.Invoke()
+		resp, err := cc.Invoke(ctx)
 		if err != nil {
-			return "", "", err
+			return "", resp, err
 		}
-		log.GetLogger(ctx).Debugf("Response: %+v", jsonStr)
+		log.GetLogger(ctx).Debugf("Response: %+v", resp)
 		cc.Clear()
-		return cc.GetIdentifier(), jsonStr, nil
+		return cc.GetIdentifier(), resp, nil
 	}
 }
 
-func RestExecutor(ctx context.Context, cc client.Runner, opts ...client.RunnerOpts) Executor {
-	return func() (string, string, error) {
+func RestExecutor(cc client.Runner, opts ...client.RunnerOpts) Executor {
+	return func(ctx context.Context) (string, client.Response, error) {
 		for _, opt := range opts {
 			opt(cc)
 		}
 		err := cc.Build(ctx)
 		if err != nil {
-			return "", "", err
+			return "", client.Response{}, err
 		}
-		jsonStr, err := cc.Invoke()
+		resp, err := cc.Invoke(ctx)
 		if err != nil {
-			return "", "", err
+			return "", resp, err
 		}
-		log.GetLogger(ctx).Debugf("Response: %+v", jsonStr)
+		log.GetLogger(ctx).Debugf("Response: %+v", resp)
 		cc.Clear()
-		return cc.GetIdentifier(), jsonStr, nil
+		return cc.GetIdentifier(), resp, nil
 	}
 }
@@ -0,0 +1,38 @@
+package tester
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thejasn/tester/core/client"
+)
+
+type failingRunner struct{}
+
+func (failingRunner) GetIdentifier() string       { return "failing" }
+func (failingRunner) Build(context.Context) error { return nil }
+func (failingRunner) Clear()                      {}
+func (failingRunner) Invoke(context.Context) (client.Response, error) {
+	return client.Response{Request: &client.Request{URL: "http://localhost:1"}}, errors.New("connection refused")
+}
+
+func TestExecutorKeepsRequest(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Executor func(client.Runner, ...client.RunnerOpts) Executor
+	}{
+		{"grpc", GrpcExecutor},
+		{"rest", RestExecutor},
+	}
+
+	for _, tc := range cases {
+		_, resp, err := tc.Executor(failingRunner{})(context.Background())
+		if err == nil {
+			t.Fatalf("%s: expected an error", tc.Name)
+		}
+		if resp.Request == nil || resp.Request.URL != "http://localhost:1" {
+			t.Fatalf("%s: request lost: %#v", tc.Name, resp)
+		}
+	}
+}
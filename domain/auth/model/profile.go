@@ -0,0 +1,99 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/core/auth"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `auth_profile` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `name` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `type` enum('basic','bearer','apikey','oauth2_client_credentials','oauth2_password') COLLATE utf8mb4_unicode_ci NOT NULL,
+  `username` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `password` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `token` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `key_name` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `key_value` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `key_in` enum('header','query') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'header',
+  `token_url` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `client_id` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `client_secret` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `scopes` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `auth_profile_UK` (`name`) USING HASH
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 7}
+*/
+
+// Profile struct is a row record of the auth_profile table in the tester database
+type Profile struct {
+	ID           int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`         //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	Name         string      `gorm:"column:name;type:TEXT;size:65535;" json:"name"`                   //[ 1] name                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
+	Type         string      `gorm:"column:type;type:CHAR;size:25;" json:"type"`                      //[ 2] type                                           char(25)             null: false  primary: false  auto: false  col: char            len: 25      default: []
+	Username     null.String `gorm:"column:username;type:TEXT;size:65535;" json:"username"`           //[ 3] username                                       text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Password     null.String `gorm:"column:password;type:TEXT;size:65535;" json:"password"`           //[ 4] password                                       text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Token        null.String `gorm:"column:token;type:TEXT;size:65535;" json:"token"`                 //[ 5] token                                          text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	KeyName      null.String `gorm:"column:key_name;type:TEXT;size:65535;" json:"key_name"`           //[ 6] key_name                                       text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	KeyValue     null.String `gorm:"column:key_value;type:TEXT;size:65535;" json:"key_value"`         //[ 7] key_value                                      text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	KeyIn        string      `gorm:"column:key_in;type:CHAR;size:6;default:'header';" json:"key_in"`  //[ 8] key_in                                         char(6)              null: false  primary: false  auto: false  col: char            len: 6       default: ['header']
+	TokenURL     null.String `gorm:"column:token_url;type:TEXT;size:65535;" json:"token_url"`         //[ 9] token_url                                      text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	ClientID     null.String `gorm:"column:client_id;type:TEXT;size:65535;" json:"client_id"`         //[10] client_id                                      text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	ClientSecret null.String `gorm:"column:client_secret;type:TEXT;size:65535;" json:"client_secret"` //[11] client_secret                                  text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Scopes       null.String `gorm:"column:scopes;type:TEXT;size:65535;" json:"scopes"`               //[12] scopes                                         text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	CreatedAt    time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`              //[13] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt    time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`              //[14] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+}
+
+// TableName sets the insert table name for this struct type
+func (p *Profile) TableName() string {
+	return "auth_profile"
+}
+
+// Auth converts the record into the core representation used while
+// executing a testcase. Scopes are stored space separated.
+func (p Profile) Auth() auth.Profile {
+	return auth.Profile{
+		Type:         auth.Type(p.Type),
+		Username:     p.Username.String,
+		Password:     p.Password.String,
+		Token:        p.Token.String,
+		KeyName:      p.KeyName.String,
+		KeyValue:     p.KeyValue.String,
+		KeyIn:        auth.Location(p.KeyIn),
+		TokenURL:     p.TokenURL.String,
+		ClientID:     p.ClientID.String,
+		ClientSecret: p.ClientSecret.String,
+		Scopes:       strings.Fields(p.Scopes.String),
+	}
+}
+
+// Redacted returns the profile without its credentials, which are written
+// through the API but not read back from it. Credentials left out of an
+// update keep their stored values.
+func (p Profile) Redacted() Profile {
+	p.Password = null.String{}
+	p.Token = null.String{}
+	p.KeyValue = null.String{}
+	p.ClientSecret = null.String{}
+	return p
+}
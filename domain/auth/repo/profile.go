@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/smallnest/gen/dbmeta"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/auth/model"
+	"gorm.io/gorm"
+)
+
+type Profile interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Profile, int64, error)
+	Get(context.Context, int) (model.Profile, error)
+	Add(context.Context, *model.Profile) (*model.Profile, int64, error)
+	Update(context.Context, int, *model.Profile) (*model.Profile, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewProfileRepo(db *gorm.DB) Profile {
+	return profile{
+		DB: db,
+	}
+}
+
+type profile struct {
+	DB *gorm.DB
+}
+
+// GetAll is a function to get a slice of record(s) from auth_profile table in the tester database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func (p profile) GetAll(ctx context.Context, page, pagesize int64, order string) (profiles []*model.Profile, totalRows int64, err error) {
+
+	profiles = []*model.Profile{}
+
+	profilesOrm := p.DB.Model(&model.Profile{})
+	profilesOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		profilesOrm = profilesOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		profilesOrm = profilesOrm.Limit(int(pagesize))
+	}
+
+	if order != "" {
+		profilesOrm = profilesOrm.Order(order)
+	}
+
+	if err = profilesOrm.Find(&profiles).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	return profiles, totalRows, nil
+}
+
+// GetProfile is a function to get a single record to auth_profile table in the tester database
+// error - ErrNotFound, db Find error
+func (p profile) Get(ctx context.Context, id int) (record model.Profile, err error) {
+	if err = p.DB.First(&record, id).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	return record, nil
+}
+
+// AddProfile is a function to add a single record to auth_profile table in the tester database
+// error - ErrInsertFailed, db save call failed
+func (p profile) Add(ctx context.Context, profile *model.Profile) (result *model.Profile, RowsAffected int64, err error) {
+	db := p.DB.Save(profile)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return profile, db.RowsAffected, nil
+}
+
+// UpdateProfile is a function to update a single record from auth_profile table in the tester database
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func (p profile) Update(ctx context.Context, id int, updated *model.Profile) (result *model.Profile, RowsAffected int64, err error) {
+
+	result = &model.Profile{}
+	db := p.DB.First(result, id)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrNotFound
+	}
+
+	if err = dbmeta.Copy(result, updated); err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	db = db.Save(result)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	return result, db.RowsAffected, nil
+}
+
+// DeleteProfile is a function to delete a single record from auth_profile table in the tester database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func (p profile) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+
+	profile := &model.Profile{}
+	db := p.DB.First(profile, id)
+	if db.Error != nil {
+		return -1, cerrors.ErrNotFound
+	}
+
+	db = db.Delete(profile)
+	if err = db.Error; err != nil {
+		return -1, cerrors.ErrDeleteFailed
+	}
+
+	return db.RowsAffected, nil
+}
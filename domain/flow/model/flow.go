@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/guregu/null"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
 )
 
 var (
@@ -21,10 +22,18 @@ DB Table Details
 CREATE TABLE `flow` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `name` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `auth_profile_id` int(11) DEFAULT NULL,
+  `engine` enum('linear','dag') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'linear',
+  `parallelism` int(11) NOT NULL DEFAULT 4,
+  `timeout` varchar(32) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `budget` varchar(32) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `exports` blob DEFAULT NULL,
+  `tags` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
   `created_at` datetime NOT NULL DEFAULT current_timestamp(),
   `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
   PRIMARY KEY (`id`),
-  UNIQUE KEY `flow_UK` (`name`) USING HASH
+  UNIQUE KEY `flow_UK` (`name`) USING HASH,
+  CONSTRAINT `flow_auth_profile_FK` FOREIGN KEY (`auth_profile_id`) REFERENCES `auth_profile` (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
 
 JSON Sample
@@ -34,10 +43,17 @@ JSON Sample
 
 // Flow struct is a row record of the flow table in the tester database
 type Flow struct {
-	ID        int       `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"` //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
-	Name      string    `gorm:"column:name;type:TEXT;size:65535;" json:"name"`           //[ 1] name                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
-	CreatedAt time.Time `gorm:"column:created_at;type:DATETIME;" json:"created_at"`      //[ 2] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
-	UpdatedAt time.Time `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`      //[ 3] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	ID            int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`        //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	Name          string      `gorm:"column:name;type:TEXT;size:65535;" json:"name"`                  //[ 1] name                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
+	CreatedAt     time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`             //[ 2] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt     time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`             //[ 3] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	AuthProfileID null.Int    `gorm:"column:auth_profile_id;type:INT;" json:"auth_profile_id"`        //[ 4] auth_profile_id                                int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Engine        string      `gorm:"column:engine;type:CHAR;size:6;default:'linear';" json:"engine"` //[ 5] engine                                         char(6)              null: false  primary: false  auto: false  col: char            len: 6       default: ['linear']
+	Parallelism   int         `gorm:"column:parallelism;type:INT;default:4;" json:"parallelism"`      //[ 6] parallelism                                    int                  null: false  primary: false  auto: false  col: int             len: -1      default: [4]
+	Timeout       null.String `gorm:"column:timeout;type:VARCHAR;size:32;" json:"timeout"`            //[ 7] timeout                                        varchar(32)          null: true   primary: false  auto: false  col: varchar         len: 32      default: [NULL]
+	Budget        null.String `gorm:"column:budget;type:VARCHAR;size:32;" json:"budget"`              //[ 8] budget                                         varchar(32)          null: true   primary: false  auto: false  col: varchar         len: 32      default: [NULL]
+	Exports       tmodel.JSON `gorm:"column:exports;" json:"exports"`                                 //[ 9] exports                                        blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	Tags          null.String `gorm:"column:tags;type:TEXT;size:65535;" json:"tags"`                  //[10] tags                                           text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
 
 }
 
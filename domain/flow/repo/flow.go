@@ -6,11 +6,26 @@ import (
 	"github.com/smallnest/gen/dbmeta"
 	"github.com/thejasn/tester/cerrors"
 	"github.com/thejasn/tester/domain/flow/model"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+	pdb "github.com/thejasn/tester/pkg/db"
 	"gorm.io/gorm"
 )
 
+// Filter narrows the flows listed, empty fields match anything. Name
+// matches a substring of the name, Tag one of the comma separated tags, API
+// and Host flows with at least one such testcase and Status the outcome of
+// the latest run of the flow, either "passed" or "failed".
+type Filter struct {
+	Name   string
+	Tag    string
+	API    string
+	Host   string
+	Status string
+}
+
 type Flow interface {
-	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Flow, int64, error)
+	GetAll(ctx context.Context, page, pagesize int64, order string, filter Filter) ([]*model.Flow, int64, error)
+	Find(context.Context, Filter) ([]*model.Flow, error)
 	Get(context.Context, int) (model.Flow, error)
 	Add(context.Context, *model.Flow) (*model.Flow, int64, error)
 	Update(context.Context, int, *model.Flow) (*model.Flow, int64, error)
@@ -31,12 +46,13 @@ type flow struct {
 // params - page     - page requested (defaults to 0)
 // params - pagesize - number of records in a page  (defaults to 20)
 // params - order    - db sort order column
+// params - filter   - criteria the records must match
 // error - ErrNotFound, db Find error
-func (f flow) GetAll(ctx context.Context, page, pagesize int64, order string) (flows []*model.Flow, totalRows int64, err error) {
+func (f flow) GetAll(ctx context.Context, page, pagesize int64, order string, filter Filter) (flows []*model.Flow, totalRows int64, err error) {
 
 	flows = []*model.Flow{}
 
-	flowsOrm := f.DB.Model(&model.Flow{})
+	flowsOrm := f.filtered(filter)
 	flowsOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -58,6 +74,17 @@ func (f flow) GetAll(ctx context.Context, page, pagesize int64, order string) (f
 	return flows, totalRows, nil
 }
 
+// Find is a function to get every record of flow table in the tester database matching the filter, in id order
+// error - ErrNotFound, db Find error
+func (f flow) Find(ctx context.Context, filter Filter) (flows []*model.Flow, err error) {
+	flows = []*model.Flow{}
+	if err = f.filtered(filter).Order("id").Find(&flows).Error; err != nil {
+		return nil, cerrors.ErrNotFound
+	}
+
+	return flows, nil
+}
+
 // GetFlow is a function to get a single record to flow table in the tester database
 // error - ErrNotFound, db Find error
 func (f flow) Get(ctx context.Context, id int) (record model.Flow, err error) {
@@ -121,3 +148,28 @@ func (f flow) Delete(ctx context.Context, id int) (rowsAffected int64, err error
 
 	return db.RowsAffected, nil
 }
+
+// filtered scopes a query on the flow table to the records matching filter
+func (f flow) filtered(filter Filter) *gorm.DB {
+	escape := pdb.LikeEscape(f.DB.Dialector.Name())
+	db := f.DB.Model(&model.Flow{})
+	if filter.Name != "" {
+		db = db.Where("LOWER(name) LIKE LOWER(?) "+escape, "%"+pdb.EscapeLike(filter.Name)+"%")
+	}
+	if filter.Tag != "" {
+		tag := pdb.EscapeLike(filter.Tag)
+		db = db.Where("tags = ? OR tags LIKE ? "+escape+" OR tags LIKE ? "+escape+" OR tags LIKE ? "+escape,
+			filter.Tag, tag+",%", "%,"+tag, "%,"+tag+",%")
+	}
+	if filter.API != "" {
+		db = db.Where("id IN (?)", f.DB.Model(&tmodel.Testcase{}).Select("flow_id").Where("api = ?", filter.API))
+	}
+	if filter.Host != "" {
+		db = db.Where("id IN (?)", f.DB.Model(&tmodel.Testcase{}).Select("flow_id").Where("host = ?", filter.Host))
+	}
+	if filter.Status != "" {
+		db = db.Where("id IN (SELECT r.flow_id FROM run r WHERE r.passed = ? AND r.id = (SELECT MAX(l.id) FROM run l WHERE l.flow_id = r.flow_id))",
+			filter.Status == "passed")
+	}
+	return db
+}
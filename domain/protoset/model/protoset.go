@@ -0,0 +1,51 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `protoset` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `name` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `description` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `descriptor` mediumblob NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `protoset_UK` (`name`) USING HASH
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 2}
+*/
+
+// Protoset struct is a row record of the protoset table in the tester database.
+// Descriptor is a serialized FileDescriptorSet, base64 encoded in json.
+type Protoset struct {
+	ID          int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`     //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	Name        string      `gorm:"column:name;type:TEXT;size:65535;" json:"name"`               //[ 1] name                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
+	Description null.String `gorm:"column:description;type:TEXT;size:65535;" json:"description"` //[ 2] description                                    text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Descriptor  []byte      `gorm:"column:descriptor;type:MEDIUMBLOB;" json:"descriptor"`        //[ 3] descriptor                                     mediumblob           null: false  primary: false  auto: false  col: mediumblob      len: -1      default: []
+	CreatedAt   time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`          //[ 4] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt   time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`          //[ 5] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+}
+
+// TableName sets the insert table name for this struct type
+func (p *Protoset) TableName() string {
+	return "protoset"
+}
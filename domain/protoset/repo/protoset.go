@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/smallnest/gen/dbmeta"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/protoset/model"
+	"gorm.io/gorm"
+)
+
+type Protoset interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Protoset, int64, error)
+	Get(context.Context, int) (model.Protoset, error)
+	Add(context.Context, *model.Protoset) (*model.Protoset, int64, error)
+	Update(context.Context, int, *model.Protoset) (*model.Protoset, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewProtosetRepo(db *gorm.DB) Protoset {
+	return protoset{
+		DB: db,
+	}
+}
+
+type protoset struct {
+	DB *gorm.DB
+}
+
+// GetAll is a function to get a slice of record(s) from protoset table in the tester database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func (p protoset) GetAll(ctx context.Context, page, pagesize int64, order string) (protosets []*model.Protoset, totalRows int64, err error) {
+
+	protosets = []*model.Protoset{}
+
+	protosetsOrm := p.DB.Model(&model.Protoset{})
+	protosetsOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		protosetsOrm = protosetsOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		protosetsOrm = protosetsOrm.Limit(int(pagesize))
+	}
+
+	if order != "" {
+		protosetsOrm = protosetsOrm.Order(order)
+	}
+
+	if err = protosetsOrm.Find(&protosets).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	return protosets, totalRows, nil
+}
+
+// GetProtoset is a function to get a single record to protoset table in the tester database
+// error - ErrNotFound, db Find error
+func (p protoset) Get(ctx context.Context, id int) (record model.Protoset, err error) {
+	if err = p.DB.First(&record, id).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	return record, nil
+}
+
+// AddProtoset is a function to add a single record to protoset table in the tester database
+// error - ErrInsertFailed, db save call failed
+func (p protoset) Add(ctx context.Context, protoset *model.Protoset) (result *model.Protoset, RowsAffected int64, err error) {
+	db := p.DB.Save(protoset)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return protoset, db.RowsAffected, nil
+}
+
+// UpdateProtoset is a function to update a single record from protoset table in the tester database
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func (p protoset) Update(ctx context.Context, id int, updated *model.Protoset) (result *model.Protoset, RowsAffected int64, err error) {
+
+	result = &model.Protoset{}
+	db := p.DB.First(result, id)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrNotFound
+	}
+
+	if err = dbmeta.Copy(result, updated); err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	db = db.Save(result)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	return result, db.RowsAffected, nil
+}
+
+// DeleteProtoset is a function to delete a single record from protoset table in the tester database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func (p protoset) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+
+	protoset := &model.Protoset{}
+	db := p.DB.First(protoset, id)
+	if db.Error != nil {
+		return -1, cerrors.ErrNotFound
+	}
+
+	db = db.Delete(protoset)
+	if err = db.Error; err != nil {
+		return -1, cerrors.ErrDeleteFailed
+	}
+
+	return db.RowsAffected, nil
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `run` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `flow_id` int(11) DEFAULT NULL,
+  `testcase_id` int(11) DEFAULT NULL,
+  `passed` tinyint(1) NOT NULL DEFAULT 0,
+  `message` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `duration_ms` double NOT NULL DEFAULT 0,
+  `started_at` datetime NOT NULL,
+  `report` mediumblob NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `source` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'api',
+  PRIMARY KEY (`id`),
+  KEY `run_flow_IDX` (`flow_id`,`started_at`),
+  CONSTRAINT `run_flow_FK` FOREIGN KEY (`flow_id`) REFERENCES `flow` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `run_testcase_FK` FOREIGN KEY (`testcase_id`) REFERENCES `testcase` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 12}
+*/
+
+// Run struct is a row record of the run table in the tester database.
+// Either FlowID or TestcaseID is set, depending on what was executed, and
+// Report holds the report of the run as returned by the execute endpoints.
+// Source tells what triggered the run, api or schedule.
+type Run struct {
+	ID         int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`       //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	FlowID     null.Int    `gorm:"column:flow_id;type:INT;" json:"flow_id"`                       //[ 1] flow_id                                        int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	TestcaseID null.Int    `gorm:"column:testcase_id;type:INT;" json:"testcase_id"`               //[ 2] testcase_id                                    int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Passed     bool        `gorm:"column:passed;type:TINYINT;default:0;" json:"passed"`           //[ 3] passed                                         tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	Message    null.String `gorm:"column:message;type:TEXT;size:65535;" json:"message"`           //[ 4] message                                        text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	DurationMs float64     `gorm:"column:duration_ms;type:DOUBLE;default:0;" json:"duration_ms"`  //[ 5] duration_ms                                    double               null: false  primary: false  auto: false  col: double          len: -1      default: [0]
+	StartedAt  time.Time   `gorm:"column:started_at;type:DATETIME;" json:"started_at"`            //[ 6] started_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: []
+	Report     tmodel.JSON `gorm:"column:report;" json:"report"`                                  //[ 7] report                                         mediumblob           null: false  primary: false  auto: false  col: mediumblob      len: -1      default: []
+	CreatedAt  time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`            //[ 8] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	Source     string      `gorm:"column:source;type:VARCHAR;size:16;default:api;" json:"source"` //[ 9] source                                         varchar(16)          null: false  primary: false  auto: false  col: varchar         len: 16      default: [api]
+}
+
+// TableName sets the insert table name for this struct type
+func (r *Run) TableName() string {
+	return "run"
+}
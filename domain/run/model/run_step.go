@@ -0,0 +1,63 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `run_step` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `run_id` int(11) NOT NULL,
+  `testcase_id` int(11) DEFAULT NULL,
+  `step_id` int(11) NOT NULL,
+  `name` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `iteration` int(11) DEFAULT NULL,
+  `status` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `duration_ms` double NOT NULL DEFAULT 0,
+  `latency_ms` double DEFAULT NULL,
+  `ttfb_ms` double DEFAULT NULL,
+  `started_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `run_step_testcase_IDX` (`testcase_id`,`id`),
+  CONSTRAINT `run_step_run_FK` FOREIGN KEY (`run_id`) REFERENCES `run` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `run_step_testcase_FK` FOREIGN KEY (`testcase_id`) REFERENCES `testcase` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 31}
+*/
+
+// RunStep struct is a row record of the run_step table in the tester database,
+// it keeps the outcome and timings of a step of a run for trending
+type RunStep struct {
+	ID         int        `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`      //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	RunID      int        `gorm:"column:run_id;type:INT;" json:"run_id"`                        //[ 1] run_id                                         int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
+	TestcaseID null.Int   `gorm:"column:testcase_id;type:INT;" json:"testcase_id"`              //[ 2] testcase_id                                    int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	StepID     int        `gorm:"column:step_id;type:INT;" json:"step_id"`                      //[ 3] step_id                                        int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
+	Name       string     `gorm:"column:name;type:TEXT;size:65535;" json:"name"`                //[ 4] name                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
+	Iteration  null.Int   `gorm:"column:iteration;type:INT;" json:"iteration"`                  //[ 5] iteration                                      int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Status     string     `gorm:"column:status;type:VARCHAR;size:16;" json:"status"`            //[ 6] status                                         varchar(16)          null: false  primary: false  auto: false  col: varchar         len: 16      default: []
+	DurationMs float64    `gorm:"column:duration_ms;type:DOUBLE;default:0;" json:"duration_ms"` //[ 7] duration_ms                                    double               null: false  primary: false  auto: false  col: double          len: -1      default: [0]
+	LatencyMs  null.Float `gorm:"column:latency_ms;type:DOUBLE;" json:"latency_ms"`             //[ 8] latency_ms                                     double               null: true   primary: false  auto: false  col: double          len: -1      default: [NULL]
+	TTFBMs     null.Float `gorm:"column:ttfb_ms;type:DOUBLE;" json:"ttfb_ms"`                   //[ 9] ttfb_ms                                        double               null: true   primary: false  auto: false  col: double          len: -1      default: [NULL]
+	StartedAt  null.Time  `gorm:"column:started_at;type:DATETIME;" json:"started_at"`           //[10] started_at                                     datetime             null: true   primary: false  auto: false  col: datetime        len: -1      default: [NULL]
+}
+
+// TableName sets the insert table name for this struct type
+func (r *RunStep) TableName() string {
+	return "run_step"
+}
@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/run/model"
+	"gorm.io/gorm"
+)
+
+type Run interface {
+	GetAllWhere(ctx context.Context, page, pagesize int64, order string, conditions map[string]interface{}) ([]*model.Run, int64, error)
+	Get(context.Context, int) (model.Run, error)
+	Add(context.Context, *model.Run, []*model.RunStep) (*model.Run, int64, error)
+	Timings(ctx context.Context, testcaseID int, limit int) ([]*model.RunStep, error)
+	Previous(context.Context, model.Run) (model.Run, error)
+}
+
+func NewRunRepo(db *gorm.DB) Run {
+	return run{
+		DB: db,
+	}
+}
+
+type run struct {
+	DB *gorm.DB
+}
+
+// GetAllWhere is a function to get a slice of record(s) from run table in the tester database
+// params - page       - page requested (defaults to 0)
+// params - pagesize   - number of records in a page  (defaults to 20)
+// params - order      - db sort order column, latest runs first when empty
+// params - conditions - column values the records must match
+// error - ErrNotFound, db Find error
+func (r run) GetAllWhere(ctx context.Context, page, pagesize int64, order string, conditions map[string]interface{}) (runs []*model.Run, totalRows int64, err error) {
+
+	runs = []*model.Run{}
+
+	runsOrm := r.DB.Model(&model.Run{}).Where(conditions)
+	runsOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		runsOrm = runsOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		runsOrm = runsOrm.Limit(int(pagesize))
+	}
+
+	if order == "" {
+		order = "id desc"
+	}
+	runsOrm = runsOrm.Order(order)
+
+	if err = runsOrm.Find(&runs).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	return runs, totalRows, nil
+}
+
+// Get is a function to get a single record to run table in the tester database
+// error - ErrNotFound, db Find error
+func (r run) Get(ctx context.Context, id int) (record model.Run, err error) {
+	if err = r.DB.First(&record, id).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	return record, nil
+}
+
+// Add is a function to add a run along with its steps to the run and run_step tables in the tester database
+// error - ErrInsertFailed, db create call failed
+func (r run) Add(ctx context.Context, record *model.Run, steps []*model.RunStep) (result *model.Run, RowsAffected int64, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		db := tx.Create(record)
+		if db.Error != nil {
+			return db.Error
+		}
+		RowsAffected = db.RowsAffected
+		if len(steps) == 0 {
+			return nil
+		}
+		for _, s := range steps {
+			s.RunID = record.ID
+		}
+		return tx.Create(&steps).Error
+	})
+	if err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return record, RowsAffected, nil
+}
+
+// Timings is a function to get the latest timings of a testcase from run_step table in the tester database
+// error - ErrNotFound, db Find error
+func (r run) Timings(ctx context.Context, testcaseID int, limit int) (steps []*model.RunStep, err error) {
+	steps = []*model.RunStep{}
+	if err = r.DB.Where("testcase_id = ?", testcaseID).Order("id desc").Limit(limit).Find(&steps).Error; err != nil {
+		return nil, cerrors.ErrNotFound
+	}
+
+	return steps, nil
+}
+
+// Previous is a function to get the run of the same flow or testcase preceding a run from run table in the tester database
+// error - ErrNotFound, db record not found
+func (r run) Previous(ctx context.Context, current model.Run) (record model.Run, err error) {
+	db := r.DB.Where("id < ?", current.ID)
+	if current.FlowID.Valid {
+		db = db.Where("flow_id = ?", current.FlowID.Int64)
+	} else {
+		db = db.Where("testcase_id = ?", current.TestcaseID.Int64)
+	}
+	if err = db.Order("id desc").First(&record).Error; err != nil {
+		return record, cerrors.ErrNotFound
+	}
+
+	return record, nil
+}
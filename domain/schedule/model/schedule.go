@@ -0,0 +1,70 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `schedule` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `cron` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `target` enum('flow','suite','tag') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'flow',
+  `flow_id` int(11) DEFAULT NULL,
+  `suite_id` int(11) DEFAULT NULL,
+  `tag` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `environment` varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `paused` tinyint(1) NOT NULL DEFAULT 0,
+  `last_run_at` datetime DEFAULT NULL,
+  `last_status` varchar(16) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `schedule_UK` (`name`),
+  CONSTRAINT `schedule_flow_FK` FOREIGN KEY (`flow_id`) REFERENCES `flow` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `schedule_suite_FK` FOREIGN KEY (`suite_id`) REFERENCES `suite` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 4}
+*/
+
+// Schedule struct is a row record of the schedule table in the tester database.
+// Depending on Target it runs the flow FlowID, the suite SuiteID or every
+// flow tagged Tag whenever the Cron expression fires, unless Paused.
+// LastStatus is the outcome of the latest scheduled run: passed, failed,
+// skipped or error.
+type Schedule struct {
+	ID          int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`     //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	Name        string      `gorm:"column:name;type:VARCHAR;size:255;" json:"name"`              //[ 1] name                                           varchar(255)         null: false  primary: false  auto: false  col: varchar         len: 255     default: []
+	Cron        string      `gorm:"column:cron;type:VARCHAR;size:255;" json:"cron"`              //[ 2] cron                                           varchar(255)         null: false  primary: false  auto: false  col: varchar         len: 255     default: []
+	Target      string      `gorm:"column:target;type:CHAR;size:5;default:flow;" json:"target"`  //[ 3] target                                         char(5)              null: false  primary: false  auto: false  col: char            len: 5       default: [flow]
+	FlowID      null.Int    `gorm:"column:flow_id;type:INT;" json:"flow_id"`                     //[ 4] flow_id                                        int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	SuiteID     null.Int    `gorm:"column:suite_id;type:INT;" json:"suite_id"`                   //[ 5] suite_id                                       int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Tag         null.String `gorm:"column:tag;type:VARCHAR;size:255;" json:"tag"`                //[ 6] tag                                            varchar(255)         null: true   primary: false  auto: false  col: varchar         len: 255     default: [NULL]
+	Environment null.String `gorm:"column:environment;type:VARCHAR;size:64;" json:"environment"` //[ 7] environment                                    varchar(64)          null: true   primary: false  auto: false  col: varchar         len: 64      default: [NULL]
+	Paused      bool        `gorm:"column:paused;type:TINYINT;default:0;" json:"paused"`         //[ 8] paused                                         tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	LastRunAt   null.Time   `gorm:"column:last_run_at;type:DATETIME;" json:"last_run_at"`        //[ 9] last_run_at                                    datetime             null: true   primary: false  auto: false  col: datetime        len: -1      default: [NULL]
+	LastStatus  null.String `gorm:"column:last_status;type:VARCHAR;size:16;" json:"last_status"` //[10] last_status                                    varchar(16)          null: true   primary: false  auto: false  col: varchar         len: 16      default: [NULL]
+	CreatedAt   time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`          //[11] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt   time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`          //[12] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+}
+
+// TableName sets the insert table name for this struct type
+func (s *Schedule) TableName() string {
+	return "schedule"
+}
@@ -0,0 +1,154 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/smallnest/gen/dbmeta"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/schedule/model"
+	"gorm.io/gorm"
+)
+
+type Schedule interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Schedule, int64, error)
+	Active(context.Context) ([]*model.Schedule, error)
+	Get(context.Context, int) (model.Schedule, error)
+	Add(context.Context, *model.Schedule) (*model.Schedule, int64, error)
+	Update(context.Context, int, *model.Schedule) (*model.Schedule, int64, error)
+	Ran(ctx context.Context, id int, at time.Time, status string) error
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewScheduleRepo(db *gorm.DB) Schedule {
+	return schedule{
+		DB: db,
+	}
+}
+
+type schedule struct {
+	DB *gorm.DB
+}
+
+// GetAll is a function to get a slice of record(s) from schedule table in the tester database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func (s schedule) GetAll(ctx context.Context, page, pagesize int64, order string) (schedules []*model.Schedule, totalRows int64, err error) {
+
+	schedules = []*model.Schedule{}
+
+	schedulesOrm := s.DB.Model(&model.Schedule{})
+	schedulesOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		schedulesOrm = schedulesOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		schedulesOrm = schedulesOrm.Limit(int(pagesize))
+	}
+
+	if order != "" {
+		schedulesOrm = schedulesOrm.Order(order)
+	}
+
+	if err = schedulesOrm.Find(&schedules).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	return schedules, totalRows, nil
+}
+
+// Active is a function to get every record of schedule table in the tester database which is not paused
+// error - ErrNotFound, db Find error
+func (s schedule) Active(ctx context.Context) (schedules []*model.Schedule, err error) {
+	schedules = []*model.Schedule{}
+	if err = s.DB.Where("paused = ?", false).Order("id").Find(&schedules).Error; err != nil {
+		return nil, cerrors.ErrNotFound
+	}
+
+	return schedules, nil
+}
+
+// Get is a function to get a single record to schedule table in the tester database
+// error - ErrNotFound, db Find error
+func (s schedule) Get(ctx context.Context, id int) (record model.Schedule, err error) {
+	if err = s.DB.First(&record, id).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	return record, nil
+}
+
+// Add is a function to add a single record to schedule table in the tester database
+// error - ErrInsertFailed, db save call failed
+func (s schedule) Add(ctx context.Context, record *model.Schedule) (result *model.Schedule, RowsAffected int64, err error) {
+	db := s.DB.Save(record)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return record, db.RowsAffected, nil
+}
+
+// Update is a function to update a single record from schedule table in the tester database,
+// paused and the target columns being written even when zero, so that a schedule can resume or change target
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func (s schedule) Update(ctx context.Context, id int, updated *model.Schedule) (result *model.Schedule, RowsAffected int64, err error) {
+
+	result = &model.Schedule{}
+	db := s.DB.First(result, id)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrNotFound
+	}
+
+	if err = dbmeta.Copy(result, updated); err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+	result.Paused = updated.Paused
+	result.FlowID, result.SuiteID, result.Tag = updated.FlowID, updated.SuiteID, updated.Tag
+
+	db = db.Save(result)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	return result, db.RowsAffected, nil
+}
+
+// Ran is a function to record the time and outcome of the latest run of a schedule in the tester database
+// error - ErrUpdateFailed, db Updates call failed
+func (s schedule) Ran(ctx context.Context, id int, at time.Time, status string) error {
+	err := s.DB.Model(&model.Schedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_run_at": at,
+		"last_status": status,
+	}).Error
+	if err != nil {
+		return cerrors.ErrUpdateFailed
+	}
+
+	return nil
+}
+
+// Delete is a function to delete a single record from schedule table in the tester database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func (s schedule) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+
+	record := &model.Schedule{}
+	db := s.DB.First(record, id)
+	if db.Error != nil {
+		return -1, cerrors.ErrNotFound
+	}
+
+	db = db.Delete(record)
+	if err = db.Error; err != nil {
+		return -1, cerrors.ErrDeleteFailed
+	}
+
+	return db.RowsAffected, nil
+}
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null"
+
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	"github.com/thejasn/tester/domain/schedule/model"
+	"github.com/thejasn/tester/pkg/db/dbtest"
+)
+
+func TestScheduleUpdate(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	flow := fmodel.Flow{Name: "login"}
+	if err := db.Create(&flow).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := NewScheduleRepo(db)
+	added, _, err := r.Add(ctx, &model.Schedule{Name: "nightly", Cron: "0 2 * * *", Target: "tag", Tag: null.StringFrom("smoke"), Paused: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = r.Update(ctx, added.ID, &model.Schedule{Name: "nightly", Cron: "0 3 * * *", Target: "flow", FlowID: null.IntFrom(int64(flow.ID))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Get(ctx, added.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Paused || got.Cron != "0 3 * * *" || got.Target != "flow" || got.FlowID.Int64 != int64(flow.ID) || got.Tag.Valid {
+		t.Fatalf("bad schedule: %#v", got)
+	}
+	active, err := r.Active(ctx)
+	if err != nil || len(active) != 1 {
+		t.Fatalf("bad active schedules: %v %#v", err, active)
+	}
+}
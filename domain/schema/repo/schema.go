@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/smallnest/gen/dbmeta"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/schema/model"
+	"gorm.io/gorm"
+)
+
+type Schema interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Schema, int64, error)
+	Get(context.Context, int) (model.Schema, error)
+	Add(context.Context, *model.Schema) (*model.Schema, int64, error)
+	Update(context.Context, int, *model.Schema) (*model.Schema, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewSchemaRepo(db *gorm.DB) Schema {
+	return schema{
+		DB: db,
+	}
+}
+
+type schema struct {
+	DB *gorm.DB
+}
+
+// GetAll is a function to get a slice of record(s) from json_schema table in the tester database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func (s schema) GetAll(ctx context.Context, page, pagesize int64, order string) (schemas []*model.Schema, totalRows int64, err error) {
+
+	schemas = []*model.Schema{}
+
+	schemasOrm := s.DB.Model(&model.Schema{})
+	schemasOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		schemasOrm = schemasOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		schemasOrm = schemasOrm.Limit(int(pagesize))
+	}
+
+	if order != "" {
+		schemasOrm = schemasOrm.Order(order)
+	}
+
+	if err = schemasOrm.Find(&schemas).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	return schemas, totalRows, nil
+}
+
+// GetSchema is a function to get a single record to json_schema table in the tester database
+// error - ErrNotFound, db Find error
+func (s schema) Get(ctx context.Context, id int) (record model.Schema, err error) {
+	if err = s.DB.First(&record, id).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	return record, nil
+}
+
+// AddSchema is a function to add a single record to json_schema table in the tester database
+// error - ErrInsertFailed, db save call failed
+func (s schema) Add(ctx context.Context, schema *model.Schema) (result *model.Schema, RowsAffected int64, err error) {
+	db := s.DB.Save(schema)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return schema, db.RowsAffected, nil
+}
+
+// UpdateSchema is a function to update a single record from json_schema table in the tester database
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func (s schema) Update(ctx context.Context, id int, updated *model.Schema) (result *model.Schema, RowsAffected int64, err error) {
+
+	result = &model.Schema{}
+	db := s.DB.First(result, id)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrNotFound
+	}
+
+	if err = dbmeta.Copy(result, updated); err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	db = db.Save(result)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	return result, db.RowsAffected, nil
+}
+
+// DeleteSchema is a function to delete a single record from json_schema table in the tester database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func (s schema) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+
+	schema := &model.Schema{}
+	db := s.DB.First(schema, id)
+	if db.Error != nil {
+		return -1, cerrors.ErrNotFound
+	}
+
+	db = db.Delete(schema)
+	if err = db.Error; err != nil {
+		return -1, cerrors.ErrDeleteFailed
+	}
+
+	return db.RowsAffected, nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `snapshot` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `testcase_id` int(11) NOT NULL,
+  `golden` blob DEFAULT NULL,
+  `pending` blob DEFAULT NULL,
+  `approved_at` datetime DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `snapshot_UN` (`testcase_id`),
+  CONSTRAINT `snapshot_FK` FOREIGN KEY (`testcase_id`) REFERENCES `testcase` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 4}
+*/
+
+// Snapshot struct is a row record of the snapshot table in the tester database.
+// Golden is the approved response of a testcase, Pending the last response
+// differing from it, awaiting review.
+type Snapshot struct {
+	ID         int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"` //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	TestcaseID int         `gorm:"column:testcase_id;type:INT;" json:"testcase_id"`         //[ 1] testcase_id                                    int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
+	Golden     tmodel.JSON `gorm:"column:golden;" json:"golden"`                            //[ 2] golden                                         blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	Pending    tmodel.JSON `gorm:"column:pending;" json:"pending"`                          //[ 3] pending                                        blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	ApprovedAt null.Time   `gorm:"column:approved_at;type:DATETIME;" json:"approved_at"`    //[ 4] approved_at                                    datetime             null: true   primary: false  auto: false  col: datetime        len: -1      default: [NULL]
+	CreatedAt  time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`      //[ 5] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt  time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`      //[ 6] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+}
+
+// TableName sets the insert table name for this struct type
+func (s *Snapshot) TableName() string {
+	return "snapshot"
+}
@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/snapshot/model"
+	"gorm.io/gorm"
+)
+
+type Snapshot interface {
+	Get(context.Context, int) (model.Snapshot, error)
+	Save(context.Context, *model.Snapshot) (*model.Snapshot, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewSnapshotRepo(db *gorm.DB) Snapshot {
+	return snapshot{
+		DB: db,
+	}
+}
+
+type snapshot struct {
+	DB *gorm.DB
+}
+
+// Get is a function to get the snapshot of a testcase from snapshot table in the tester database
+// error - ErrNotFound, db Find error
+func (s snapshot) Get(ctx context.Context, testcaseID int) (record model.Snapshot, err error) {
+	if err = s.DB.Where("testcase_id = ?", testcaseID).First(&record).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	return record, nil
+}
+
+// Save is a function to insert or update a single record of snapshot table in the tester database
+// error - ErrInsertFailed, db save call failed
+func (s snapshot) Save(ctx context.Context, snapshot *model.Snapshot) (result *model.Snapshot, RowsAffected int64, err error) {
+	db := s.DB.Save(snapshot)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return snapshot, db.RowsAffected, nil
+}
+
+// Delete is a function to delete the snapshot of a testcase from snapshot table in the tester database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func (s snapshot) Delete(ctx context.Context, testcaseID int) (rowsAffected int64, err error) {
+
+	snapshot := &model.Snapshot{}
+	db := s.DB.Where("testcase_id = ?", testcaseID).First(snapshot)
+	if db.Error != nil {
+		return -1, cerrors.ErrNotFound
+	}
+
+	db = s.DB.Delete(snapshot)
+	if err = db.Error; err != nil {
+		return -1, cerrors.ErrDeleteFailed
+	}
+
+	return db.RowsAffected, nil
+}
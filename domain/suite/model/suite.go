@@ -0,0 +1,60 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `suite` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `tags` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `parallel` tinyint(1) NOT NULL DEFAULT 0,
+  `parallelism` int(11) NOT NULL DEFAULT 4,
+  `environment` varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `variables` blob DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `suite_UK` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 3}
+*/
+
+// Suite struct is a row record of the suite table in the tester database.
+// FlowIDs lists the flows of the suite in execution order, they are stored
+// in the suite_flow table.
+type Suite struct {
+	ID          int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`     //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	Name        string      `gorm:"column:name;type:VARCHAR;size:255;" json:"name"`              //[ 1] name                                           varchar(255)         null: false  primary: false  auto: false  col: varchar         len: 255     default: []
+	Tags        null.String `gorm:"column:tags;type:TEXT;size:65535;" json:"tags"`               //[ 2] tags                                           text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Parallel    bool        `gorm:"column:parallel;type:TINYINT;default:0;" json:"parallel"`     //[ 3] parallel                                       tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	Parallelism int         `gorm:"column:parallelism;type:INT;default:4;" json:"parallelism"`   //[ 4] parallelism                                    int                  null: false  primary: false  auto: false  col: int             len: -1      default: [4]
+	Environment null.String `gorm:"column:environment;type:VARCHAR;size:64;" json:"environment"` //[ 5] environment                                    varchar(64)          null: true   primary: false  auto: false  col: varchar         len: 64      default: [NULL]
+	Variables   tmodel.JSON `gorm:"column:variables;" json:"variables"`                          //[ 6] variables                                      blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	CreatedAt   time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`          //[ 7] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt   time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`          //[ 8] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	FlowIDs     []int       `gorm:"-" json:"flow_ids"`
+}
+
+// TableName sets the insert table name for this struct type
+func (s *Suite) TableName() string {
+	return "suite"
+}
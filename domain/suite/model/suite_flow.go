@@ -0,0 +1,33 @@
+package model
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `suite_flow` (
+  `suite_id` int(11) NOT NULL,
+  `flow_id` int(11) NOT NULL,
+  `position` int(11) NOT NULL,
+  PRIMARY KEY (`suite_id`,`flow_id`),
+  CONSTRAINT `suite_flow_suite_FK` FOREIGN KEY (`suite_id`) REFERENCES `suite` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `suite_flow_flow_FK` FOREIGN KEY (`flow_id`) REFERENCES `flow` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "suite_id": 3,    "flow_id": 11,    "position": 0}
+*/
+
+// SuiteFlow struct is a row record of the suite_flow table in the tester
+// database, Position orders the flows of a suite
+type SuiteFlow struct {
+	SuiteID  int `gorm:"column:suite_id;type:INT;primary_key" json:"suite_id"` //[ 0] suite_id                                       int                  null: false  primary: true   auto: false  col: int             len: -1      default: []
+	FlowID   int `gorm:"column:flow_id;type:INT;primary_key" json:"flow_id"`   //[ 1] flow_id                                        int                  null: false  primary: true   auto: false  col: int             len: -1      default: []
+	Position int `gorm:"column:position;type:INT;" json:"position"`            //[ 2] position                                       int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
+}
+
+// TableName sets the insert table name for this struct type
+func (s *SuiteFlow) TableName() string {
+	return "suite_flow"
+}
@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/smallnest/gen/dbmeta"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/suite/model"
+	"gorm.io/gorm"
+)
+
+type Suite interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Suite, int64, error)
+	Get(context.Context, int) (model.Suite, error)
+	Add(context.Context, *model.Suite) (*model.Suite, int64, error)
+	Update(context.Context, int, *model.Suite) (*model.Suite, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewSuiteRepo(db *gorm.DB) Suite {
+	return suite{
+		DB: db,
+	}
+}
+
+type suite struct {
+	DB *gorm.DB
+}
+
+// GetAll is a function to get a slice of record(s) from suite table in the tester database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func (s suite) GetAll(ctx context.Context, page, pagesize int64, order string) (suites []*model.Suite, totalRows int64, err error) {
+
+	suites = []*model.Suite{}
+
+	suitesOrm := s.DB.Model(&model.Suite{})
+	suitesOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		suitesOrm = suitesOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		suitesOrm = suitesOrm.Limit(int(pagesize))
+	}
+
+	if order != "" {
+		suitesOrm = suitesOrm.Order(order)
+	}
+
+	if err = suitesOrm.Find(&suites).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	for _, record := range suites {
+		if record.FlowIDs, err = s.flowIDs(s.DB, record.ID); err != nil {
+			return nil, -1, err
+		}
+	}
+
+	return suites, totalRows, nil
+}
+
+// Get is a function to get a single record to suite table in the tester database along with its flows
+// error - ErrNotFound, db Find error
+func (s suite) Get(ctx context.Context, id int) (record model.Suite, err error) {
+	if err = s.DB.First(&record, id).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	if record.FlowIDs, err = s.flowIDs(s.DB, record.ID); err != nil {
+		return record, err
+	}
+
+	return record, nil
+}
+
+// Add is a function to add a suite along with its flows to the suite and suite_flow tables in the tester database
+// error - ErrInsertFailed, db create call failed
+func (s suite) Add(ctx context.Context, record *model.Suite) (result *model.Suite, RowsAffected int64, err error) {
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		db := tx.Create(record)
+		if db.Error != nil {
+			return db.Error
+		}
+		RowsAffected = db.RowsAffected
+		return s.saveFlows(tx, record.ID, record.FlowIDs)
+	})
+	if err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return record, RowsAffected, nil
+}
+
+// Update is a function to update a suite and replace its flows in the tester database,
+// parallel being written even when false so that a suite can go back to running its flows in sequence
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func (s suite) Update(ctx context.Context, id int, updated *model.Suite) (result *model.Suite, RowsAffected int64, err error) {
+
+	result = &model.Suite{}
+	if err = s.DB.First(result, id).Error; err != nil {
+		return nil, -1, cerrors.ErrNotFound
+	}
+
+	if err = dbmeta.Copy(result, updated); err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+	result.Parallel = updated.Parallel
+	result.FlowIDs = updated.FlowIDs
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		db := tx.Save(result)
+		if db.Error != nil {
+			return db.Error
+		}
+		RowsAffected = db.RowsAffected
+		if err := tx.Where("suite_id = ?", id).Delete(&model.SuiteFlow{}).Error; err != nil {
+			return err
+		}
+		return s.saveFlows(tx, id, result.FlowIDs)
+	})
+	if err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	return result, RowsAffected, nil
+}
+
+// Delete is a function to delete a single record from suite table in the tester database, its flows are deleted in cascade
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func (s suite) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+
+	record := &model.Suite{}
+	db := s.DB.First(record, id)
+	if db.Error != nil {
+		return -1, cerrors.ErrNotFound
+	}
+
+	db = db.Delete(record)
+	if err = db.Error; err != nil {
+		return -1, cerrors.ErrDeleteFailed
+	}
+
+	return db.RowsAffected, nil
+}
+
+func (s suite) saveFlows(tx *gorm.DB, suiteID int, flowIDs []int) error {
+	if len(flowIDs) == 0 {
+		return nil
+	}
+	rows := make([]*model.SuiteFlow, len(flowIDs))
+	for i, id := range flowIDs {
+		rows[i] = &model.SuiteFlow{SuiteID: suiteID, FlowID: id, Position: i}
+	}
+	return tx.Create(&rows).Error
+}
+
+func (s suite) flowIDs(db *gorm.DB, suiteID int) ([]int, error) {
+	ids := []int{}
+	err := db.Model(&model.SuiteFlow{}).Where("suite_id = ?", suiteID).Order("position").Pluck("flow_id", &ids).Error
+	if err != nil {
+		return nil, cerrors.ErrNotFound
+	}
+	return ids, nil
+}
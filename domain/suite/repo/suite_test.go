@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	"github.com/thejasn/tester/domain/suite/model"
+	"github.com/thejasn/tester/pkg/db/dbtest"
+)
+
+func TestSuite(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	var ids []int
+	for _, name := range []string{"login", "cart", "checkout"} {
+		flow := fmodel.Flow{Name: name}
+		if err := db.Create(&flow).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, flow.ID)
+	}
+	r := NewSuiteRepo(db)
+
+	added, _, err := r.Add(ctx, &model.Suite{Name: "nightly", Parallel: true, Parallelism: 2, FlowIDs: []int{ids[0], ids[1]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Get(ctx, added.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Parallel || !reflect.DeepEqual(got.FlowIDs, []int{ids[0], ids[1]}) {
+		t.Fatalf("bad suite: %#v", got)
+	}
+
+	if _, _, err = r.Update(ctx, added.ID, &model.Suite{Name: "nightly", FlowIDs: []int{ids[2], ids[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = r.Get(ctx, added.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got.Parallel || got.Parallelism != 2 || !reflect.DeepEqual(got.FlowIDs, []int{ids[2], ids[0]}) {
+		t.Fatalf("bad updated suite: %#v", got)
+	}
+
+	if _, err = r.Delete(ctx, added.ID); err != nil {
+		t.Fatal(err)
+	}
+	var left int64
+	if err = db.Model(&model.SuiteFlow{}).Where("suite_id = ?", added.ID).Count(&left).Error; err != nil || left != 0 {
+		t.Fatalf("flows of the deleted suite left: %d %v", left, err)
+	}
+}
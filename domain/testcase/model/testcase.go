@@ -41,9 +41,41 @@ CREATE TABLE `testcase` (
   `path` text COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '/',
   `body` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
   `mapping_test_id` int(11) DEFAULT NULL,
+  `api` enum('REST','GRPC') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'REST',
+  `when` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `on_false` enum('skip','stop') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'skip',
+  `branch` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `branch_else` tinyint(1) NOT NULL DEFAULT 0,
+  `loop` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `needs` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `retry` blob DEFAULT NULL,
+  `timeout` varchar(32) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `format` enum('json','text','binary') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'json',
+  `schema` blob DEFAULT NULL,
+  `schema_id` int(11) DEFAULT NULL,
+  `schema_path` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `contract` tinyint(1) NOT NULL DEFAULT 0,
+  `protoset_id` int(11) DEFAULT NULL,
+  `snapshot` tinyint(1) NOT NULL DEFAULT 0,
+  `snapshot_ignore` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `compare` blob DEFAULT NULL,
+  `sla` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `pre_script` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `post_script` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `script_timeout` varchar(32) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `script_max_steps` int(11) DEFAULT NULL,
+  `assert` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `extract` blob DEFAULT NULL,
+  `subflow_id` int(11) DEFAULT NULL,
+  `inputs` blob DEFAULT NULL,
+  `phase` enum('setup','main','teardown') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'main',
+  `tags` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
   PRIMARY KEY (`id`),
   UNIQUE KEY `testcase_UN` (`flow_id`,`test_case_id`),
-  CONSTRAINT `testcase_FK` FOREIGN KEY (`flow_id`) REFERENCES `flow` (`id`)
+  CONSTRAINT `testcase_FK` FOREIGN KEY (`flow_id`) REFERENCES `flow` (`id`),
+  CONSTRAINT `testcase_schema_FK` FOREIGN KEY (`schema_id`) REFERENCES `json_schema` (`id`),
+  CONSTRAINT `testcase_protoset_FK` FOREIGN KEY (`protoset_id`) REFERENCES `protoset` (`id`),
+  CONSTRAINT `testcase_subflow_FK` FOREIGN KEY (`subflow_id`) REFERENCES `flow` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
 
 JSON Sample
@@ -55,37 +87,90 @@ type JSON json.RawMessage
 
 // Testcase struct is a row record of the testcase table in the tester database
 type Testcase struct {
-	ID            int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`      //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
-	Name          string      `gorm:"column:name;type:TEXT;size:65535;" json:"name"`                //[ 1] name                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
-	Expected      JSON        `gorm:"column:expected;" json:"expected"`                             //[ 2] expected                                       blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
-	Actual        null.String `gorm:"column:actual;type:TEXT;size:65535;" json:"actual"`            //[ 3] actual                                         text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
-	Operation     string      `gorm:"column:operation;type:CHAR;size:9;" json:"operation"`          //[ 4] operation                                      char(9)              null: false  primary: false  auto: false  col: char            len: 9       default: []
-	CreatedAt     time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`           //[ 5] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
-	UpdatedAt     time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`           //[ 6] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
-	Status        int         `gorm:"column:status;type:TINYINT;default:1;" json:"status"`          //[ 7] status                                         tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [1]
-	FlowID        int         `gorm:"column:flow_id;type:INT;" json:"flow_id"`                      //[ 8] flow_id                                        int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
-	TestCaseID    int         `gorm:"column:test_case_id;type:INT;" json:"test_case_id"`            //[ 9] test_case_id                                   int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
-	Scheme        string      `gorm:"column:scheme;type:CHAR;size:5;default:'http';" json:"scheme"` //[10] scheme                                         char(5)              null: false  primary: false  auto: false  col: char            len: 5       default: ['http']
-	Host          string      `gorm:"column:host;type:TEXT;size:65535;" json:"host"`                //[11] host                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
-	Port          int         `gorm:"column:port;type:INT;default:8080;" json:"port"`               //[12] port                                           int                  null: false  primary: false  auto: false  col: int             len: -1      default: [8080]
-	Headers       []byte      `gorm:"column:headers;type:BLOB;" json:"headers"`                     //[13] headers                                        blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
-	Method        null.String `gorm:"column:method;type:TEXT;size:65535;" json:"method"`            //[14] method                                         text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
-	Path          string      `gorm:"column:path;type:TEXT;size:65535;default:'/';" json:"path"`    //[15] path                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: ['/']
-	Body          null.String `gorm:"column:body;type:TEXT;size:65535;" json:"body"`                //[16] body                                           text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
-	MappingTestID null.Int    `gorm:"column:mapping_test_id;type:INT;" json:"mapping_test_id"`      //[17] mapping_test_id                                int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
-	API           string      `gorm:"column:api;type:CHAR;size:4;default:'REST';" json:"api"`       //[18] api                                            char(4)              null: false  primary: false  auto: false  col: char            len: 4       default: ['REST']
+	ID             int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`             //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	Name           string      `gorm:"column:name;type:TEXT;size:65535;" json:"name"`                       //[ 1] name                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
+	Expected       JSON        `gorm:"column:expected;" json:"expected"`                                    //[ 2] expected                                       blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	Actual         null.String `gorm:"column:actual;type:TEXT;size:65535;" json:"actual"`                   //[ 3] actual                                         text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Operation      string      `gorm:"column:operation;type:CHAR;size:9;" json:"operation"`                 //[ 4] operation                                      char(9)              null: false  primary: false  auto: false  col: char            len: 9       default: []
+	CreatedAt      time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`                  //[ 5] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt      time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`                  //[ 6] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	Status         int         `gorm:"column:status;type:TINYINT;default:1;" json:"status"`                 //[ 7] status                                         tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [1]
+	FlowID         int         `gorm:"column:flow_id;type:INT;" json:"flow_id"`                             //[ 8] flow_id                                        int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
+	TestCaseID     int         `gorm:"column:test_case_id;type:INT;" json:"test_case_id"`                   //[ 9] test_case_id                                   int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
+	Scheme         string      `gorm:"column:scheme;type:CHAR;size:5;default:'http';" json:"scheme"`        //[10] scheme                                         char(5)              null: false  primary: false  auto: false  col: char            len: 5       default: ['http']
+	Host           string      `gorm:"column:host;type:TEXT;size:65535;" json:"host"`                       //[11] host                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
+	Port           int         `gorm:"column:port;type:INT;default:8080;" json:"port"`                      //[12] port                                           int                  null: false  primary: false  auto: false  col: int             len: -1      default: [8080]
+	Headers        []byte      `gorm:"column:headers;type:BLOB;" json:"headers"`                            //[13] headers                                        blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	Method         null.String `gorm:"column:method;type:TEXT;size:65535;" json:"method"`                   //[14] method                                         text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Path           string      `gorm:"column:path;type:TEXT;size:65535;default:'/';" json:"path"`           //[15] path                                           text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: ['/']
+	Body           null.String `gorm:"column:body;type:TEXT;size:65535;" json:"body"`                       //[16] body                                           text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	MappingTestID  null.Int    `gorm:"column:mapping_test_id;type:INT;" json:"mapping_test_id"`             //[17] mapping_test_id                                int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	API            string      `gorm:"column:api;type:CHAR;size:4;default:'REST';" json:"api"`              //[18] api                                            char(4)              null: false  primary: false  auto: false  col: char            len: 4       default: ['REST']
+	When           null.String `gorm:"column:when;type:TEXT;size:65535;" json:"when"`                       //[19] when                                           text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	OnFalse        string      `gorm:"column:on_false;type:CHAR;size:4;default:'skip';" json:"on_false"`    //[20] on_false                                       char(4)              null: false  primary: false  auto: false  col: char            len: 4       default: ['skip']
+	Branch         null.String `gorm:"column:branch;type:TEXT;size:65535;" json:"branch"`                   //[21] branch                                         text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	BranchElse     bool        `gorm:"column:branch_else;type:TINYINT;default:0;" json:"branch_else"`       //[22] branch_else                                    tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	Loop           null.String `gorm:"column:loop;type:TEXT;size:65535;" json:"loop"`                       //[23] loop                                           text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Needs          null.String `gorm:"column:needs;type:TEXT;size:65535;" json:"needs"`                     //[24] needs                                          text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Retry          JSON        `gorm:"column:retry;" json:"retry"`                                          //[25] retry                                          blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	Timeout        null.String `gorm:"column:timeout;type:VARCHAR;size:32;" json:"timeout"`                 //[26] timeout                                        varchar(32)          null: true   primary: false  auto: false  col: varchar         len: 32      default: [NULL]
+	Format         string      `gorm:"column:format;type:CHAR;size:6;default:'json';" json:"format"`        //[27] format                                         char(6)              null: false  primary: false  auto: false  col: char            len: 6       default: ['json']
+	Schema         JSON        `gorm:"column:schema;" json:"schema"`                                        //[28] schema                                         blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	SchemaID       null.Int    `gorm:"column:schema_id;type:INT;" json:"schema_id"`                         //[29] schema_id                                      int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	SchemaPath     null.String `gorm:"column:schema_path;type:TEXT;size:65535;" json:"schema_path"`         //[30] schema_path                                    text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Contract       bool        `gorm:"column:contract;type:TINYINT;default:0;" json:"contract"`             //[31] contract                                       tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	ProtosetID     null.Int    `gorm:"column:protoset_id;type:INT;" json:"protoset_id"`                     //[32] protoset_id                                    int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Snapshot       bool        `gorm:"column:snapshot;type:TINYINT;default:0;" json:"snapshot"`             //[33] snapshot                                       tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	SnapshotIgnore null.String `gorm:"column:snapshot_ignore;type:TEXT;size:65535;" json:"snapshot_ignore"` //[34] snapshot_ignore                                text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Compare        JSON        `gorm:"column:compare;" json:"compare"`                                      //[35] compare                                        blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	SLA            null.String `gorm:"column:sla;type:TEXT;size:65535;" json:"sla"`                         //[36] sla                                            text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	PreScript      null.String `gorm:"column:pre_script;type:TEXT;size:65535;" json:"pre_script"`           //[37] pre_script                                     text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	PostScript     null.String `gorm:"column:post_script;type:TEXT;size:65535;" json:"post_script"`         //[38] post_script                                    text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	ScriptTimeout  null.String `gorm:"column:script_timeout;type:VARCHAR;size:32;" json:"script_timeout"`   //[39] script_timeout                                 varchar(32)          null: true   primary: false  auto: false  col: varchar         len: 32      default: [NULL]
+	ScriptMaxSteps null.Int    `gorm:"column:script_max_steps;type:INT;" json:"script_max_steps"`           //[40] script_max_steps                               int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Assert         null.String `gorm:"column:assert;type:TEXT;size:65535;" json:"assert"`                   //[41] assert                                         text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	Extract        JSON        `gorm:"column:extract;" json:"extract"`                                      //[42] extract                                        blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	SubflowID      null.Int    `gorm:"column:subflow_id;type:INT;" json:"subflow_id"`                       //[43] subflow_id                                     int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Inputs         JSON        `gorm:"column:inputs;" json:"inputs"`                                        //[44] inputs                                         blob                 null: true   primary: false  auto: false  col: blob            len: -1      default: [NULL]
+	Phase          string      `gorm:"column:phase;type:CHAR;size:8;default:'main';" json:"phase"`          //[45] phase                                          char(8)              null: false  primary: false  auto: false  col: char            len: 8       default: ['main']
+	Tags           null.String `gorm:"column:tags;type:TEXT;size:65535;" json:"tags"`                       //[46] tags                                           text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
 }
 
 type Result struct {
 	Data interface{}
 }
 
+// Retry is the retry policy stored with a testcase, durations use the
+// time.ParseDuration format e.g. "500ms"
+type Retry struct {
+	MaxAttempts int    `json:"max_attempts"`
+	Backoff     string `json:"backoff"`
+	Delay       string `json:"delay"`
+	MaxDelay    string `json:"max_delay"`
+	OnStatus    []int  `json:"on_status"`
+	OnError     bool   `json:"on_error"`
+	PollFor     string `json:"poll_for"`
+}
+
+// Compare tunes how responses are compared with the expected value and the
+// golden snapshot of a testcase
+type Compare struct {
+	Ignore            []string `json:"ignore"`
+	IgnoreOrder       bool     `json:"ignore_order"`
+	IgnoreExtra       bool     `json:"ignore_extra"`
+	IgnoreNumericType bool     `json:"ignore_numeric_type"`
+}
+
 func (JSON) GormDataType() string {
 	return "json"
 }
 
 // Scan scan value into Jsonb, implements sql.Scanner interface
 func (j *JSON) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
 	bytes, ok := value.([]byte)
 	if !ok {
 		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
@@ -114,7 +199,7 @@ func (r *JSON) UnmarshalJSON(b []byte) error {
 
 func (r JSON) MarshalJSON() ([]byte, error) {
 	if len(r) == 0 {
-		return nil, nil
+		return []byte("null"), nil
 	}
 	return json.RawMessage(r).MarshalJSON()
 }
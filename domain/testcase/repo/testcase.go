@@ -6,11 +6,24 @@ import (
 	"github.com/smallnest/gen/dbmeta"
 	"github.com/thejasn/tester/cerrors"
 	"github.com/thejasn/tester/domain/testcase/model"
+	pdb "github.com/thejasn/tester/pkg/db"
 	"gorm.io/gorm"
 )
 
+// Filter narrows the testcases listed, empty fields match anything. Name
+// matches a substring of the name, Tag one of the comma separated tags and
+// Status the outcome of the testcase in its latest run, e.g. "FAILED".
+type Filter struct {
+	FlowID int
+	Name   string
+	Tag    string
+	API    string
+	Host   string
+	Status string
+}
+
 type Testcase interface {
-	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Testcase, int64, error)
+	GetAll(ctx context.Context, page, pagesize int64, order string, filter Filter) ([]*model.Testcase, int64, error)
 	Get(context.Context, int) (model.Testcase, error)
 	Add(context.Context, *model.Testcase) (*model.Testcase, int64, error)
 	Update(context.Context, int, *model.Testcase) (*model.Testcase, int64, error)
@@ -32,12 +45,13 @@ type testcase struct {
 // params - page     - page requested (defaults to 0)
 // params - pagesize - number of records in a page  (defaults to 20)
 // params - order    - db sort order column
+// params - filter   - criteria the records must match
 // error - ErrNotFound, db Find error
-func (t testcase) GetAll(ctx context.Context, page, pagesize int64, order string) (testcases []*model.Testcase, totalRows int64, err error) {
+func (t testcase) GetAll(ctx context.Context, page, pagesize int64, order string, filter Filter) (testcases []*model.Testcase, totalRows int64, err error) {
 
 	testcases = []*model.Testcase{}
 
-	testcasesOrm := t.DB.Model(&model.Testcase{})
+	testcasesOrm := t.filtered(filter)
 	testcasesOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -137,3 +151,31 @@ func (t testcase) GetAllOrderedWhere(ctx context.Context, conditions map[string]
 
 	return testcases, totalRows, nil
 }
+
+// filtered scopes a query on the testcase table to the records matching filter
+func (t testcase) filtered(filter Filter) *gorm.DB {
+	escape := pdb.LikeEscape(t.DB.Dialector.Name())
+	db := t.DB.Model(&model.Testcase{})
+	if filter.FlowID > 0 {
+		db = db.Where("flow_id = ?", filter.FlowID)
+	}
+	if filter.Name != "" {
+		db = db.Where("LOWER(name) LIKE LOWER(?) "+escape, "%"+pdb.EscapeLike(filter.Name)+"%")
+	}
+	if filter.Tag != "" {
+		tag := pdb.EscapeLike(filter.Tag)
+		db = db.Where("tags = ? OR tags LIKE ? "+escape+" OR tags LIKE ? "+escape+" OR tags LIKE ? "+escape,
+			filter.Tag, tag+",%", "%,"+tag, "%,"+tag+",%")
+	}
+	if filter.API != "" {
+		db = db.Where("api = ?", filter.API)
+	}
+	if filter.Host != "" {
+		db = db.Where("host = ?", filter.Host)
+	}
+	if filter.Status != "" {
+		db = db.Where("id IN (SELECT s.testcase_id FROM run_step s WHERE s.status = ? AND s.id = (SELECT MAX(l.id) FROM run_step l WHERE l.testcase_id = s.testcase_id))",
+			filter.Status)
+	}
+	return db
+}
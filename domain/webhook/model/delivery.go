@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/guregu/null"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `webhook_delivery` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `webhook_id` int(11) NOT NULL,
+  `run_id` int(11) DEFAULT NULL,
+  `event` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `payload` mediumblob NOT NULL,
+  `attempts` int(11) NOT NULL DEFAULT 0,
+  `delivered` tinyint(1) NOT NULL DEFAULT 0,
+  `status_code` int(11) DEFAULT NULL,
+  `error` text COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `duration_ms` double NOT NULL DEFAULT 0,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  KEY `webhook_delivery_IDX` (`webhook_id`,`created_at`),
+  CONSTRAINT `webhook_delivery_webhook_FK` FOREIGN KEY (`webhook_id`) REFERENCES `webhook` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `webhook_delivery_run_FK` FOREIGN KEY (`run_id`) REFERENCES `run` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 51}
+*/
+
+// Delivery struct is a row record of the webhook_delivery table in the tester
+// database, it logs a notification along with the outcome of its last
+// attempt. StatusCode is the response to the last attempt, if one came back.
+type Delivery struct {
+	ID         int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`      //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	WebhookID  int         `gorm:"column:webhook_id;type:INT;" json:"webhook_id"`                //[ 1] webhook_id                                     int                  null: false  primary: false  auto: false  col: int             len: -1      default: []
+	RunID      null.Int    `gorm:"column:run_id;type:INT;" json:"run_id"`                        //[ 2] run_id                                         int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Event      string      `gorm:"column:event;type:VARCHAR;size:16;" json:"event"`              //[ 3] event                                          varchar(16)          null: false  primary: false  auto: false  col: varchar         len: 16      default: []
+	Payload    tmodel.JSON `gorm:"column:payload;" json:"payload"`                               //[ 4] payload                                        mediumblob           null: false  primary: false  auto: false  col: mediumblob      len: -1      default: []
+	Attempts   int         `gorm:"column:attempts;type:INT;default:0;" json:"attempts"`          //[ 5] attempts                                       int                  null: false  primary: false  auto: false  col: int             len: -1      default: [0]
+	Delivered  bool        `gorm:"column:delivered;type:TINYINT;default:0;" json:"delivered"`    //[ 6] delivered                                      tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	StatusCode null.Int    `gorm:"column:status_code;type:INT;" json:"status_code"`              //[ 7] status_code                                    int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Error      null.String `gorm:"column:error;type:TEXT;size:65535;" json:"error"`              //[ 8] error                                          text(65535)          null: true   primary: false  auto: false  col: text            len: 65535   default: [NULL]
+	DurationMs float64     `gorm:"column:duration_ms;type:DOUBLE;default:0;" json:"duration_ms"` //[ 9] duration_ms                                    double               null: false  primary: false  auto: false  col: double          len: -1      default: [0]
+	CreatedAt  time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`           //[10] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+}
+
+// TableName sets the insert table name for this struct type
+func (d *Delivery) TableName() string {
+	return "webhook_delivery"
+}
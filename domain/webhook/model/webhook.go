@@ -0,0 +1,68 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `webhook` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `url` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `secret` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `event` enum('finished','changed') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'finished',
+  `flow_id` int(11) DEFAULT NULL,
+  `paused` tinyint(1) NOT NULL DEFAULT 0,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `webhook_UK` (`name`),
+  CONSTRAINT `webhook_flow_FK` FOREIGN KEY (`flow_id`) REFERENCES `flow` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+
+JSON Sample
+-------------------------------------
+{    "id": 5}
+*/
+
+// Webhook struct is a row record of the webhook table in the tester database.
+// The URL is posted to when a run finishes, or only when its outcome
+// differs from the previous run with the event changed. Runs of every flow
+// are notified unless FlowID is set. Payloads are signed with Secret, if any.
+type Webhook struct {
+	ID        int         `gorm:"AUTO_INCREMENT;column:id;type:INT;primary_key" json:"id"`      //[ 0] id                                             int                  null: false  primary: true   auto: true   col: int             len: -1      default: []
+	Name      string      `gorm:"column:name;type:VARCHAR;size:255;" json:"name"`               //[ 1] name                                           varchar(255)         null: false  primary: false  auto: false  col: varchar         len: 255     default: []
+	URL       string      `gorm:"column:url;type:TEXT;size:65535;" json:"url"`                  //[ 2] url                                            text(65535)          null: false  primary: false  auto: false  col: text            len: 65535   default: []
+	Secret    null.String `gorm:"column:secret;type:VARCHAR;size:255;" json:"secret"`           //[ 3] secret                                         varchar(255)         null: true   primary: false  auto: false  col: varchar         len: 255     default: [NULL]
+	Event     string      `gorm:"column:event;type:CHAR;size:8;default:finished;" json:"event"` //[ 4] event                                          char(8)              null: false  primary: false  auto: false  col: char            len: 8       default: [finished]
+	FlowID    null.Int    `gorm:"column:flow_id;type:INT;" json:"flow_id"`                      //[ 5] flow_id                                        int                  null: true   primary: false  auto: false  col: int             len: -1      default: [NULL]
+	Paused    bool        `gorm:"column:paused;type:TINYINT;default:0;" json:"paused"`          //[ 6] paused                                         tinyint              null: false  primary: false  auto: false  col: tinyint         len: -1      default: [0]
+	CreatedAt time.Time   `gorm:"column:created_at;type:DATETIME;" json:"created_at"`           //[ 7] created_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+	UpdatedAt time.Time   `gorm:"column:updated_at;type:DATETIME;" json:"updated_at"`           //[ 8] updated_at                                     datetime             null: false  primary: false  auto: false  col: datetime        len: -1      default: [current_timestamp()]
+}
+
+// TableName sets the insert table name for this struct type
+func (w *Webhook) TableName() string {
+	return "webhook"
+}
+
+// Redacted returns the webhook without its signing secret, which is written
+// through the API but not read back from it. A secret left out of an update
+// keeps its stored value.
+func (w Webhook) Redacted() Webhook {
+	w.Secret = null.String{}
+	return w
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/guregu/null"
+	"github.com/smallnest/gen/dbmeta"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/webhook/model"
+	"gorm.io/gorm"
+)
+
+type Webhook interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Webhook, int64, error)
+	Get(context.Context, int) (model.Webhook, error)
+	Add(context.Context, *model.Webhook) (*model.Webhook, int64, error)
+	Update(context.Context, int, *model.Webhook) (*model.Webhook, int64, error)
+	Delete(context.Context, int) (int64, error)
+	Matching(ctx context.Context, flowID null.Int) ([]*model.Webhook, error)
+	AddDelivery(context.Context, *model.Delivery) error
+	SaveDelivery(context.Context, *model.Delivery) error
+	Deliveries(ctx context.Context, webhookID int, page, pagesize int64) ([]*model.Delivery, int64, error)
+}
+
+func NewWebhookRepo(db *gorm.DB) Webhook {
+	return webhook{
+		DB: db,
+	}
+}
+
+type webhook struct {
+	DB *gorm.DB
+}
+
+// GetAll is a function to get a slice of record(s) from webhook table in the tester database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func (w webhook) GetAll(ctx context.Context, page, pagesize int64, order string) (webhooks []*model.Webhook, totalRows int64, err error) {
+
+	webhooks = []*model.Webhook{}
+
+	webhooksOrm := w.DB.Model(&model.Webhook{})
+	webhooksOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		webhooksOrm = webhooksOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		webhooksOrm = webhooksOrm.Limit(int(pagesize))
+	}
+
+	if order != "" {
+		webhooksOrm = webhooksOrm.Order(order)
+	}
+
+	if err = webhooksOrm.Find(&webhooks).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	return webhooks, totalRows, nil
+}
+
+// Get is a function to get a single record to webhook table in the tester database
+// error - ErrNotFound, db Find error
+func (w webhook) Get(ctx context.Context, id int) (record model.Webhook, err error) {
+	if err = w.DB.First(&record, id).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return record, err
+	}
+
+	return record, nil
+}
+
+// Add is a function to add a single record to webhook table in the tester database
+// error - ErrInsertFailed, db save call failed
+func (w webhook) Add(ctx context.Context, record *model.Webhook) (result *model.Webhook, RowsAffected int64, err error) {
+	db := w.DB.Save(record)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrInsertFailed
+	}
+
+	return record, db.RowsAffected, nil
+}
+
+// Update is a function to update a single record from webhook table in the tester database,
+// paused and flow_id being written even when zero so that a webhook can be re-enabled or
+// widened to every flow, the secret being kept when left out
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func (w webhook) Update(ctx context.Context, id int, updated *model.Webhook) (result *model.Webhook, RowsAffected int64, err error) {
+
+	result = &model.Webhook{}
+	db := w.DB.First(result, id)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrNotFound
+	}
+
+	if err = dbmeta.Copy(result, updated); err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+	result.Paused, result.FlowID = updated.Paused, updated.FlowID
+
+	db = db.Save(result)
+	if err = db.Error; err != nil {
+		return nil, -1, cerrors.ErrUpdateFailed
+	}
+
+	return result, db.RowsAffected, nil
+}
+
+// Delete is a function to delete a single record from webhook table in the tester database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func (w webhook) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+
+	record := &model.Webhook{}
+	db := w.DB.First(record, id)
+	if db.Error != nil {
+		return -1, cerrors.ErrNotFound
+	}
+
+	db = db.Delete(record)
+	if err = db.Error; err != nil {
+		return -1, cerrors.ErrDeleteFailed
+	}
+
+	return db.RowsAffected, nil
+}
+
+// Matching is a function to get the records of webhook table in the tester database notified of a run of the flow, if any, which are not paused
+// error - ErrNotFound, db Find error
+func (w webhook) Matching(ctx context.Context, flowID null.Int) (webhooks []*model.Webhook, err error) {
+	webhooks = []*model.Webhook{}
+	db := w.DB.Where("paused = ?", false)
+	if flowID.Valid {
+		db = db.Where("flow_id IS NULL OR flow_id = ?", flowID.Int64)
+	} else {
+		db = db.Where("flow_id IS NULL")
+	}
+	if err = db.Order("id").Find(&webhooks).Error; err != nil {
+		return nil, cerrors.ErrNotFound
+	}
+
+	return webhooks, nil
+}
+
+// AddDelivery is a function to add a single record to webhook_delivery table in the tester database
+// error - ErrInsertFailed, db create call failed
+func (w webhook) AddDelivery(ctx context.Context, record *model.Delivery) error {
+	if err := w.DB.Create(record).Error; err != nil {
+		return cerrors.ErrInsertFailed
+	}
+
+	return nil
+}
+
+// SaveDelivery is a function to update a single record of webhook_delivery table in the tester database
+// error - ErrUpdateFailed, db save call failed
+func (w webhook) SaveDelivery(ctx context.Context, record *model.Delivery) error {
+	if err := w.DB.Save(record).Error; err != nil {
+		return cerrors.ErrUpdateFailed
+	}
+
+	return nil
+}
+
+// Deliveries is a function to get a slice of record(s) of a webhook from webhook_delivery table in the tester database, latest first
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// error - ErrNotFound, db Find error
+func (w webhook) Deliveries(ctx context.Context, webhookID int, page, pagesize int64) (deliveries []*model.Delivery, totalRows int64, err error) {
+
+	deliveries = []*model.Delivery{}
+
+	deliveriesOrm := w.DB.Model(&model.Delivery{}).Where("webhook_id = ?", webhookID)
+	deliveriesOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		deliveriesOrm = deliveriesOrm.Offset(int(offset)).Limit(int(pagesize))
+	} else {
+		deliveriesOrm = deliveriesOrm.Limit(int(pagesize))
+	}
+
+	if err = deliveriesOrm.Order("id desc").Find(&deliveries).Error; err != nil {
+		err = cerrors.ErrNotFound
+		return nil, -1, err
+	}
+
+	return deliveries, totalRows, nil
+}
@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null"
+
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	"github.com/thejasn/tester/domain/webhook/model"
+	"github.com/thejasn/tester/pkg/db/dbtest"
+)
+
+func TestWebhookUpdate(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	flow := fmodel.Flow{Name: "login"}
+	if err := db.Create(&flow).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := NewWebhookRepo(db)
+	added, _, err := r.Add(ctx, &model.Webhook{Name: "ci", URL: "http://ci", Secret: null.StringFrom("s3cret"), Event: "finished", FlowID: null.IntFrom(int64(flow.ID)), Paused: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = r.Update(ctx, added.ID, &model.Webhook{Name: "ci", URL: "http://ci", Event: "finished"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Get(ctx, added.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Paused || got.FlowID.Valid || got.Secret.String != "s3cret" {
+		t.Fatalf("bad webhook: %#v", got)
+	}
+	matching, err := r.Matching(ctx, null.IntFrom(int64(flow.ID)+1))
+	if err != nil || len(matching) != 1 {
+		t.Fatalf("bad matching webhooks: %v %#v", err, matching)
+	}
+}
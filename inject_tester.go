@@ -7,24 +7,57 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/google/wire"
+	authrepo "github.com/thejasn/tester/domain/auth/repo"
 	flowrepo "github.com/thejasn/tester/domain/flow/repo"
+	protosetrepo "github.com/thejasn/tester/domain/protoset/repo"
+	runrepo "github.com/thejasn/tester/domain/run/repo"
+	schedulerepo "github.com/thejasn/tester/domain/schedule/repo"
+	schemarepo "github.com/thejasn/tester/domain/schema/repo"
+	snapshotrepo "github.com/thejasn/tester/domain/snapshot/repo"
+	suiterepo "github.com/thejasn/tester/domain/suite/repo"
 	testcaserepo "github.com/thejasn/tester/domain/testcase/repo"
+	webhookrepo "github.com/thejasn/tester/domain/webhook/repo"
 	"github.com/thejasn/tester/service"
 	"github.com/thejasn/tester/transport/http"
 	"github.com/thejasn/tester/transport/http/handler"
 	"gorm.io/gorm"
 )
 
-func injectTester(ctx context.Context, r *chi.Mux, db *gorm.DB) http.Router {
+func injectTester(ctx context.Context, r *chi.Mux, db *gorm.DB) application {
 	wire.Build(
 		flowrepo.NewFlowRepo,
 		testcaserepo.NewTestcaseRepo,
+		authrepo.NewProfileRepo,
+		schemarepo.NewSchemaRepo,
+		protosetrepo.NewProtosetRepo,
+		snapshotrepo.NewSnapshotRepo,
+		runrepo.NewRunRepo,
+		suiterepo.NewSuiteRepo,
+		schedulerepo.NewScheduleRepo,
+		webhookrepo.NewWebhookRepo,
 		service.NewFlowSvc,
 		service.NewTestcaseSvc,
+		service.NewAuthProfileSvc,
+		service.NewSchemaSvc,
+		service.NewProtosetSvc,
+		service.NewSnapshotSvc,
+		service.NewRunSvc,
+		service.NewSuiteSvc,
+		service.NewScheduleSvc,
+		service.NewWebhookSvc,
 		wire.Struct(new(handler.Set), "*"),
 		handler.NewFlowHandler,
 		handler.NewTestcaseHandler,
+		handler.NewAuthProfileHandler,
+		handler.NewSchemaHandler,
+		handler.NewProtosetHandler,
+		handler.NewSnapshotHandler,
+		handler.NewRunHandler,
+		handler.NewSuiteHandler,
+		handler.NewScheduleHandler,
+		handler.NewWebhookHandler,
 		http.NewRouter,
+		wire.Struct(new(application), "*"),
 	)
-	return http.Router{}
+	return application{}
 }
@@ -2,35 +2,59 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"github.com/go-chi/chi"
 	"github.com/thejasn/tester/pkg/config"
 	"github.com/thejasn/tester/pkg/db"
 	"github.com/thejasn/tester/pkg/log"
 	"github.com/thejasn/tester/pkg/server"
+	"github.com/thejasn/tester/service"
+	"github.com/thejasn/tester/transport/http"
 	"golang.org/x/sync/errgroup"
 )
 
+// application is what the server runs, the http routes and the scheduler
+// of flow runs
+type application struct {
+	Router    http.Router
+	Scheduler service.Schedule
+	Webhooks  service.Webhook
+}
+
 func main() {
 
 	ctx := log.WithLogger(context.Background(), log.Init())
 
-	router := injectTester(ctx, chi.NewMux(), db.LoadDatabase(ctx, config.LoadAppConfig()))
+	conf := config.LoadAppConfig()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrate(ctx, conf, os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	app := injectTester(ctx, chi.NewMux(), db.LoadDatabase(ctx, conf))
 	httpServer := server.BuildHttp(
 		server.WithHTTPAddr("0.0.0.0", 8080),
-		server.WithHTTPHandler(router.Route(ctx)),
+		server.WithHTTPHandler(app.Router.Route(ctx)),
 	)
 	httpServer.Start(ctx)
+	app.Scheduler.Start(ctx)
 
 	<-server.AwaitTermination()
 
-	grace, ctx := errgroup.WithContext(ctx)
+	grace, gctx := errgroup.WithContext(ctx)
 
 	grace.Go(func() error {
-		return httpServer.CleanUp(ctx)
+		return httpServer.CleanUp(gctx)
+	})
+	grace.Go(func() error {
+		return app.Scheduler.CleanUp(gctx)
 	})
 
 	if err := grace.Wait(); err != nil {
 		log.GetLogger(ctx).Errorf("application shutdown was not graceful:%+v", err)
 	}
+	// runs have finished, so that no delivery starts past this point
+	if err := app.Webhooks.CleanUp(ctx); err != nil {
+		log.GetLogger(ctx).Errorf("application shutdown was not graceful:%+v", err)
+	}
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thejasn/tester/pkg/config"
+	"github.com/thejasn/tester/pkg/db"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: tester migrate <up | down <version> | status>
+
+  up              applies the pending migrations
+  down <version>  rolls back the migrations past version, 0 dropping every table
+  status          lists the migrations, applied or pending
+`
+
+// migrate runs the migrate command against the database of conf rather
+// than starting the server, returning the exit status
+func migrate(ctx context.Context, conf config.AppConfig, args []string, out, errOut io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(errOut, migrateUsage)
+		return 2
+	}
+	var err error
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		gdb := db.ConnectDatabase(ctx, conf)
+		if err = db.Migrate(ctx, gdb); err == nil {
+			err = printMigrations(ctx, gdb, out)
+		}
+	case args[0] == "down" && len(args) == 2:
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			err = fmt.Errorf("invalid version %q", args[1])
+			break
+		}
+		gdb := db.ConnectDatabase(ctx, conf)
+		if err = db.Rollback(ctx, gdb, version); err == nil {
+			err = printMigrations(ctx, gdb, out)
+		}
+	case args[0] == "status" && len(args) == 1:
+		err = printMigrations(ctx, db.ConnectDatabase(ctx, conf), out)
+	default:
+		fmt.Fprint(errOut, migrateUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "tester migrate: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printMigrations(ctx context.Context, gdb *gorm.DB, out io.Writer) error {
+	statuses, err := db.Status(ctx, gdb)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt.Valid {
+			applied = s.AppliedAt.Time.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
@@ -12,10 +12,12 @@ type AppConfig struct {
 		Debug bool `yaml:"debug" env:"MASTERDATA_LOG_LEVEL"`
 	} `yaml:"logging"`
 	Database struct {
+		Driver                string        `yaml:"driver" env:"DATASOURCE_DRIVER" env-default:"mysql"`
 		URL                   string        `yaml:"url" env:"DATASOURCE_URL"`
 		MaxIdleConnections    int           `yaml:"idleConnections" env:"MAX_IDLE_CONNECTIONS"`
 		MaxOpenConnections    int           `yaml:"openConnections" env:"MAX_OPEN_CONNECTIONS"`
 		MaxConnectionLifetime time.Duration `yaml:"connectionLifetime" env:"MAX_CONNECTION_LIFETIME"`
+		ManualMigrations      bool          `yaml:"manualMigrations" env:"MANUAL_MIGRATIONS"`
 	} `yaml:"database"`
 	Cache struct {
 		Size int           `yaml:"size" env:"CACHE_SIZE"`
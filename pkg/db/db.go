@@ -2,17 +2,54 @@ package db
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
 	"github.com/thejasn/tester/pkg/config"
 	"github.com/thejasn/tester/pkg/log"
 )
 
-func createConnection(ctx context.Context, url string, maxIdleConnections, maxOpenConnections int, maxConnectionLifetime time.Duration) (*gorm.DB, error) {
-	db, err := gorm.Open(mysql.Open(url), &gorm.Config{})
+// The drivers a database can be opened with, named as their gorm dialects
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// dialector returns the gorm dialect of a driver. SQLite foreign keys are
+// off unless asked for on every connection, hence _foreign_keys being added
+// to its url.
+func dialector(driver, url string) (gorm.Dialector, error) {
+	switch driver {
+	case MySQL, "":
+		return mysql.Open(url), nil
+	case Postgres:
+		return postgres.Open(url), nil
+	case SQLite:
+		if !strings.Contains(url, "_foreign_keys=") && !strings.Contains(url, "_fk=") {
+			sep := "?"
+			if strings.Contains(url, "?") {
+				sep = "&"
+			}
+			url += sep + "_foreign_keys=1"
+		}
+		return sqlite.Open(url), nil
+	}
+	return nil, fmt.Errorf("unknown database driver %q, expected %s, %s or %s", driver, MySQL, Postgres, SQLite)
+}
+
+func createConnection(ctx context.Context, driver, url string, maxIdleConnections, maxOpenConnections int, maxConnectionLifetime time.Duration) (*gorm.DB, error) {
+	d, err := dialector(driver, url)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(d, &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
@@ -20,16 +57,22 @@ func createConnection(ctx context.Context, url string, maxIdleConnections, maxOp
 	if err != nil {
 		return nil, err
 	}
+	if driver == SQLite {
+		// sqlite writes through a single connection, which also keeps an
+		// in memory database alive for as long as the service runs
+		maxIdleConnections, maxOpenConnections, maxConnectionLifetime = 1, 1, 0
+	}
 	sqlDB.SetMaxIdleConns(maxIdleConnections)
 	sqlDB.SetMaxOpenConns(maxOpenConnections)
 	sqlDB.SetConnMaxLifetime(maxConnectionLifetime)
 	return db, nil
 }
 
-// LoadDatabase initializes the Database struct with connections
-func LoadDatabase(ctx context.Context, conf config.AppConfig) *gorm.DB {
+// ConnectDatabase opens the database of conf, leaving its schema as it is
+func ConnectDatabase(ctx context.Context, conf config.AppConfig) *gorm.DB {
 	db, err := createConnection(
 		ctx,
+		conf.Database.Driver,
 		conf.Database.URL,
 		conf.Database.MaxIdleConnections,
 		conf.Database.MaxOpenConnections,
@@ -39,3 +82,30 @@ func LoadDatabase(ctx context.Context, conf config.AppConfig) *gorm.DB {
 	}
 	return db
 }
+
+// LoadDatabase initializes the Database struct with connections, applying
+// the pending migrations unless they are left to the migrate command
+func LoadDatabase(ctx context.Context, conf config.AppConfig) *gorm.DB {
+	db := ConnectDatabase(ctx, conf)
+	if !conf.Database.ManualMigrations {
+		if err := Migrate(ctx, db); err != nil {
+			log.GetLogger(ctx).Fatalf("error during migrating the database %v", err.Error())
+		}
+		return db
+	}
+
+	statuses, err := Status(ctx, db)
+	if err != nil {
+		log.GetLogger(ctx).Fatalf("error during reading the migrations of the database %v", err.Error())
+	}
+	pending := 0
+	for _, s := range statuses {
+		if !s.AppliedAt.Valid {
+			pending++
+		}
+	}
+	if pending > 0 {
+		log.GetLogger(ctx).Warnf("%d migrations of the database are pending, apply them with the migrate command", pending)
+	}
+	return db
+}
@@ -0,0 +1,25 @@
+// Package dbtest opens databases for the tests of the repositories
+package dbtest
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/thejasn/tester/pkg/config"
+	"github.com/thejasn/tester/pkg/db"
+)
+
+// Open returns an in memory SQLite database with every migration applied
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+	ctx := context.Background()
+	var conf config.AppConfig
+	conf.Database.Driver, conf.Database.URL = db.SQLite, ":memory:"
+	d := db.ConnectDatabase(ctx, conf)
+	if err := db.Migrate(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
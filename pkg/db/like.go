@@ -0,0 +1,21 @@
+package db
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the wildcards of a term with a backslash, so that it
+// matches literally within a LIKE pattern followed by LikeEscape
+func EscapeLike(term string) string {
+	return likeEscaper.Replace(term)
+}
+
+// LikeEscape returns the clause declaring the backslash as the escape
+// character of a LIKE pattern. MySQL string literals escape the backslash
+// itself, unlike those of PostgreSQL and SQLite.
+func LikeEscape(driver string) string {
+	if driver == MySQL || driver == "" {
+		return `ESCAPE '\\'`
+	}
+	return `ESCAPE '\'`
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestEscapeLike(t *testing.T) {
+	ctx := context.Background()
+	db, err := createConnection(ctx, SQLite, ":memory:", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Exec(`CREATE TABLE item (name TEXT)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"50% off", "500 off", "a_b", "axb", `c:\dir`, `c:dir`} {
+		if err = db.Exec(`INSERT INTO item (name) VALUES (?)`, name).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"50%", []string{"50% off"}},
+		{"_", []string{"a_b"}},
+		{`\`, []string{`c:\dir`}},
+		{"off", []string{"50% off", "500 off"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			var got []string
+			err := db.Table("item").Where("name LIKE ? "+LikeEscape(SQLite), "%"+EscapeLike(tt.term)+"%").Order("name").Pluck("name", &got).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LIKE %q = %q, want %q", tt.term, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/guregu/null"
+	"gorm.io/gorm"
+
+	"github.com/thejasn/tester/pkg/log"
+)
+
+// migration is a versioned change of the schema. Migrations are applied in
+// the order of their versions, each in a transaction of its own along with
+// its schema_version row. MySQL commits DDL as it goes, so that a failed
+// migration there may have to be completed by hand.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *gorm.DB, driver string) error
+	down    func(tx *gorm.DB, driver string) error
+}
+
+// migrations lists the migrations by version. A released migration is
+// never changed, a change to the schema being a new migration.
+var migrations = []migration{
+	{version: 1, name: "create tables", up: createTables, down: dropTables},
+}
+
+// versionTable records the migrations applied to a database
+var versionTable = table{
+	name: "schema_version",
+	columns: []column{
+		{name: "version", kind: integer},
+		{name: "name", kind: varchar, size: 255},
+		{name: "applied_at", kind: datetime, def: now},
+	},
+	primary: []string{"version"},
+}
+
+type appliedMigration struct {
+	Version   int       `gorm:"column:version"`
+	Name      string    `gorm:"column:name"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+// MigrationStatus is a migration of the service, AppliedAt being null while
+// it is pending
+type MigrationStatus struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt null.Time `json:"applied_at"`
+}
+
+// migrationLock identifies the advisory lock held while migrating, by name
+// on MySQL and by key on PostgreSQL
+const (
+	migrationLock    = "tsekaro_schema_migration"
+	migrationLockKey = 7364257230216
+)
+
+// lockTimeout bounds the wait for the migrations of another instance
+const lockTimeout = 10 * time.Minute
+
+// Migrate applies the migrations missing from the database in order. It
+// fails on a database migrated by a newer build, which this one does not
+// know the schema of. Instances starting together migrate one after the
+// other, those coming second finding nothing left to apply.
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	driver := db.Dialector.Name()
+	unlock, err := lock(ctx, db, driver)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	applied, err := appliedMigrations(db, driver)
+	if err != nil {
+		return err
+	}
+	if err = checkKnown(applied); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+		m := m
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx, driver); err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO schema_version (version, name) VALUES (?, ?)", m.version, m.name).Error
+		})
+		if err != nil {
+			return fmt.Errorf("could not apply migration %d %s: %w", m.version, m.name, err)
+		}
+		log.GetLogger(ctx).Infof("applied migration %d %s", m.version, m.name)
+	}
+	return nil
+}
+
+// Rollback reverts the migrations applied past version, latest first.
+// Rolling back to version 0 drops every table of the service.
+func Rollback(ctx context.Context, db *gorm.DB, version int) error {
+	latest := migrations[len(migrations)-1].version
+	if version < 0 || version > latest {
+		return fmt.Errorf("invalid version %d, expected 0 to %d", version, latest)
+	}
+	db = db.WithContext(ctx)
+	driver := db.Dialector.Name()
+	unlock, err := lock(ctx, db, driver)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	applied, err := appliedMigrations(db, driver)
+	if err != nil {
+		return err
+	}
+	if err = checkKnown(applied); err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0 && migrations[i].version > version; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.version]; !ok {
+			continue
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := m.down(tx, driver); err != nil {
+				return err
+			}
+			return tx.Exec("DELETE FROM schema_version WHERE version = ?", m.version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("could not roll back migration %d %s: %w", m.version, m.name, err)
+		}
+		log.GetLogger(ctx).Infof("rolled back migration %d %s", m.version, m.name)
+	}
+	return nil
+}
+
+// Status lists the migrations of the service, applied or pending
+func Status(ctx context.Context, db *gorm.DB) ([]MigrationStatus, error) {
+	db = db.WithContext(ctx)
+	applied, err := appliedMigrations(db, db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err = checkKnown(applied); err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Version: m.version, Name: m.name}
+		if a, ok := applied[m.version]; ok {
+			statuses[i].AppliedAt = null.TimeFrom(a.AppliedAt)
+		}
+	}
+	return statuses, nil
+}
+
+// lock takes the advisory lock serializing the migrations of the instances
+// sharing a database, on a connection of its own held until the returned
+// function releases it. SQLite databases are written through the single
+// connection of one instance and are not locked.
+func lock(ctx context.Context, db *gorm.DB, driver string) (func(), error) {
+	if driver != Postgres && driver != MySQL {
+		return func() {}, nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if sqlDB.Stats().MaxOpenConnections == 1 {
+		// the migrations would wait forever on the connection holding the lock
+		log.GetLogger(ctx).Warnf("migrating without a lock over a single database connection")
+		return func() {}, nil
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not lock the schema: %w", err)
+	}
+	wait, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+	release := func() (sql.Result, error) {
+		return conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+	}
+	if driver == Postgres {
+		_, err = conn.ExecContext(wait, "SELECT pg_advisory_lock($1)", migrationLockKey)
+	} else {
+		var locked null.Int
+		err = conn.QueryRowContext(wait, "SELECT GET_LOCK(?, -1)", migrationLock).Scan(&locked)
+		if err == nil && locked.Int64 != 1 {
+			err = fmt.Errorf("lock %s not granted", migrationLock)
+		}
+		release = func() (sql.Result, error) {
+			return conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLock)
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not lock the schema: %w", err)
+	}
+	return func() {
+		if _, err := release(); err != nil {
+			log.GetLogger(ctx).Warnf("could not unlock the schema: %v", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+// appliedMigrations reads the schema_version table by version, creating it
+// on a database yet to be migrated
+func appliedMigrations(db *gorm.DB, driver string) (map[int]appliedMigration, error) {
+	for _, stmt := range createTable(driver, versionTable) {
+		if err := db.Exec(stmt).Error; err != nil {
+			return nil, fmt.Errorf("could not create table %s: %w", versionTable.name, err)
+		}
+	}
+	var rows []appliedMigration
+	if err := db.Table(versionTable.name).Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not read table %s: %w", versionTable.name, err)
+	}
+	applied := make(map[int]appliedMigration, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+func checkKnown(applied map[int]appliedMigration) error {
+	latest := migrations[len(migrations)-1].version
+	for v := range applied {
+		if v > latest {
+			return fmt.Errorf("the database is at version %d, newer than the latest migration %d of this build", v, latest)
+		}
+	}
+	return nil
+}
+
+// createTables creates the tables of the service, adopting those created
+// by hand from the DDL of the models before migrations existed: the columns
+// they lack, such as the api column of testcase, are added.
+func createTables(tx *gorm.DB, driver string) error {
+	for _, t := range initialTables {
+		for _, stmt := range createTable(driver, t) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("could not create table %s: %w", t.name, err)
+			}
+		}
+		existing, err := columns(tx, driver, t.name)
+		if err != nil {
+			return err
+		}
+		for _, c := range t.columns {
+			if existing[c.name] {
+				continue
+			}
+			stmt := "ALTER TABLE " + quote(driver, t.name) + " ADD COLUMN " + columnDefinition(driver, c)
+			if err = tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("could not add column %s.%s: %w", t.name, c.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func dropTables(tx *gorm.DB, driver string) error {
+	for i := len(initialTables) - 1; i >= 0; i-- {
+		if err := tx.Exec("DROP TABLE IF EXISTS " + quote(driver, initialTables[i].name)).Error; err != nil {
+			return fmt.Errorf("could not drop table %s: %w", initialTables[i].name, err)
+		}
+	}
+	return nil
+}
+
+// columns returns the names of the columns of a table
+func columns(tx *gorm.DB, driver, table string) (map[string]bool, error) {
+	rows, err := tx.Raw("SELECT * FROM " + quote(driver, table) + " WHERE 1 = 0").Rows()
+	if err != nil {
+		return nil, fmt.Errorf("could not read columns of table %s: %w", table, err)
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("could not read columns of table %s: %w", table, err)
+	}
+	existing := make(map[string]bool, len(names))
+	for _, n := range names {
+		existing[n] = true
+	}
+	return existing, nil
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	rmodel "github.com/thejasn/tester/domain/run/model"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+func TestMigrateSQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := createConnection(ctx, SQLite, ":memory:", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// migrating again leaves the schema as it is
+	for i := 0; i < 2; i++ {
+		if err = Migrate(ctx, db); err != nil {
+			t.Fatalf("Migrate() #%d error = %v", i+1, err)
+		}
+	}
+
+	flow := fmodel.Flow{Name: "login", Exports: tmodel.JSON(`{"token":"$.token"}`), Tags: null.StringFrom("smoke")}
+	if err = db.Create(&flow).Error; err != nil {
+		t.Fatalf("create flow error = %v", err)
+	}
+	test := tmodel.Testcase{
+		Name:       "post login",
+		Operation:  "EQUAL",
+		FlowID:     flow.ID,
+		TestCaseID: 1,
+		Host:       "localhost",
+		When:       null.StringFrom("true"),
+		Contract:   true,
+		Headers:    []byte(`{"Accept":"application/json"}`),
+	}
+	if err = db.Create(&test).Error; err != nil {
+		t.Fatalf("create testcase error = %v", err)
+	}
+	run := rmodel.Run{FlowID: null.IntFrom(int64(flow.ID)), Passed: true, DurationMs: 1.5, StartedAt: time.Now(), Report: tmodel.JSON(`{}`)}
+	if err = db.Create(&run).Error; err != nil {
+		t.Fatalf("create run error = %v", err)
+	}
+
+	var gotFlow fmodel.Flow
+	if err = db.First(&gotFlow, flow.ID).Error; err != nil {
+		t.Fatalf("read flow error = %v", err)
+	}
+	if gotFlow.Engine != "linear" || gotFlow.Parallelism != 4 || string(gotFlow.Exports) != `{"token":"$.token"}` || gotFlow.CreatedAt.IsZero() {
+		t.Errorf("read flow = %+v, want the defaults and exports", gotFlow)
+	}
+	var gotTest tmodel.Testcase
+	if err = db.First(&gotTest, test.ID).Error; err != nil {
+		t.Fatalf("read testcase error = %v", err)
+	}
+	if gotTest.API != "REST" || gotTest.Path != "/" || gotTest.Status != 1 || !gotTest.Contract || gotTest.When.String != "true" {
+		t.Errorf("read testcase = %+v, want the defaults, contract and when", gotTest)
+	}
+	var gotRun rmodel.Run
+	if err = db.First(&gotRun, run.ID).Error; err != nil {
+		t.Fatalf("read run error = %v", err)
+	}
+	if !gotRun.Passed || gotRun.Source != "api" || gotRun.DurationMs != 1.5 {
+		t.Errorf("read run = %+v, want passed from the api", gotRun)
+	}
+
+	// constraints hold as on mysql
+	if err = db.Create(&fmodel.Flow{Name: "bad", Engine: "tree"}).Error; err == nil {
+		t.Error("create flow with an unknown engine error = nil")
+	}
+	if err = db.Create(&tmodel.Testcase{Name: "orphan", Operation: "EQUAL", FlowID: flow.ID + 1, TestCaseID: 1, Host: "localhost"}).Error; err == nil {
+		t.Error("create testcase of a missing flow error = nil")
+	}
+	if err = db.Delete(&fmodel.Flow{}, flow.ID).Error; err == nil {
+		t.Error("delete flow with testcases error = nil")
+	}
+}
+
+func TestMigrateAdoptsTables(t *testing.T) {
+	ctx := context.Background()
+	db, err := createConnection(ctx, SQLite, ":memory:", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flow and testcase as created by hand before migrations, testcase
+	// lacking the api column
+	for _, stmt := range []string{
+		"CREATE TABLE flow (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP)",
+		"CREATE TABLE testcase (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, operation TEXT NOT NULL, flow_id INTEGER NOT NULL, test_case_id INTEGER NOT NULL, host TEXT NOT NULL, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP)",
+		"INSERT INTO flow (name) VALUES ('login')",
+		"INSERT INTO testcase (name, operation, flow_id, test_case_id, host) VALUES ('post login', 'EQUAL', 1, 1, 'localhost')",
+	} {
+		if err = db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	var got tmodel.Testcase
+	if err = db.First(&got, 1).Error; err != nil {
+		t.Fatalf("read testcase error = %v", err)
+	}
+	if got.Name != "post login" || got.API != "REST" || got.Phase != "main" {
+		t.Errorf("read testcase = %+v, want the existing row with the defaults of the added columns", got)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	db, err := createConnection(ctx, SQLite, ":memory:", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := Status(ctx, db)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != len(migrations) || statuses[0].AppliedAt.Valid {
+		t.Errorf("Status() before Migrate() = %+v, want every migration pending", statuses)
+	}
+	if err = Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if statuses, _ = Status(ctx, db); !statuses[0].AppliedAt.Valid {
+		t.Errorf("Status() after Migrate() = %+v, want every migration applied", statuses)
+	}
+
+	if err = Rollback(ctx, db, len(migrations)+1); err == nil {
+		t.Error("Rollback() to an unknown version error = nil")
+	}
+	if err = Rollback(ctx, db, 0); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if db.Migrator().HasTable("flow") {
+		t.Error("Rollback() to 0 left table flow")
+	}
+	if statuses, _ = Status(ctx, db); statuses[0].AppliedAt.Valid {
+		t.Errorf("Status() after Rollback() = %+v, want every migration pending", statuses)
+	}
+
+	// a database migrated by a newer build is left alone
+	if err = db.Exec("INSERT INTO schema_version (version, name) VALUES (?, 'future')", len(migrations)+1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err = Migrate(ctx, db); err == nil {
+		t.Error("Migrate() of a newer database error = nil")
+	}
+}
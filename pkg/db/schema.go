@@ -0,0 +1,540 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// kind is the portable type of a column, each driver rendering it with a
+// type of its own
+type kind int
+
+const (
+	serial kind = iota
+	integer
+	smallint
+	boolean
+	double
+	text
+	varchar
+	enum
+	blob
+	mediumblob
+	datetime
+)
+
+// now is the default of a column holding the time a row was inserted
+const now = "now"
+
+// column describes a column portably. def is a default in SQL, now or,
+// for booleans, true or false.
+type column struct {
+	name     string
+	kind     kind
+	size     int
+	values   []string
+	null     bool
+	def      string
+	onUpdate bool
+}
+
+type index struct {
+	name    string
+	columns []string
+	unique  bool
+}
+
+type foreignKey struct {
+	name     string
+	column   string
+	table    string
+	onDelete string
+}
+
+type table struct {
+	name    string
+	columns []column
+	primary []string
+	indexes []index
+	foreign []foreignKey
+}
+
+// initialTables lists the tables created by the first migration, a table
+// coming after those it refers to. Later changes to the schema are
+// migrations of their own, leaving these as released.
+var initialTables = []table{
+	{
+		name: "auth_profile",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: text},
+			{name: "type", kind: enum, values: []string{"basic", "bearer", "apikey", "oauth2_client_credentials", "oauth2_password"}},
+			{name: "username", kind: text, null: true},
+			{name: "password", kind: text, null: true},
+			{name: "token", kind: text, null: true},
+			{name: "key_name", kind: text, null: true},
+			{name: "key_value", kind: text, null: true},
+			{name: "key_in", kind: enum, values: []string{"header", "query"}, def: "'header'"},
+			{name: "token_url", kind: text, null: true},
+			{name: "client_id", kind: text, null: true},
+			{name: "client_secret", kind: text, null: true},
+			{name: "scopes", kind: text, null: true},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "auth_profile_UK", columns: []string{"name"}, unique: true}},
+	},
+	{
+		name: "flow",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: text},
+			{name: "auth_profile_id", kind: integer, null: true},
+			{name: "engine", kind: enum, values: []string{"linear", "dag"}, def: "'linear'"},
+			{name: "parallelism", kind: integer, def: "4"},
+			{name: "timeout", kind: varchar, size: 32, null: true},
+			{name: "budget", kind: varchar, size: 32, null: true},
+			{name: "exports", kind: blob, null: true},
+			{name: "tags", kind: text, null: true},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "flow_UK", columns: []string{"name"}, unique: true}},
+		foreign: []foreignKey{{name: "flow_auth_profile_FK", column: "auth_profile_id", table: "auth_profile"}},
+	},
+	{
+		name: "json_schema",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: text},
+			{name: "description", kind: text, null: true},
+			{name: "schema", kind: blob},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "json_schema_UK", columns: []string{"name"}, unique: true}},
+	},
+	{
+		name: "protoset",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: text},
+			{name: "description", kind: text, null: true},
+			{name: "descriptor", kind: mediumblob},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "protoset_UK", columns: []string{"name"}, unique: true}},
+	},
+	{
+		name: "testcase",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: text},
+			{name: "expected", kind: blob, null: true},
+			{name: "actual", kind: text, null: true},
+			{name: "operation", kind: enum, values: []string{"EQUAL", "NOT_EQUAL"}},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+			{name: "status", kind: smallint, def: "1"},
+			{name: "flow_id", kind: integer},
+			{name: "test_case_id", kind: integer},
+			{name: "scheme", kind: enum, values: []string{"https", "http"}, def: "'http'"},
+			{name: "host", kind: text},
+			{name: "port", kind: integer, def: "8080"},
+			{name: "headers", kind: blob, null: true},
+			{name: "method", kind: text, null: true},
+			{name: "path", kind: text, def: "'/'"},
+			{name: "body", kind: text, null: true},
+			{name: "mapping_test_id", kind: integer, null: true},
+			{name: "api", kind: enum, values: []string{"REST", "GRPC"}, def: "'REST'"},
+			{name: "when", kind: text, null: true},
+			{name: "on_false", kind: enum, values: []string{"skip", "stop"}, def: "'skip'"},
+			{name: "branch", kind: text, null: true},
+			{name: "branch_else", kind: boolean, def: "false"},
+			{name: "loop", kind: text, null: true},
+			{name: "needs", kind: text, null: true},
+			{name: "retry", kind: blob, null: true},
+			{name: "timeout", kind: varchar, size: 32, null: true},
+			{name: "format", kind: enum, values: []string{"json", "text", "binary"}, def: "'json'"},
+			{name: "schema", kind: blob, null: true},
+			{name: "schema_id", kind: integer, null: true},
+			{name: "schema_path", kind: text, null: true},
+			{name: "contract", kind: boolean, def: "false"},
+			{name: "protoset_id", kind: integer, null: true},
+			{name: "snapshot", kind: boolean, def: "false"},
+			{name: "snapshot_ignore", kind: text, null: true},
+			{name: "compare", kind: blob, null: true},
+			{name: "sla", kind: text, null: true},
+			{name: "pre_script", kind: text, null: true},
+			{name: "post_script", kind: text, null: true},
+			{name: "script_timeout", kind: varchar, size: 32, null: true},
+			{name: "script_max_steps", kind: integer, null: true},
+			{name: "assert", kind: text, null: true},
+			{name: "extract", kind: blob, null: true},
+			{name: "subflow_id", kind: integer, null: true},
+			{name: "inputs", kind: blob, null: true},
+			{name: "phase", kind: enum, values: []string{"setup", "main", "teardown"}, def: "'main'"},
+			{name: "tags", kind: text, null: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "testcase_UN", columns: []string{"flow_id", "test_case_id"}, unique: true}},
+		foreign: []foreignKey{
+			{name: "testcase_FK", column: "flow_id", table: "flow"},
+			{name: "testcase_schema_FK", column: "schema_id", table: "json_schema"},
+			{name: "testcase_protoset_FK", column: "protoset_id", table: "protoset"},
+			{name: "testcase_subflow_FK", column: "subflow_id", table: "flow"},
+		},
+	},
+	{
+		name: "run",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "flow_id", kind: integer, null: true},
+			{name: "testcase_id", kind: integer, null: true},
+			{name: "passed", kind: boolean, def: "false"},
+			{name: "message", kind: text, null: true},
+			{name: "duration_ms", kind: double, def: "0"},
+			{name: "started_at", kind: datetime},
+			{name: "report", kind: mediumblob},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "source", kind: varchar, size: 16, def: "'api'"},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "run_flow_IDX", columns: []string{"flow_id", "started_at"}}},
+		foreign: []foreignKey{
+			{name: "run_flow_FK", column: "flow_id", table: "flow", onDelete: "CASCADE"},
+			{name: "run_testcase_FK", column: "testcase_id", table: "testcase", onDelete: "CASCADE"},
+		},
+	},
+	{
+		name: "run_step",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "run_id", kind: integer},
+			{name: "testcase_id", kind: integer, null: true},
+			{name: "step_id", kind: integer},
+			{name: "name", kind: text},
+			{name: "iteration", kind: integer, null: true},
+			{name: "status", kind: varchar, size: 16},
+			{name: "duration_ms", kind: double, def: "0"},
+			{name: "latency_ms", kind: double, null: true},
+			{name: "ttfb_ms", kind: double, null: true},
+			{name: "started_at", kind: datetime, null: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "run_step_testcase_IDX", columns: []string{"testcase_id", "id"}}},
+		foreign: []foreignKey{
+			{name: "run_step_run_FK", column: "run_id", table: "run", onDelete: "CASCADE"},
+			{name: "run_step_testcase_FK", column: "testcase_id", table: "testcase", onDelete: "SET NULL"},
+		},
+	},
+	{
+		name: "snapshot",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "testcase_id", kind: integer},
+			{name: "golden", kind: blob, null: true},
+			{name: "pending", kind: blob, null: true},
+			{name: "approved_at", kind: datetime, null: true},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "snapshot_UN", columns: []string{"testcase_id"}, unique: true}},
+		foreign: []foreignKey{{name: "snapshot_FK", column: "testcase_id", table: "testcase", onDelete: "CASCADE"}},
+	},
+	{
+		name: "suite",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: varchar, size: 255},
+			{name: "tags", kind: text, null: true},
+			{name: "parallel", kind: boolean, def: "false"},
+			{name: "parallelism", kind: integer, def: "4"},
+			{name: "environment", kind: varchar, size: 64, null: true},
+			{name: "variables", kind: blob, null: true},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "suite_UK", columns: []string{"name"}, unique: true}},
+	},
+	{
+		name: "suite_flow",
+		columns: []column{
+			{name: "suite_id", kind: integer},
+			{name: "flow_id", kind: integer},
+			{name: "position", kind: integer},
+		},
+		primary: []string{"suite_id", "flow_id"},
+		foreign: []foreignKey{
+			{name: "suite_flow_suite_FK", column: "suite_id", table: "suite", onDelete: "CASCADE"},
+			{name: "suite_flow_flow_FK", column: "flow_id", table: "flow", onDelete: "CASCADE"},
+		},
+	},
+	{
+		name: "schedule",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: varchar, size: 255},
+			{name: "cron", kind: varchar, size: 255},
+			{name: "target", kind: enum, values: []string{"flow", "suite", "tag"}, def: "'flow'"},
+			{name: "flow_id", kind: integer, null: true},
+			{name: "suite_id", kind: integer, null: true},
+			{name: "tag", kind: varchar, size: 255, null: true},
+			{name: "environment", kind: varchar, size: 64, null: true},
+			{name: "paused", kind: boolean, def: "false"},
+			{name: "last_run_at", kind: datetime, null: true},
+			{name: "last_status", kind: varchar, size: 16, null: true},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "schedule_UK", columns: []string{"name"}, unique: true}},
+		foreign: []foreignKey{
+			{name: "schedule_flow_FK", column: "flow_id", table: "flow", onDelete: "CASCADE"},
+			{name: "schedule_suite_FK", column: "suite_id", table: "suite", onDelete: "CASCADE"},
+		},
+	},
+	{
+		name: "webhook",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: varchar, size: 255},
+			{name: "url", kind: text},
+			{name: "secret", kind: varchar, size: 255, null: true},
+			{name: "event", kind: enum, values: []string{"finished", "changed"}, def: "'finished'"},
+			{name: "flow_id", kind: integer, null: true},
+			{name: "paused", kind: boolean, def: "false"},
+			{name: "created_at", kind: datetime, def: now},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "webhook_UK", columns: []string{"name"}, unique: true}},
+		foreign: []foreignKey{{name: "webhook_flow_FK", column: "flow_id", table: "flow", onDelete: "CASCADE"}},
+	},
+	{
+		name: "webhook_delivery",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "webhook_id", kind: integer},
+			{name: "run_id", kind: integer, null: true},
+			{name: "event", kind: varchar, size: 16},
+			{name: "payload", kind: mediumblob},
+			{name: "attempts", kind: integer, def: "0"},
+			{name: "delivered", kind: boolean, def: "false"},
+			{name: "status_code", kind: integer, null: true},
+			{name: "error", kind: text, null: true},
+			{name: "duration_ms", kind: double, def: "0"},
+			{name: "created_at", kind: datetime, def: now},
+		},
+		primary: []string{"id"},
+		indexes: []index{{name: "webhook_delivery_IDX", columns: []string{"webhook_id", "created_at"}}},
+		foreign: []foreignKey{
+			{name: "webhook_delivery_webhook_FK", column: "webhook_id", table: "webhook", onDelete: "CASCADE"},
+			{name: "webhook_delivery_run_FK", column: "run_id", table: "run", onDelete: "SET NULL"},
+		},
+	},
+}
+
+// createTable renders the statements creating a table if it does not
+// exist. MySQL declares indexes within the table, having no CREATE INDEX IF
+// NOT EXISTS, while the others create them separately.
+func createTable(driver string, t table) []string {
+	quoteAll := func(names []string) string {
+		out := make([]string, len(names))
+		for i, n := range names {
+			out[i] = quote(driver, n)
+			if driver == MySQL && t.kindOf(n) == text {
+				// text is indexed on a prefix only
+				out[i] += "(255)"
+			}
+		}
+		return strings.Join(out, ",")
+	}
+
+	var lines []string
+	for _, c := range t.columns {
+		lines = append(lines, columnDefinition(driver, c))
+	}
+	if t.kindOf("id") != serial || driver != SQLite {
+		lines = append(lines, "PRIMARY KEY ("+quoteAll(t.primary)+")")
+	}
+
+	var after []string
+	for _, i := range t.indexes {
+		switch {
+		case driver == MySQL && i.unique:
+			lines = append(lines, "UNIQUE KEY "+quote(driver, i.name)+" ("+quoteAll(i.columns)+")")
+		case driver == MySQL:
+			lines = append(lines, "KEY "+quote(driver, i.name)+" ("+quoteAll(i.columns)+")")
+		case i.unique:
+			lines = append(lines, "CONSTRAINT "+quote(driver, i.name)+" UNIQUE ("+quoteAll(i.columns)+")")
+		default:
+			after = append(after, "CREATE INDEX IF NOT EXISTS "+quote(driver, i.name)+" ON "+quote(driver, t.name)+" ("+quoteAll(i.columns)+")")
+		}
+	}
+	for _, f := range t.foreign {
+		line := "CONSTRAINT " + quote(driver, f.name) + " FOREIGN KEY (" + quote(driver, f.column) + ") REFERENCES " + quote(driver, f.table) + " (" + quote(driver, "id") + ")"
+		if f.onDelete != "" {
+			line += " ON DELETE " + f.onDelete
+		}
+		lines = append(lines, line)
+	}
+
+	stmt := "CREATE TABLE IF NOT EXISTS " + quote(driver, t.name) + " (\n  " + strings.Join(lines, ",\n  ") + "\n)"
+	if driver == MySQL {
+		stmt += " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci"
+	}
+	return append([]string{stmt}, after...)
+}
+
+// columnDefinition renders a column as declared by CREATE TABLE and ALTER
+// TABLE ADD COLUMN
+func columnDefinition(driver string, c column) string {
+	line := quote(driver, c.name) + " " + columnType(driver, c)
+	if c.kind == serial && driver == SQLite {
+		return line + " PRIMARY KEY AUTOINCREMENT"
+	}
+	if !c.null {
+		line += " NOT NULL"
+	}
+	if c.kind == serial && driver == MySQL {
+		line += " AUTO_INCREMENT"
+	}
+	if c.def != "" {
+		line += " DEFAULT " + defaultValue(driver, c.def)
+	}
+	if c.onUpdate && driver == MySQL {
+		line += " ON UPDATE current_timestamp()"
+	}
+	if c.kind == enum && driver != MySQL {
+		values := make([]string, len(c.values))
+		for i, v := range c.values {
+			values[i] = "'" + v + "'"
+		}
+		line += " CHECK (" + quote(driver, c.name) + " IN (" + strings.Join(values, ",") + "))"
+	}
+	return line
+}
+
+func quote(driver, name string) string {
+	if driver == MySQL {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+func (t table) kindOf(name string) kind {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c.kind
+		}
+	}
+	return -1
+}
+
+func columnType(driver string, c column) string {
+	switch driver {
+	case MySQL:
+		switch c.kind {
+		case serial, integer:
+			return "int(11)"
+		case smallint:
+			return "tinyint(4)"
+		case boolean:
+			return "tinyint(1)"
+		case double:
+			return "double"
+		case text:
+			return "text"
+		case varchar:
+			return "varchar(" + strconv.Itoa(c.size) + ")"
+		case enum:
+			values := make([]string, len(c.values))
+			for i, v := range c.values {
+				values[i] = "'" + v + "'"
+			}
+			return "enum(" + strings.Join(values, ",") + ")"
+		case blob:
+			return "blob"
+		case mediumblob:
+			return "mediumblob"
+		case datetime:
+			return "datetime"
+		}
+	case Postgres:
+		switch c.kind {
+		case serial:
+			return "SERIAL"
+		case integer:
+			return "INTEGER"
+		case smallint:
+			return "SMALLINT"
+		case boolean:
+			return "BOOLEAN"
+		case double:
+			return "DOUBLE PRECISION"
+		case text:
+			return "TEXT"
+		case varchar:
+			return "VARCHAR(" + strconv.Itoa(c.size) + ")"
+		case enum:
+			return "VARCHAR(" + strconv.Itoa(longest(c.values)) + ")"
+		case blob, mediumblob:
+			return "BYTEA"
+		case datetime:
+			return "TIMESTAMP"
+		}
+	case SQLite:
+		switch c.kind {
+		case serial, integer, smallint:
+			return "INTEGER"
+		case boolean:
+			return "BOOLEAN"
+		case double:
+			return "REAL"
+		case text, enum:
+			return "TEXT"
+		case varchar:
+			return "VARCHAR(" + strconv.Itoa(c.size) + ")"
+		case blob, mediumblob:
+			return "BLOB"
+		case datetime:
+			return "DATETIME"
+		}
+	}
+	panic(fmt.Sprintf("no type of kind %d for driver %s", c.kind, driver))
+}
+
+func defaultValue(driver, def string) string {
+	switch {
+	case def == now && driver == MySQL:
+		return "current_timestamp()"
+	case def == now:
+		return "CURRENT_TIMESTAMP"
+	case def == "true" && driver != Postgres:
+		return "1"
+	case def == "false" && driver != Postgres:
+		return "0"
+	case def == "true" || def == "false":
+		return strings.ToUpper(def)
+	}
+	return def
+}
+
+func longest(values []string) int {
+	n := 0
+	for _, v := range values {
+		if len(v) > n {
+			n = len(v)
+		}
+	}
+	return n
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateTable(t *testing.T) {
+	tbl := table{
+		name: "item",
+		columns: []column{
+			{name: "id", kind: serial},
+			{name: "name", kind: text},
+			{name: "kind", kind: enum, values: []string{"a", "bb"}, def: "'a'"},
+			{name: "done", kind: boolean, def: "false"},
+			{name: "updated_at", kind: datetime, def: now, onUpdate: true},
+		},
+		primary: []string{"id"},
+		indexes: []index{
+			{name: "item_UK", columns: []string{"name"}, unique: true},
+			{name: "item_IDX", columns: []string{"kind", "id"}},
+		},
+		foreign: []foreignKey{{name: "item_FK", column: "id", table: "other", onDelete: "CASCADE"}},
+	}
+	tests := []struct {
+		driver string
+		want   []string
+	}{
+		{MySQL, []string{"CREATE TABLE IF NOT EXISTS `item` (\n" +
+			"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+			"  `name` text NOT NULL,\n" +
+			"  `kind` enum('a','bb') NOT NULL DEFAULT 'a',\n" +
+			"  `done` tinyint(1) NOT NULL DEFAULT 0,\n" +
+			"  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),\n" +
+			"  PRIMARY KEY (`id`),\n" +
+			"  UNIQUE KEY `item_UK` (`name`(255)),\n" +
+			"  KEY `item_IDX` (`kind`,`id`),\n" +
+			"  CONSTRAINT `item_FK` FOREIGN KEY (`id`) REFERENCES `other` (`id`) ON DELETE CASCADE\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci"}},
+		{Postgres, []string{`CREATE TABLE IF NOT EXISTS "item" (` + "\n" +
+			`  "id" SERIAL NOT NULL,` + "\n" +
+			`  "name" TEXT NOT NULL,` + "\n" +
+			`  "kind" VARCHAR(2) NOT NULL DEFAULT 'a' CHECK ("kind" IN ('a','bb')),` + "\n" +
+			`  "done" BOOLEAN NOT NULL DEFAULT FALSE,` + "\n" +
+			`  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,` + "\n" +
+			`  PRIMARY KEY ("id"),` + "\n" +
+			`  CONSTRAINT "item_UK" UNIQUE ("name"),` + "\n" +
+			`  CONSTRAINT "item_FK" FOREIGN KEY ("id") REFERENCES "other" ("id") ON DELETE CASCADE` + "\n" +
+			`)`,
+			`CREATE INDEX IF NOT EXISTS "item_IDX" ON "item" ("kind","id")`}},
+		{SQLite, []string{`CREATE TABLE IF NOT EXISTS "item" (` + "\n" +
+			`  "id" INTEGER PRIMARY KEY AUTOINCREMENT,` + "\n" +
+			`  "name" TEXT NOT NULL,` + "\n" +
+			`  "kind" TEXT NOT NULL DEFAULT 'a' CHECK ("kind" IN ('a','bb')),` + "\n" +
+			`  "done" BOOLEAN NOT NULL DEFAULT 0,` + "\n" +
+			`  "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,` + "\n" +
+			`  CONSTRAINT "item_UK" UNIQUE ("name"),` + "\n" +
+			`  CONSTRAINT "item_FK" FOREIGN KEY ("id") REFERENCES "other" ("id") ON DELETE CASCADE` + "\n" +
+			`)`,
+			`CREATE INDEX IF NOT EXISTS "item_IDX" ON "item" ("kind","id")`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			if got := createTable(tt.driver, tbl); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("createTable() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
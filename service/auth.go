@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thejasn/tester/core/auth"
+	"github.com/thejasn/tester/domain/auth/model"
+	"github.com/thejasn/tester/domain/auth/repo"
+)
+
+type AuthProfile interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Profile, int64, error)
+	Get(context.Context, int) (model.Profile, error)
+	Add(context.Context, *model.Profile) (*model.Profile, int64, error)
+	Update(context.Context, int, *model.Profile) (*model.Profile, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewAuthProfileSvc(r repo.Profile) AuthProfile {
+	return authProfile{
+		repo: r,
+	}
+}
+
+type authProfile struct {
+	repo repo.Profile
+}
+
+func (a authProfile) GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Profile, int64, error) {
+	return a.repo.GetAll(ctx, page, pagesize, order)
+}
+
+func (a authProfile) Get(ctx context.Context, id int) (model.Profile, error) {
+	return a.repo.Get(ctx, id)
+}
+
+func (a authProfile) Add(ctx context.Context, m *model.Profile) (*model.Profile, int64, error) {
+	return a.repo.Add(ctx, m)
+}
+
+func (a authProfile) Update(ctx context.Context, id int, m *model.Profile) (*model.Profile, int64, error) {
+	return a.repo.Update(ctx, id, m)
+}
+
+func (a authProfile) Delete(ctx context.Context, id int) (int64, error) {
+	return a.repo.Delete(ctx, id)
+}
+
+// loadAuth fetches the auth profile attached to a flow, nil is returned when
+// the flow is not authenticated
+func loadAuth(ctx context.Context, r profileReader, id int, valid bool) (*auth.Profile, error) {
+	if !valid {
+		return nil, nil
+	}
+	p, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not load auth profile %d as %w", id, err)
+	}
+	a := p.Auth()
+	return &a, nil
+}
@@ -2,44 +2,76 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strconv"
+	"strings"
 
-	"github.com/thejasn/tester/core/asserter"
-	"github.com/thejasn/tester/core/client/grpc"
-	"github.com/thejasn/tester/core/client/rest"
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/cerrors"
 	"github.com/thejasn/tester/core/stream"
-	"github.com/thejasn/tester/core/tester"
+	arepo "github.com/thejasn/tester/domain/auth/repo"
 	"github.com/thejasn/tester/domain/flow/model"
 	"github.com/thejasn/tester/domain/flow/repo"
+	prepo "github.com/thejasn/tester/domain/protoset/repo"
+	rmodel "github.com/thejasn/tester/domain/run/model"
+	hrepo "github.com/thejasn/tester/domain/run/repo"
+	srepo "github.com/thejasn/tester/domain/schema/repo"
+	snaprepo "github.com/thejasn/tester/domain/snapshot/repo"
 	tmodel "github.com/thejasn/tester/domain/testcase/model"
 	trepo "github.com/thejasn/tester/domain/testcase/repo"
 )
 
 type Flow interface {
-	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Flow, int64, error)
+	GetAll(ctx context.Context, page, pagesize int64, order string, filter FlowFilter) ([]*model.Flow, int64, error)
 	Get(context.Context, int) (model.Flow, error)
 	Add(context.Context, *model.Flow) (*model.Flow, int64, error)
 	Update(context.Context, int, *model.Flow) (*model.Flow, int64, error)
 	Delete(context.Context, int) (int64, error)
-	Execute(context.Context, int) (bool, error)
+	Execute(context.Context, int) (stream.Report, error)
+	ExecuteWith(context.Context, int, RunOptions) (stream.Report, error)
+	ExecuteTagged(ctx context.Context, tag string, o RunOptions) (SuiteReport, error)
 }
 
-func NewFlowSvc(r repo.Flow, t trepo.Testcase) Flow {
+// RunOptions tunes a single execution of a flow. Variables are defined in
+// the flow context before any step runs, along with environment when an
+// Environment is given, so that hosts, paths and bodies can refer to them.
+// Source is recorded in the run history, SourceAPI when empty. Progress,
+// when set, is called as each flow of a suite or tagged run finishes.
+type RunOptions struct {
+	Environment string
+	Variables   map[string]interface{}
+	Source      string
+	Progress    func(SuiteFlowResult)
+}
+
+func NewFlowSvc(r repo.Flow, t trepo.Testcase, a arepo.Profile, s srepo.Schema, p prepo.Protoset, n snaprepo.Snapshot, h hrepo.Run, w Webhook) Flow {
 	return flow{
 		repo:  r,
 		trepo: t,
+		arepo: a,
+		srepo: s,
+		prepo: p,
+		nrepo: n,
+		hrepo: h,
+		hooks: w,
 	}
 }
 
 type flow struct {
 	repo  repo.Flow
 	trepo trepo.Testcase
+	arepo arepo.Profile
+	srepo srepo.Schema
+	prepo prepo.Protoset
+	nrepo snaprepo.Snapshot
+	hrepo hrepo.Run
+	hooks Webhook
 }
 
-func (f flow) GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Flow, int64, error) {
-	return f.repo.GetAll(ctx, page, pagesize, order)
+func (f flow) GetAll(ctx context.Context, page, pagesize int64, order string, filter FlowFilter) ([]*model.Flow, int64, error) {
+	if err := validateFlowFilter(&filter); err != nil {
+		return nil, -1, err
+	}
+	return f.repo.GetAll(ctx, page, pagesize, order, filter)
 }
 
 func (f flow) Get(ctx context.Context, id int) (model.Flow, error) {
@@ -47,10 +79,12 @@ func (f flow) Get(ctx context.Context, id int) (model.Flow, error) {
 }
 
 func (f flow) Add(ctx context.Context, m *model.Flow) (*model.Flow, int64, error) {
+	m.Tags = normalizeTags(m.Tags)
 	return f.repo.Add(ctx, m)
 }
 
 func (f flow) Update(ctx context.Context, id int, m *model.Flow) (*model.Flow, int64, error) {
+	m.Tags = normalizeTags(m.Tags)
 	return f.repo.Update(ctx, id, m)
 }
 
@@ -58,52 +92,116 @@ func (f flow) Delete(ctx context.Context, id int) (int64, error) {
 	return f.repo.Delete(ctx, id)
 }
 
-func (f flow) Execute(ctx context.Context, id int) (bool, error) {
+func (f flow) Execute(ctx context.Context, id int) (stream.Report, error) {
+	return f.ExecuteWith(ctx, id, RunOptions{})
+}
+
+func (f flow) ExecuteWith(ctx context.Context, id int, o RunOptions) (stream.Report, error) {
 	fl, err := f.repo.Get(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("could not execute as flow %w", err)
+		return stream.Report{}, fmt.Errorf("could not execute as flow %w", err)
 	}
 
 	tests, _, err := f.trepo.GetAllOrderedWhere(ctx, map[string]interface{}{
 		"flow_id": fl.ID,
 	})
 	if err != nil {
-		return false, fmt.Errorf("could not find flows for id: %d as %w", fl.ID, err)
+		return stream.Report{}, fmt.Errorf("could not find flows for id: %d as %w", fl.ID, err)
+	}
+
+	b := builder{
+		schemas:   newSchemaCache(f.srepo),
+		protosets: newProtosetCache(f.prepo),
+		snapshots: f.nrepo,
+		flows:     f.repo,
+		tests:     f.trepo,
+		auths:     f.arepo,
+		calls:     []int{fl.ID},
+	}
+	report, err := runFlow(ctx, fl, tests, b, o)
+	if err != nil {
+		return stream.Report{}, err
 	}
 
+	if err = recordSnapshots(ctx, f.nrepo, tests, report); err != nil {
+		return report, err
+	}
+	r := rmodel.Run{FlowID: null.IntFrom(int64(fl.ID)), Source: o.Source}
+	if err = recordRun(ctx, f.hrepo, &r, tests, report); err != nil {
+		return report, err
+	}
+	f.hooks.Notify(ctx, r.FlowID, fl.Name, r, report)
+	return report, nil
+}
+
+// ExecuteTagged runs every flow tagged with tag, one after the other and in
+// id order, the same way a suite does
+func (f flow) ExecuteTagged(ctx context.Context, tag string, o RunOptions) (SuiteReport, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return SuiteReport{}, fmt.Errorf("%w: a tag is required", cerrors.ErrBadParams)
+	}
+	if err := validateTag(tag); err != nil {
+		return SuiteReport{}, err
+	}
+	flows, err := f.repo.Find(ctx, FlowFilter{Tag: tag})
+	if err != nil {
+		return SuiteReport{}, fmt.Errorf("could not find flows tagged %q as %w", tag, err)
+	}
+	ids := make([]int, len(flows))
+	for i, fl := range flows {
+		ids[i] = fl.ID
+	}
+	return runFlows(ctx, f, ids, 1, o), nil
+}
+
+// runFlow executes the testcases of a flow, within its timeout and judged
+// against its budget. The variables and environment of o are defined in
+// the context of the flow beforehand.
+func runFlow(ctx context.Context, fl model.Flow, tests []*tmodel.Testcase, b builder, o RunOptions) (stream.Report, error) {
+	var err error
+	if b.profile, err = loadAuth(ctx, b.auths, int(fl.AuthProfileID.Int64), fl.AuthProfileID.Valid); err != nil {
+		return stream.Report{}, err
+	}
+
+	timeout, err := parseTimeout(fl.Timeout, "flow")
+	if err != nil {
+		return stream.Report{}, err
+	}
+	budget, err := parseBudget(fl.Budget)
+	if err != nil {
+		return stream.Report{}, err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	c := stream.NewInMemoryContext()
+	for k, v := range o.Variables {
+		c.Set(k, v)
+	}
+	if o.Environment != "" {
+		c.Set("environment", o.Environment)
+	}
+	report, err := run(ctx, engine(fl, c), c, tests, b)
+	if err != nil {
+		return stream.Report{}, err
+	}
+	report.Budget = budget
+	return report.Evaluate(), nil
+}
+
+// engine picks the execution engine configured for the flow, running
+// within the given context
+func engine(fl model.Flow, c stream.Context) stream.Engine {
+	if fl.Engine == "dag" {
+		d := stream.NewDAGFlow(fl.Parallelism)
+		d.Ctx = c
+		return &d
+	}
 	l := stream.NewLinearFlow()
-	for _, test := range tests {
-		tc, err := f.trepo.Get(ctx, test.ID)
-		if err != nil {
-			return false, fmt.Errorf("could not execute as testcase %w", err)
-		}
-
-		var expected tmodel.Result
-		err = json.Unmarshal(tc.Expected, &expected)
-		if err != nil {
-			return false, fmt.Errorf("corrupt data stored for 'expected' in testcase")
-		}
-
-		switch tc.API {
-		case "REST":
-			cfg := rest.NewRestConfig(ctx, tc.Scheme+"://"+tc.Host+":"+strconv.Itoa(tc.Port))
-			l.Execute(tc.TestCaseID, tester.RestExecutor(ctx, cfg,
-				rest.WithMethod(tc.Method.String),
-				rest.WithBody(tc.Body.String),
-				rest.WithUriPath(tc.Path)), asserter.Assertion{
-				Expected: expected.Data,
-				Actual:   tc.Actual.String,
-				Operator: tc.Operation,
-			})
-		case "GRPC":
-			cfg := grpc.NewConfig(ctx, "something", tc.Host, strconv.Itoa(tc.Port))
-			l.Execute(tc.TestCaseID, tester.GrpcExecutor(ctx, cfg,
-				grpc.WithRequest(tc.Body.String),
-				grpc.WithMethod(tc.Path)), asserter.Assertion{
-				Expected: expected.Data,
-				Actual:   tc.Actual.String,
-				Operator: tc.Operation})
-		}
-	}
-	return true, nil
+	l.Ctx = c
+	return &l
 }
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/stream"
+	amodel "github.com/thejasn/tester/domain/auth/model"
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	pmodel "github.com/thejasn/tester/domain/protoset/model"
+	schmodel "github.com/thejasn/tester/domain/schema/model"
+	snapmodel "github.com/thejasn/tester/domain/snapshot/model"
+	smodel "github.com/thejasn/tester/domain/suite/model"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+// SuiteFile is a suite along with its flows and their testcases, as
+// exported from a server. Flows called as sub-flows are part of the file
+// too. Auth profiles, schemas and protosets are referred to by id, those
+// listed in the file being used by local runs.
+type SuiteFile struct {
+	Suite        smodel.Suite      `json:"suite"`
+	Flows        []SuiteFileFlow   `json:"flows"`
+	AuthProfiles []amodel.Profile  `json:"auth_profiles,omitempty"`
+	Schemas      []schmodel.Schema `json:"schemas,omitempty"`
+	Protosets    []pmodel.Protoset `json:"protosets,omitempty"`
+}
+
+// SuiteFileFlow is a flow of a suite file with its testcases
+type SuiteFileFlow struct {
+	Flow      fmodel.Flow       `json:"flow"`
+	Testcases []tmodel.Testcase `json:"testcases"`
+}
+
+// Local runs a suite file without a database, only the core packages
+// taking part. Nothing is recorded: the run history, snapshots and
+// webhooks are left to the server, snapshot testcases having no golden to
+// be compared against.
+type Local interface {
+	Suite() smodel.Suite
+	Execute(ctx context.Context, o RunOptions) (SuiteReport, error)
+}
+
+// NewLocal reads a suite file, checking that every flow of the suite is
+// part of it. Testcases run in the order of their test_case_id, as on the
+// server.
+func NewLocal(r io.Reader) (Local, error) {
+	var file SuiteFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: invalid suite file: %v", cerrors.ErrInValidation, err)
+	}
+	if err := validateSuite(&file.Suite); err != nil {
+		return nil, err
+	}
+
+	l := local{
+		suite:     file.Suite,
+		flows:     make(flowCatalog, len(file.Flows)),
+		tests:     make(testcaseCatalog, len(file.Flows)),
+		profiles:  make(profileCatalog, len(file.AuthProfiles)),
+		schemas:   make(schemaCatalog, len(file.Schemas)),
+		protosets: make(protosetCatalog, len(file.Protosets)),
+	}
+	for _, f := range file.Flows {
+		if _, ok := l.flows[f.Flow.ID]; ok {
+			return nil, fmt.Errorf("%w: flow %d is listed twice in suite file", cerrors.ErrInValidation, f.Flow.ID)
+		}
+		l.flows[f.Flow.ID] = f.Flow
+		tests := make([]*tmodel.Testcase, len(f.Testcases))
+		for i := range f.Testcases {
+			tests[i] = &f.Testcases[i]
+			tests[i].FlowID = f.Flow.ID
+		}
+		sort.SliceStable(tests, func(i, j int) bool {
+			return tests[i].TestCaseID < tests[j].TestCaseID
+		})
+		l.tests[f.Flow.ID] = tests
+	}
+	for _, id := range file.Suite.FlowIDs {
+		if _, ok := l.flows[id]; !ok {
+			return nil, fmt.Errorf("%w: flow %d of the suite is missing from suite file", cerrors.ErrInValidation, id)
+		}
+	}
+	for _, p := range file.AuthProfiles {
+		l.profiles[p.ID] = p
+	}
+	for _, s := range file.Schemas {
+		l.schemas[s.ID] = s
+	}
+	for _, p := range file.Protosets {
+		l.protosets[p.ID] = p
+	}
+	return l, nil
+}
+
+type local struct {
+	suite     smodel.Suite
+	flows     flowCatalog
+	tests     testcaseCatalog
+	profiles  profileCatalog
+	schemas   schemaCatalog
+	protosets protosetCatalog
+}
+
+func (l local) Suite() smodel.Suite {
+	return l.suite
+}
+
+// Execute runs the flows of the suite as the server does, sharing the
+// environment and variables of the suite unless overridden by o
+func (l local) Execute(ctx context.Context, o RunOptions) (SuiteReport, error) {
+	o, parallelism, err := suiteRun(l.suite, o)
+	if err != nil {
+		return SuiteReport{}, err
+	}
+	report := runFlows(ctx, l, l.suite.FlowIDs, parallelism, o)
+	report.SuiteID = l.suite.ID
+	return report, nil
+}
+
+func (l local) Get(ctx context.Context, id int) (fmodel.Flow, error) {
+	return l.flows.Get(ctx, id)
+}
+
+func (l local) ExecuteWith(ctx context.Context, id int, o RunOptions) (stream.Report, error) {
+	fl, err := l.flows.Get(ctx, id)
+	if err != nil {
+		return stream.Report{}, fmt.Errorf("could not execute as flow %w", err)
+	}
+	b := builder{
+		schemas:   newSchemaCache(l.schemas),
+		protosets: newProtosetCache(l.protosets),
+		snapshots: snapshotCatalog{},
+		flows:     l.flows,
+		tests:     l.tests,
+		auths:     l.profiles,
+		calls:     []int{fl.ID},
+	}
+	return runFlow(ctx, fl, l.tests[fl.ID], b, o)
+}
+
+// The catalogs are the records of a suite file by id, read as the
+// repositories are on the server
+type (
+	flowCatalog     map[int]fmodel.Flow
+	testcaseCatalog map[int][]*tmodel.Testcase
+	profileCatalog  map[int]amodel.Profile
+	schemaCatalog   map[int]schmodel.Schema
+	protosetCatalog map[int]pmodel.Protoset
+	snapshotCatalog struct{}
+)
+
+func (c flowCatalog) Get(_ context.Context, id int) (fmodel.Flow, error) {
+	m, ok := c[id]
+	if !ok {
+		return m, cerrors.ErrNotFound
+	}
+	return m, nil
+}
+
+// GetAllOrderedWhere lists the testcases of a flow, the only condition
+// building steps looks them up by
+func (c testcaseCatalog) GetAllOrderedWhere(_ context.Context, conditions map[string]interface{}) ([]*tmodel.Testcase, int64, error) {
+	id, ok := conditions["flow_id"].(int)
+	if !ok || len(conditions) != 1 {
+		return nil, -1, fmt.Errorf("%w: testcases of a suite file are looked up by flow_id only", cerrors.ErrBadParams)
+	}
+	tests := c[id]
+	return tests, int64(len(tests)), nil
+}
+
+func (c profileCatalog) Get(_ context.Context, id int) (amodel.Profile, error) {
+	m, ok := c[id]
+	if !ok {
+		return m, cerrors.ErrNotFound
+	}
+	return m, nil
+}
+
+func (c schemaCatalog) Get(_ context.Context, id int) (schmodel.Schema, error) {
+	m, ok := c[id]
+	if !ok {
+		return m, cerrors.ErrNotFound
+	}
+	return m, nil
+}
+
+func (c protosetCatalog) Get(_ context.Context, id int) (pmodel.Protoset, error) {
+	m, ok := c[id]
+	if !ok {
+		return m, cerrors.ErrNotFound
+	}
+	return m, nil
+}
+
+func (snapshotCatalog) Get(context.Context, int) (snapmodel.Snapshot, error) {
+	return snapmodel.Snapshot{}, cerrors.ErrNotFound
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/reflect"
+	"github.com/thejasn/tester/domain/protoset/model"
+	"github.com/thejasn/tester/domain/protoset/repo"
+)
+
+type Protoset interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Protoset, int64, error)
+	Get(context.Context, int) (model.Protoset, error)
+	Add(context.Context, *model.Protoset) (*model.Protoset, int64, error)
+	Update(context.Context, int, *model.Protoset) (*model.Protoset, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewProtosetSvc(r repo.Protoset) Protoset {
+	return protoset{
+		repo: r,
+	}
+}
+
+type protoset struct {
+	repo repo.Protoset
+}
+
+func (p protoset) GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Protoset, int64, error) {
+	return p.repo.GetAll(ctx, page, pagesize, order)
+}
+
+func (p protoset) Get(ctx context.Context, id int) (model.Protoset, error) {
+	return p.repo.Get(ctx, id)
+}
+
+// Add stores published protos, the descriptor set must be complete
+func (p protoset) Add(ctx context.Context, m *model.Protoset) (*model.Protoset, int64, error) {
+	if _, err := reflect.DescriptorSourceFromProtoSet(m.Descriptor); err != nil {
+		return nil, -1, fmt.Errorf("%w: %v", cerrors.ErrInValidation, err)
+	}
+	return p.repo.Add(ctx, m)
+}
+
+func (p protoset) Update(ctx context.Context, id int, m *model.Protoset) (*model.Protoset, int64, error) {
+	if len(m.Descriptor) > 0 {
+		if _, err := reflect.DescriptorSourceFromProtoSet(m.Descriptor); err != nil {
+			return nil, -1, fmt.Errorf("%w: %v", cerrors.ErrInValidation, err)
+		}
+	}
+	return p.repo.Update(ctx, id, m)
+}
+
+func (p protoset) Delete(ctx context.Context, id int) (int64, error) {
+	return p.repo.Delete(ctx, id)
+}
+
+// protosetCache parses the published protos used by the testcases of a run
+type protosetCache struct {
+	repo    protosetReader
+	sources map[int]reflect.DescriptorSource
+}
+
+func newProtosetCache(r protosetReader) *protosetCache {
+	return &protosetCache{repo: r, sources: make(map[int]reflect.DescriptorSource)}
+}
+
+func (c *protosetCache) get(ctx context.Context, id int) (reflect.DescriptorSource, error) {
+	if s, ok := c.sources[id]; ok {
+		return s, nil
+	}
+	m, err := c.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not load protoset %d as %w", id, err)
+	}
+	s, err := reflect.DescriptorSourceFromProtoSet(m.Descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protoset %q: %w", m.Name, err)
+	}
+	c.sources[id] = s
+	return s, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/core/export"
+	"github.com/thejasn/tester/core/stream"
+	frepo "github.com/thejasn/tester/domain/flow/repo"
+	"github.com/thejasn/tester/domain/run/model"
+	"github.com/thejasn/tester/domain/run/repo"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+	trepo "github.com/thejasn/tester/domain/testcase/repo"
+)
+
+// DefaultTimingsLimit bounds the timings returned for a testcase
+const DefaultTimingsLimit = 100
+
+// sources of a run, what triggered it
+const (
+	SourceAPI      = "api"
+	SourceSchedule = "schedule"
+	// SourceLocal marks the reports of local runs, which are not recorded
+	SourceLocal = "local"
+)
+
+type Run interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string, flowID int) ([]*model.Run, int64, error)
+	Get(context.Context, int) (model.Run, error)
+	Timings(ctx context.Context, testcaseID int, limit int) ([]*model.RunStep, error)
+	Export(context.Context, int) (export.Run, error)
+}
+
+func NewRunSvc(r repo.Run, f frepo.Flow, t trepo.Testcase) Run {
+	return history{
+		repo:  r,
+		frepo: f,
+		trepo: t,
+	}
+}
+
+// history serves the runs recorded by recordRun
+type history struct {
+	repo  repo.Run
+	frepo frepo.Flow
+	trepo trepo.Testcase
+}
+
+// GetAll lists runs, latest first, restricted to a flow when flowID is set
+func (h history) GetAll(ctx context.Context, page, pagesize int64, order string, flowID int) ([]*model.Run, int64, error) {
+	conditions := map[string]interface{}{}
+	if flowID > 0 {
+		conditions["flow_id"] = flowID
+	}
+	return h.repo.GetAllWhere(ctx, page, pagesize, order, conditions)
+}
+
+func (h history) Get(ctx context.Context, id int) (model.Run, error) {
+	return h.repo.Get(ctx, id)
+}
+
+// Timings returns the latest timings of a testcase, latest first
+func (h history) Timings(ctx context.Context, testcaseID int, limit int) ([]*model.RunStep, error) {
+	if limit <= 0 {
+		limit = DefaultTimingsLimit
+	}
+	return h.repo.Timings(ctx, testcaseID, limit)
+}
+
+// Export returns a run along with its report and the name of the flow or
+// testcase which ran, for rendering
+func (h history) Export(ctx context.Context, id int) (export.Run, error) {
+	m, err := h.repo.Get(ctx, id)
+	if err != nil {
+		return export.Run{}, err
+	}
+	r := export.Run{ID: m.ID, Source: m.Source, Started: m.StartedAt}
+	if err = json.Unmarshal(m.Report, &r.Report); err != nil {
+		return export.Run{}, fmt.Errorf("corrupt data stored for 'report' in run")
+	}
+	if m.FlowID.Valid {
+		if fl, err := h.frepo.Get(ctx, int(m.FlowID.Int64)); err == nil {
+			r.Name = fl.Name
+		}
+	} else if m.TestcaseID.Valid {
+		if tc, err := h.trepo.Get(ctx, int(m.TestcaseID.Int64)); err == nil {
+			r.Name = tc.Name
+		}
+	}
+	return r, nil
+}
+
+// recordRun stores the report of a run in the history along with the
+// timings of its steps. Steps are matched with the testcases they ran by
+// their id within the flow. m is completed with the outcome and the id of
+// the run.
+func recordRun(ctx context.Context, r repo.Run, m *model.Run, tests []*tmodel.Testcase, report stream.Report) error {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if m.Source == "" {
+		m.Source = SourceAPI
+	}
+	m.Passed = report.Passed
+	m.Message = null.NewString(report.Message, report.Message != "")
+	m.DurationMs = milliseconds(report.Duration)
+	m.StartedAt = time.Now().Add(-report.Duration)
+	m.Report = b
+
+	ids := make(map[int]int, len(tests))
+	for _, tc := range tests {
+		ids[tc.TestCaseID] = tc.ID
+	}
+	steps := make([]*model.RunStep, 0, len(report.Steps))
+	for _, s := range report.Steps {
+		rs := &model.RunStep{
+			StepID:     s.ID,
+			Name:       s.Name,
+			Status:     string(s.Status),
+			DurationMs: milliseconds(s.Duration),
+		}
+		if id, ok := ids[s.ID]; ok {
+			rs.TestcaseID = null.IntFrom(int64(id))
+		}
+		if s.Index != nil {
+			rs.Iteration = null.IntFrom(int64(*s.Index))
+		}
+		if s.Latency > 0 {
+			rs.LatencyMs = null.FloatFrom(milliseconds(s.Latency))
+		}
+		if s.FirstByte > 0 {
+			rs.TTFBMs = null.FloatFrom(milliseconds(s.FirstByte))
+		}
+		if !s.Started.IsZero() {
+			rs.StartedAt = null.TimeFrom(s.Started)
+		}
+		steps = append(steps, rs)
+	}
+	if _, _, err = r.Add(ctx, m, steps); err != nil {
+		return fmt.Errorf("could not record run as %w", err)
+	}
+	return nil
+}
+
+// parseBudget parses the budget of a flow, zero means no budget
+func parseBudget(raw null.String) (time.Duration, error) {
+	if raw.String == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw.String)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid 'budget' %q of flow", raw.String)
+	}
+	return d, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/stream"
+	frepo "github.com/thejasn/tester/domain/flow/repo"
+	"github.com/thejasn/tester/domain/schedule/model"
+	"github.com/thejasn/tester/domain/schedule/repo"
+	"github.com/thejasn/tester/pkg/log"
+)
+
+// targets of a schedule
+const (
+	TargetFlow  = "flow"
+	TargetSuite = "suite"
+	TargetTag   = "tag"
+)
+
+// outcomes of a scheduled run, kept as the last status of its schedule
+const (
+	schedulePassed  = "passed"
+	scheduleFailed  = "failed"
+	scheduleSkipped = "skipped"
+	scheduleErrored = "error"
+)
+
+// Schedule manages schedules and runs them. Start loads the schedules
+// which are not paused and runs them whenever their cron expression fires
+// until CleanUp, changes made in between apply right away.
+type Schedule interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Schedule, int64, error)
+	Get(context.Context, int) (model.Schedule, error)
+	Add(context.Context, *model.Schedule) (*model.Schedule, int64, error)
+	Update(context.Context, int, *model.Schedule) (*model.Schedule, int64, error)
+	Delete(context.Context, int) (int64, error)
+	Start(context.Context)
+	CleanUp(context.Context) error
+}
+
+func NewScheduleSvc(r repo.Schedule, f Flow, s Suite, fr frepo.Flow) Schedule {
+	return schedule{
+		repo: r,
+		scheduler: &scheduler{
+			repo:    r,
+			flows:   f,
+			suites:  s,
+			frepo:   fr,
+			cron:    cron.New(),
+			entries: map[int]cron.EntryID{},
+			busy:    map[int]bool{},
+			ctx:     context.Background(),
+		},
+	}
+}
+
+type schedule struct {
+	repo      repo.Schedule
+	scheduler *scheduler
+}
+
+func (s schedule) GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Schedule, int64, error) {
+	return s.repo.GetAll(ctx, page, pagesize, order)
+}
+
+func (s schedule) Get(ctx context.Context, id int) (model.Schedule, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s schedule) Add(ctx context.Context, m *model.Schedule) (*model.Schedule, int64, error) {
+	if err := validateSchedule(m); err != nil {
+		return nil, -1, err
+	}
+	result, rows, err := s.repo.Add(ctx, m)
+	if err != nil {
+		return nil, -1, err
+	}
+	s.scheduler.register(*result)
+	return result, rows, nil
+}
+
+func (s schedule) Update(ctx context.Context, id int, m *model.Schedule) (*model.Schedule, int64, error) {
+	if err := validateSchedule(m); err != nil {
+		return nil, -1, err
+	}
+	result, rows, err := s.repo.Update(ctx, id, m)
+	if err != nil {
+		return nil, -1, err
+	}
+	s.scheduler.register(*result)
+	return result, rows, nil
+}
+
+func (s schedule) Delete(ctx context.Context, id int) (int64, error) {
+	rows, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+	s.scheduler.unregister(id)
+	return rows, nil
+}
+
+func (s schedule) Start(ctx context.Context) {
+	s.scheduler.start(ctx)
+}
+
+func (s schedule) CleanUp(ctx context.Context) error {
+	return s.scheduler.stop(ctx)
+}
+
+// scheduler runs schedules on their cron expression. A run is skipped
+// while any of its flows is still running from an earlier scheduled run,
+// so that slow flows do not pile up.
+type scheduler struct {
+	repo   repo.Schedule
+	flows  Flow
+	suites Suite
+	frepo  frepo.Flow
+	cron   *cron.Cron
+
+	mu      sync.Mutex
+	entries map[int]cron.EntryID
+	busy    map[int]bool
+	ctx     context.Context
+}
+
+func (s *scheduler) start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	schedules, err := s.repo.Active(ctx)
+	if err != nil {
+		log.GetLogger(ctx).Errorf("could not load schedules: %v", err)
+	}
+	for _, m := range schedules {
+		s.register(*m)
+	}
+	s.cron.Start()
+	log.GetLogger(ctx).Infof("started scheduler with %d schedule(s)", len(schedules))
+}
+
+// stop stops firing schedules and waits for the runs in progress
+func (s *scheduler) stop(ctx context.Context) error {
+	log.GetLogger(ctx).Infof("scheduler stopping")
+	select {
+	case <-s.cron.Stop().Done():
+		log.GetLogger(ctx).Infof("scheduler gracefully stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduled runs did not finish:%w", ctx.Err())
+	}
+}
+
+// register (re)schedules m, a paused schedule is only removed
+func (s *scheduler) register(m model.Schedule) {
+	s.unregister(m.ID)
+	if m.Paused {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, err := s.cron.AddFunc(m.Cron, func() { s.fire(m) })
+	if err != nil {
+		log.GetLogger(s.ctx).Errorf("could not schedule %q: %v", m.Name, err)
+		return
+	}
+	s.entries[m.ID] = id
+}
+
+func (s *scheduler) unregister(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[id]; ok {
+		s.cron.Remove(entry)
+		delete(s.entries, id)
+	}
+}
+
+// fire runs the target of a schedule and records its outcome
+func (s *scheduler) fire(m model.Schedule) {
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	logger := log.GetLogger(ctx)
+
+	start := time.Now()
+	status, err := s.run(ctx, m)
+	if err != nil {
+		logger.Errorf("scheduled run of %q failed: %v", m.Name, err)
+	} else {
+		logger.Infof("scheduled run of %q %s in %v", m.Name, status, time.Since(start))
+	}
+	if err = s.repo.Ran(ctx, m.ID, start, status); err != nil {
+		logger.Errorf("could not record scheduled run of %q: %v", m.Name, err)
+	}
+}
+
+func (s *scheduler) run(ctx context.Context, m model.Schedule) (string, error) {
+	ids, err := s.flowIDs(ctx, m)
+	if err != nil {
+		return scheduleErrored, err
+	}
+	if !s.acquire(ids) {
+		return scheduleSkipped, nil
+	}
+	defer s.release(ids)
+
+	o := RunOptions{Environment: m.Environment.String, Source: SourceSchedule}
+	var passed bool
+	switch m.Target {
+	case TargetSuite:
+		var r SuiteReport
+		r, err = s.suites.Execute(ctx, int(m.SuiteID.Int64), o)
+		passed = r.Passed
+	case TargetTag:
+		var r SuiteReport
+		r, err = s.flows.ExecuteTagged(ctx, m.Tag.String, o)
+		passed = r.Passed
+	default:
+		var r stream.Report
+		r, err = s.flows.ExecuteWith(ctx, int(m.FlowID.Int64), o)
+		passed = r.Passed
+	}
+	if err != nil {
+		return scheduleErrored, err
+	}
+	if !passed {
+		return scheduleFailed, nil
+	}
+	return schedulePassed, nil
+}
+
+// flowIDs lists the flows a schedule runs
+func (s *scheduler) flowIDs(ctx context.Context, m model.Schedule) ([]int, error) {
+	switch m.Target {
+	case TargetSuite:
+		st, err := s.suites.Get(ctx, int(m.SuiteID.Int64))
+		if err != nil {
+			return nil, fmt.Errorf("could not find suite %d as %w", m.SuiteID.Int64, err)
+		}
+		return st.FlowIDs, nil
+	case TargetTag:
+		flows, err := s.frepo.Find(ctx, FlowFilter{Tag: m.Tag.String})
+		if err != nil {
+			return nil, fmt.Errorf("could not find flows tagged %q as %w", m.Tag.String, err)
+		}
+		ids := make([]int, len(flows))
+		for i, fl := range flows {
+			ids[i] = fl.ID
+		}
+		return ids, nil
+	}
+	return []int{int(m.FlowID.Int64)}, nil
+}
+
+// acquire marks flows as running unless one of them already is
+func (s *scheduler) acquire(ids []int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if s.busy[id] {
+			return false
+		}
+	}
+	for _, id := range ids {
+		s.busy[id] = true
+	}
+	return true
+}
+
+func (s *scheduler) release(ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.busy, id)
+	}
+}
+
+// validateSchedule checks the cron expression of a schedule and that it
+// names what it runs, fields of the other targets are cleared
+func validateSchedule(m *model.Schedule) error {
+	if _, err := cron.ParseStandard(m.Cron); err != nil {
+		return fmt.Errorf("%w: invalid 'cron' of schedule, %v", cerrors.ErrInValidation, err)
+	}
+	if m.Target == "" {
+		m.Target = TargetFlow
+	}
+	switch m.Target {
+	case TargetFlow:
+		if !m.FlowID.Valid {
+			return fmt.Errorf("%w: 'flow_id' is required by a flow schedule", cerrors.ErrInValidation)
+		}
+		m.SuiteID.Valid, m.Tag.Valid = false, false
+	case TargetSuite:
+		if !m.SuiteID.Valid {
+			return fmt.Errorf("%w: 'suite_id' is required by a suite schedule", cerrors.ErrInValidation)
+		}
+		m.FlowID.Valid, m.Tag.Valid = false, false
+	case TargetTag:
+		if m.Tag.String == "" {
+			return fmt.Errorf("%w: 'tag' is required by a tag schedule", cerrors.ErrInValidation)
+		}
+		if err := validateTag(m.Tag.String); err != nil {
+			return err
+		}
+		m.FlowID.Valid, m.SuiteID.Valid = false, false
+	default:
+		return fmt.Errorf("%w: unsupported 'target' %q of schedule", cerrors.ErrInValidation, m.Target)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/thejasn/tester/core/stream"
+	"github.com/thejasn/tester/domain/schedule/model"
+	"github.com/thejasn/tester/domain/schedule/repo"
+)
+
+// scheduleRepo keeps schedules in memory
+type scheduleRepo struct {
+	repo.Schedule
+	records map[int]model.Schedule
+	ran     []string
+}
+
+func (r *scheduleRepo) Add(_ context.Context, m *model.Schedule) (*model.Schedule, int64, error) {
+	m.ID = len(r.records) + 1
+	r.records[m.ID] = *m
+	return m, 1, nil
+}
+
+func (r *scheduleRepo) Update(_ context.Context, id int, m *model.Schedule) (*model.Schedule, int64, error) {
+	m.ID = id
+	r.records[id] = *m
+	return m, 1, nil
+}
+
+func (r *scheduleRepo) Delete(_ context.Context, id int) (int64, error) {
+	delete(r.records, id)
+	return 1, nil
+}
+
+func (r *scheduleRepo) Ran(_ context.Context, id int, at time.Time, status string) error {
+	r.ran = append(r.ran, status)
+	return nil
+}
+
+// blockingFlows runs flows until released
+type blockingFlows struct {
+	Flow
+	started chan int
+	release chan struct{}
+}
+
+func (f blockingFlows) ExecuteWith(_ context.Context, id int, _ RunOptions) (stream.Report, error) {
+	f.started <- id
+	<-f.release
+	return stream.Report{Passed: true}, nil
+}
+
+func TestScheduleRegistration(t *testing.T) {
+	ctx := context.Background()
+	r := &scheduleRepo{records: map[int]model.Schedule{}}
+	s := NewScheduleSvc(r, nil, nil, nil).(schedule)
+	registered := func() bool {
+		_, ok := s.scheduler.entries[1]
+		return ok && len(s.scheduler.cron.Entries()) == 1
+	}
+
+	m := model.Schedule{Name: "nightly", Cron: "0 2 * * *", FlowID: null.IntFrom(1)}
+	steps := []struct {
+		Name       string
+		Run        func() error
+		Registered bool
+	}{
+		{"add", func() error { _, _, err := s.Add(ctx, &m); return err }, true},
+		{"pause", func() error { p := m; p.Paused = true; _, _, err := s.Update(ctx, 1, &p); return err }, false},
+		{"resume", func() error { _, _, err := s.Update(ctx, 1, &m); return err }, true},
+		{"reschedule", func() error { c := m; c.Cron = "0 3 * * *"; _, _, err := s.Update(ctx, 1, &c); return err }, true},
+		{"invalid cron", func() error { c := m; c.Cron = "never"; _, _, err := s.Update(ctx, 1, &c); return err }, true},
+		{"delete", func() error { _, err := s.Delete(ctx, 1); return err }, false},
+	}
+	for _, st := range steps {
+		err := st.Run()
+		if st.Name == "invalid cron" {
+			if err == nil {
+				t.Fatalf("%s: expected an error", st.Name)
+			}
+		} else if err != nil {
+			t.Fatalf("%s: %v", st.Name, err)
+		}
+		if registered() != st.Registered {
+			t.Fatalf("%s: registered %v, want %v", st.Name, registered(), st.Registered)
+		}
+	}
+}
+
+func TestScheduleSkipsOverlappingRun(t *testing.T) {
+	ctx := context.Background()
+	r := &scheduleRepo{records: map[int]model.Schedule{}}
+	flows := blockingFlows{started: make(chan int, 2), release: make(chan struct{})}
+	s := NewScheduleSvc(r, flows, nil, nil).(schedule).scheduler
+	m := model.Schedule{ID: 1, Name: "nightly", Target: TargetFlow, FlowID: null.IntFrom(7)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.fire(m)
+	}()
+	<-flows.started
+
+	if status, err := s.run(ctx, m); err != nil || status != scheduleSkipped {
+		t.Fatalf("overlapping run: %s %v", status, err)
+	}
+	close(flows.release)
+	<-done
+
+	if status, err := s.run(ctx, m); err != nil || status != schedulePassed {
+		t.Fatalf("run after release: %s %v", status, err)
+	}
+	if len(r.ran) != 1 || r.ran[0] != schedulePassed {
+		t.Fatalf("bad recorded runs: %#v", r.ran)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/domain/schema/model"
+	"github.com/thejasn/tester/domain/schema/repo"
+)
+
+type Schema interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Schema, int64, error)
+	Get(context.Context, int) (model.Schema, error)
+	Add(context.Context, *model.Schema) (*model.Schema, int64, error)
+	Update(context.Context, int, *model.Schema) (*model.Schema, int64, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewSchemaSvc(r repo.Schema) Schema {
+	return schema{
+		repo: r,
+	}
+}
+
+type schema struct {
+	repo repo.Schema
+}
+
+func (s schema) GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Schema, int64, error) {
+	return s.repo.GetAll(ctx, page, pagesize, order)
+}
+
+func (s schema) Get(ctx context.Context, id int) (model.Schema, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Add stores a schema of the library, it must compile
+func (s schema) Add(ctx context.Context, m *model.Schema) (*model.Schema, int64, error) {
+	if _, err := asserter.CompileSchema(m.Name, m.Schema); err != nil {
+		return nil, -1, fmt.Errorf("%w: %v", cerrors.ErrInValidation, err)
+	}
+	return s.repo.Add(ctx, m)
+}
+
+func (s schema) Update(ctx context.Context, id int, m *model.Schema) (*model.Schema, int64, error) {
+	if len(m.Schema) > 0 {
+		if _, err := asserter.CompileSchema(m.Name, m.Schema); err != nil {
+			return nil, -1, fmt.Errorf("%w: %v", cerrors.ErrInValidation, err)
+		}
+	}
+	return s.repo.Update(ctx, id, m)
+}
+
+func (s schema) Delete(ctx context.Context, id int) (int64, error) {
+	return s.repo.Delete(ctx, id)
+}
+
+// schemaCache compiles the schemas used by the testcases of a run, shared
+// schemas are loaded from the library once
+type schemaCache struct {
+	repo    schemaReader
+	library map[int]*jsonschema.Schema
+}
+
+func newSchemaCache(r schemaReader) *schemaCache {
+	return &schemaCache{repo: r, library: make(map[int]*jsonschema.Schema)}
+}
+
+// get compiles the schema stored with a testcase or, when id is valid, the
+// one of the library
+func (c *schemaCache) get(ctx context.Context, inline []byte, id int, valid bool) (*jsonschema.Schema, error) {
+	if !valid {
+		s, err := asserter.CompileSchema("testcase.json", inline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'schema' in testcase: %w", err)
+		}
+		return s, nil
+	}
+	if s, ok := c.library[id]; ok {
+		return s, nil
+	}
+	m, err := c.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not load schema %d as %w", id, err)
+	}
+	s, err := asserter.CompileSchema(m.Name, m.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %q: %w", m.Name, err)
+	}
+	c.library[id] = s
+	return s, nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/stream"
+	"github.com/thejasn/tester/domain/snapshot/model"
+	"github.com/thejasn/tester/domain/snapshot/repo"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+	trepo "github.com/thejasn/tester/domain/testcase/repo"
+)
+
+// SnapshotReview is the golden snapshot of a testcase next to the pending
+// response, Diff lists where the pending response departs from the golden
+type SnapshotReview struct {
+	model.Snapshot
+	Diff []asserter.Violation `json:"diff"`
+}
+
+type Snapshot interface {
+	Get(context.Context, int) (SnapshotReview, error)
+	Accept(context.Context, int) (model.Snapshot, error)
+	Delete(context.Context, int) (int64, error)
+}
+
+func NewSnapshotSvc(r repo.Snapshot, t trepo.Testcase) Snapshot {
+	return snapshot{
+		repo:  r,
+		trepo: t,
+	}
+}
+
+type snapshot struct {
+	repo  repo.Snapshot
+	trepo trepo.Testcase
+}
+
+// Get returns the snapshot of a testcase for review
+func (s snapshot) Get(ctx context.Context, testcaseID int) (SnapshotReview, error) {
+	tc, err := s.trepo.Get(ctx, testcaseID)
+	if err != nil {
+		return SnapshotReview{}, err
+	}
+	m, err := s.repo.Get(ctx, testcaseID)
+	if err != nil {
+		return SnapshotReview{}, err
+	}
+	review := SnapshotReview{Snapshot: m}
+	if len(m.Golden) > 0 && len(m.Pending) > 0 {
+		golden, pending, err := decodeSnapshots(m.Golden, m.Pending)
+		if err != nil {
+			return SnapshotReview{}, err
+		}
+		opts, err := compareOptions(tc, true)
+		if err != nil {
+			return SnapshotReview{}, err
+		}
+		review.Diff = asserter.Diff(golden, pending, opts)
+	}
+	return review, nil
+}
+
+// Accept approves the pending response of a testcase as its new golden
+// snapshot
+func (s snapshot) Accept(ctx context.Context, testcaseID int) (model.Snapshot, error) {
+	m, err := s.repo.Get(ctx, testcaseID)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	if len(m.Pending) == 0 {
+		return model.Snapshot{}, fmt.Errorf("%w: no pending snapshot for testcase %d", cerrors.ErrInvalid, testcaseID)
+	}
+	m.Golden, m.Pending = m.Pending, nil
+	m.ApprovedAt = null.TimeFrom(time.Now())
+	if _, _, err = s.repo.Save(ctx, &m); err != nil {
+		return model.Snapshot{}, err
+	}
+	return m, nil
+}
+
+// Delete discards the snapshot of a testcase, the response of the next
+// passing run being kept pending until accepted
+func (s snapshot) Delete(ctx context.Context, testcaseID int) (int64, error) {
+	return s.repo.Delete(ctx, testcaseID)
+}
+
+// loadGolden returns the golden snapshot a testcase is compared against
+func loadGolden(ctx context.Context, r snapshotReader, tc tmodel.Testcase) (asserter.Golden, error) {
+	var g asserter.Golden
+	m, err := r.Get(ctx, tc.ID)
+	if errors.Is(err, cerrors.ErrNotFound) {
+		return g, nil
+	}
+	if err != nil || len(m.Golden) == 0 {
+		return g, err
+	}
+	if err = json.Unmarshal(m.Golden, &g.Body); err != nil {
+		return g, fmt.Errorf("corrupt data stored for snapshot of testcase %d", tc.ID)
+	}
+	g.Recorded = true
+	return g, nil
+}
+
+// recordSnapshots stores the responses of the snapshot testcases of a run.
+// A response differing from the golden snapshot, or a passing one of a
+// testcase without a golden, is kept pending; only Accept makes it golden.
+func recordSnapshots(ctx context.Context, r repo.Snapshot, tests []*tmodel.Testcase, report stream.Report) error {
+	responses := make(map[int]string)
+	passed := make(map[int]bool)
+	for _, s := range report.Steps {
+		if s.Response != "" {
+			responses[s.ID], passed[s.ID] = s.Response, s.Status == stream.Passed
+		}
+	}
+	for _, tc := range tests {
+		response, ok := responses[tc.TestCaseID]
+		if !tc.Snapshot || !ok {
+			continue
+		}
+		body := []byte(response)
+		if !json.Valid(body) {
+			body, _ = json.Marshal(response)
+		}
+
+		m, err := r.Get(ctx, tc.ID)
+		if err != nil && !errors.Is(err, cerrors.ErrNotFound) {
+			return err
+		}
+		m.TestcaseID = tc.ID
+		switch {
+		case len(m.Golden) == 0:
+			if !passed[tc.TestCaseID] || bytes.Equal(m.Pending, body) {
+				continue
+			}
+			m.Pending = body
+		default:
+			golden, actual, err := decodeSnapshots(m.Golden, body)
+			if err != nil {
+				return err
+			}
+			opts, err := compareOptions(*tc, true)
+			if err != nil {
+				return err
+			}
+			if len(asserter.Diff(golden, actual, opts)) == 0 {
+				if len(m.Pending) == 0 {
+					continue
+				}
+				m.Pending = nil
+			} else {
+				m.Pending = body
+			}
+		}
+		if _, _, err = r.Save(ctx, &m); err != nil {
+			return fmt.Errorf("could not record snapshot of testcase %d as %w", tc.ID, err)
+		}
+	}
+	return nil
+}
+
+func decodeSnapshots(golden, other tmodel.JSON) (g interface{}, o interface{}, err error) {
+	if err = json.Unmarshal(golden, &g); err != nil {
+		return nil, nil, fmt.Errorf("corrupt data stored for snapshot")
+	}
+	if err = json.Unmarshal(other, &o); err != nil {
+		return nil, nil, fmt.Errorf("corrupt data stored for snapshot")
+	}
+	return g, o, nil
+}
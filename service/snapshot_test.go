@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/stream"
+	"github.com/thejasn/tester/domain/snapshot/model"
+	"github.com/thejasn/tester/domain/snapshot/repo"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+// snapshotRepo keeps snapshots in memory, counting saves
+type snapshotRepo struct {
+	repo.Snapshot
+	records map[int]model.Snapshot
+	saves   int
+}
+
+func (r *snapshotRepo) Get(_ context.Context, id int) (model.Snapshot, error) {
+	m, ok := r.records[id]
+	if !ok {
+		return m, cerrors.ErrNotFound
+	}
+	return m, nil
+}
+
+func (r *snapshotRepo) Save(_ context.Context, m *model.Snapshot) (*model.Snapshot, int64, error) {
+	r.saves++
+	r.records[m.TestcaseID] = *m
+	return m, 1, nil
+}
+
+func TestRecordSnapshots(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Stored   *model.Snapshot
+		Response string
+		Status   stream.Status
+		Golden   string
+		Pending  string
+		Saved    bool
+	}{
+		{"first passing run kept pending", nil, `{"a":1}`, stream.Passed, "", `{"a":1}`, true},
+		{"first failing run ignored", nil, `{"a":1}`, stream.Failed, "", "", false},
+		{"same pending not saved", &model.Snapshot{Pending: tmodel.JSON(`{"a":1}`)}, `{"a":1}`, stream.Passed, "", `{"a":1}`, false},
+		{"new pending replaces old", &model.Snapshot{Pending: tmodel.JSON(`{"a":1}`)}, `{"a":2}`, stream.Passed, "", `{"a":2}`, true},
+		{"matching golden", &model.Snapshot{Golden: tmodel.JSON(`{"a":1}`)}, `{"a": 1}`, stream.Passed, `{"a":1}`, "", false},
+		{"differing from golden", &model.Snapshot{Golden: tmodel.JSON(`{"a":1}`)}, `{"a":2}`, stream.Failed, `{"a":1}`, `{"a":2}`, true},
+		{"back to golden clears pending", &model.Snapshot{Golden: tmodel.JSON(`{"a":1}`), Pending: tmodel.JSON(`{"a":2}`)}, `{"a":1}`, stream.Passed, `{"a":1}`, "", true},
+		{"plain text response", nil, `ok`, stream.Passed, "", `"ok"`, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := &snapshotRepo{records: map[int]model.Snapshot{}}
+			if tc.Stored != nil {
+				tc.Stored.TestcaseID = 3
+				r.records[3] = *tc.Stored
+			}
+			tests := []*tmodel.Testcase{
+				{ID: 3, TestCaseID: 1, Snapshot: true},
+				{ID: 4, TestCaseID: 2},
+			}
+			report := stream.Report{Steps: []stream.StepResult{
+				{ID: 1, Status: tc.Status, Response: tc.Response},
+				{ID: 2, Status: stream.Passed, Response: `{"b":1}`},
+			}}
+
+			if err := recordSnapshots(context.Background(), r, tests, report); err != nil {
+				t.Fatal(err)
+			}
+			if (r.saves > 0) != tc.Saved {
+				t.Fatalf("saved %d times, want saved %v", r.saves, tc.Saved)
+			}
+			m := r.records[3]
+			if string(m.Golden) != tc.Golden || string(m.Pending) != tc.Pending {
+				t.Fatalf("golden %s pending %s, want %s and %s", m.Golden, m.Pending, tc.Golden, tc.Pending)
+			}
+			if _, ok := r.records[4]; ok {
+				t.Fatal("recorded a testcase without snapshot")
+			}
+		})
+	}
+}
+
+func TestAcceptSnapshot(t *testing.T) {
+	r := &snapshotRepo{records: map[int]model.Snapshot{}}
+	s := NewSnapshotSvc(r, nil)
+	ctx := context.Background()
+
+	if _, err := s.Accept(ctx, 3); err == nil {
+		t.Fatal("accepted a missing snapshot")
+	}
+	r.records[3] = model.Snapshot{TestcaseID: 3, Golden: tmodel.JSON(`{"a":1}`)}
+	if _, err := s.Accept(ctx, 3); err == nil {
+		t.Fatal("accepted a snapshot without pending response")
+	}
+
+	r.records[3] = model.Snapshot{TestcaseID: 3, Pending: tmodel.JSON(`{"a":2}`)}
+	m, err := s.Accept(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Golden) != `{"a":2}` || m.Pending != nil || !m.ApprovedAt.Valid {
+		t.Fatalf("bad accepted snapshot %#v", m)
+	}
+	if string(r.records[3].Golden) != `{"a":2}` {
+		t.Fatalf("accepted snapshot not saved: %#v", r.records[3])
+	}
+}
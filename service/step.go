@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/core/asserter"
+	"github.com/thejasn/tester/core/auth"
+	"github.com/thejasn/tester/core/client"
+	"github.com/thejasn/tester/core/client/grpc"
+	"github.com/thejasn/tester/core/client/rest"
+	"github.com/thejasn/tester/core/expr"
+	"github.com/thejasn/tester/core/reflect"
+	"github.com/thejasn/tester/core/script"
+	"github.com/thejasn/tester/core/stream"
+	"github.com/thejasn/tester/core/tester"
+	amodel "github.com/thejasn/tester/domain/auth/model"
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	pmodel "github.com/thejasn/tester/domain/protoset/model"
+	schmodel "github.com/thejasn/tester/domain/schema/model"
+	snapmodel "github.com/thejasn/tester/domain/snapshot/model"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+// The readers are what building the steps of a run looks up, the
+// repositories providing them on the server and the suite file on local
+// runs.
+type (
+	flowReader interface {
+		Get(context.Context, int) (fmodel.Flow, error)
+	}
+	testcaseReader interface {
+		GetAllOrderedWhere(context.Context, map[string]interface{}) ([]*tmodel.Testcase, int64, error)
+	}
+	profileReader interface {
+		Get(context.Context, int) (amodel.Profile, error)
+	}
+	schemaReader interface {
+		Get(context.Context, int) (schmodel.Schema, error)
+	}
+	protosetReader interface {
+		Get(context.Context, int) (pmodel.Protoset, error)
+	}
+	snapshotReader interface {
+		Get(context.Context, int) (snapmodel.Snapshot, error)
+	}
+)
+
+// builder converts testcases into steps, resolving what they refer to
+// outside of their own record. The auth profile is optional.
+type builder struct {
+	profile   *auth.Profile
+	schemas   *schemaCache
+	protosets *protosetCache
+	snapshots snapshotReader
+	flows     flowReader
+	tests     testcaseReader
+	auths     profileReader
+	// calls lists the flows being built, the outermost first
+	calls []int
+}
+
+// newStep converts a testcase record into a step executable by the stream
+// engines
+func (b builder) newStep(ctx context.Context, tc tmodel.Testcase) (stream.Step, error) {
+	var err error
+	step := stream.Step{
+		ID:        tc.TestCaseID,
+		Name:      tc.Name,
+		Needs:     splitList(tc.Needs.String),
+		When:      tc.When.String,
+		Otherwise: stream.Outcome(tc.OnFalse),
+	}
+	if !validOutcome(step.Otherwise) {
+		return stream.Step{}, fmt.Errorf("unsupported on_false %q for testcase %d", tc.OnFalse, tc.ID)
+	}
+
+	compare, err := compareOptions(tc, false)
+	if err != nil {
+		return stream.Step{}, err
+	}
+	if len(tc.Expected) > 0 {
+		var expected tmodel.Result
+		if err = json.Unmarshal(tc.Expected, &expected); err != nil {
+			return stream.Step{}, fmt.Errorf("corrupt data stored for 'expected' in testcase")
+		}
+		step.Assertions = append(step.Assertions, asserter.Assertion{
+			Expected: expected.Data,
+			Actual:   tc.Actual.String,
+			Operator: tc.Operation,
+			Options:  compare,
+		})
+	}
+	if len(tc.Schema) > 0 || tc.SchemaID.Valid {
+		s, err := b.schemas.get(ctx, tc.Schema, int(tc.SchemaID.Int64), tc.SchemaID.Valid)
+		if err != nil {
+			return stream.Step{}, err
+		}
+		step.Assertions = append(step.Assertions, asserter.Assertion{
+			Expected: s,
+			Actual:   tc.SchemaPath.String,
+			Operator: asserter.Schema,
+		})
+	}
+	if tc.Snapshot {
+		if tc.Format != "" && reflect.Format(tc.Format) != reflect.FormatJSON {
+			return stream.Step{}, fmt.Errorf("snapshot of testcase %d requires the json format", tc.ID)
+		}
+		g, err := loadGolden(ctx, b.snapshots, tc)
+		if err != nil {
+			return stream.Step{}, err
+		}
+		opts, err := compareOptions(tc, true)
+		if err != nil {
+			return stream.Step{}, err
+		}
+		step.Assertions = append(step.Assertions, asserter.Assertion{
+			Expected: g,
+			Actual:   "",
+			Operator: asserter.Snapshot,
+			Options:  opts,
+		})
+	}
+
+	if tc.Assert.String != "" {
+		e, err := expr.Compile(tc.Assert.String)
+		if err != nil {
+			return stream.Step{}, fmt.Errorf("invalid 'assert' of testcase %d: %v", tc.ID, err)
+		}
+		step.Assertions = append(step.Assertions, asserter.Assertion{
+			Expected: e,
+			Operator: asserter.Expression,
+		})
+	}
+	if step.Extract, err = expressions(tc.Extract, "extract", "testcase"); err != nil {
+		return stream.Step{}, err
+	}
+
+	if len(tc.Retry) > 0 {
+		if step.Retry, err = newRetry(tc.Retry); err != nil {
+			return stream.Step{}, err
+		}
+	}
+	if step.Timeout, err = parseTimeout(tc.Timeout, "testcase"); err != nil {
+		return stream.Step{}, err
+	}
+	step.SLA = tc.SLA.String
+
+	format := reflect.Format(tc.Format)
+	switch format {
+	case "", reflect.FormatJSON:
+	case reflect.FormatText, reflect.FormatBinary:
+		if tc.API != "GRPC" {
+			return stream.Step{}, fmt.Errorf("format %q of testcase %d is only supported for GRPC", format, tc.ID)
+		}
+	default:
+		return stream.Step{}, fmt.Errorf("unsupported format %q for testcase %d", format, tc.ID)
+	}
+
+	var published reflect.DescriptorSource
+	if tc.Contract && tc.API != "GRPC" {
+		return stream.Step{}, fmt.Errorf("contract validation of testcase %d is only supported for GRPC", tc.ID)
+	}
+	if tc.ProtosetID.Valid {
+		if published, err = b.protosets.get(ctx, int(tc.ProtosetID.Int64)); err != nil {
+			return stream.Step{}, err
+		}
+	}
+
+	pre, post, err := compileScripts(tc)
+	if err != nil {
+		return stream.Step{}, err
+	}
+	if post != nil {
+		step.Verify = func(ctx context.Context, c stream.Context, status int, body interface{}) (asserter.Result, error) {
+			return post.After(ctx, c, status, body)
+		}
+	}
+
+	if tc.SubflowID.Valid {
+		if pre != nil {
+			return stream.Step{}, fmt.Errorf("pre_script of testcase %d cannot run before a sub-flow", tc.ID)
+		}
+		if step.Call, err = b.newCall(ctx, tc); err != nil {
+			return stream.Step{}, err
+		}
+		return step, nil
+	}
+
+	switch tc.API {
+	case "REST":
+		step.Exec = func(c stream.Context) tester.Executor {
+			req := script.Request{Method: tc.Method.String, Path: c.Render(tc.Path), Body: c.Render(tc.Body.String)}
+			return hooked(pre, c, req, func(req script.Request) tester.Executor {
+				cfg := rest.NewRestConfig(tc.Scheme + "://" + c.Render(tc.Host) + ":" + strconv.Itoa(tc.Port))
+				opts := []client.RunnerOpts{
+					rest.WithMethod(req.Method),
+					rest.WithBody(req.Body),
+					rest.WithUriPath(req.Path),
+					rest.WithHeaders(req.Headers),
+				}
+				if b.profile != nil {
+					opts = append(opts, rest.WithAuth(*b.profile))
+				}
+				return tester.RestExecutor(cfg, opts...)
+			})
+		}
+	case "GRPC":
+		step.Exec = func(c stream.Context) tester.Executor {
+			req := script.Request{Path: c.Render(tc.Path), Body: c.Render(tc.Body.String)}
+			return hooked(pre, c, req, func(req script.Request) tester.Executor {
+				cfg := grpc.NewConfig("something", c.Render(tc.Host), strconv.Itoa(tc.Port))
+				opts := []client.RunnerOpts{
+					grpc.WithRequest(req.Body),
+					grpc.WithMethod(req.Path),
+					grpc.WithFormat(format),
+					grpc.WithHeaders(req.Headers),
+				}
+				if b.profile != nil {
+					opts = append(opts, grpc.WithAuth(*b.profile))
+				}
+				if tc.Contract {
+					opts = append(opts, grpc.WithContract(published))
+				}
+				return tester.GrpcExecutor(cfg, opts...)
+			})
+		}
+	default:
+		return stream.Step{}, fmt.Errorf("unsupported api %q for testcase %d", tc.API, tc.ID)
+	}
+	return step, nil
+}
+
+// compileScripts compiles the pre-request hook and post-response script of
+// a testcase, either is nil when not set
+func compileScripts(tc tmodel.Testcase) (pre, post *script.Script, err error) {
+	if tc.ScriptMaxSteps.Int64 < 0 {
+		return nil, nil, fmt.Errorf("invalid 'script_max_steps' %d of testcase", tc.ScriptMaxSteps.Int64)
+	}
+	limits := script.Limits{MaxSteps: uint64(tc.ScriptMaxSteps.Int64)}
+	if limits.Timeout, err = parseTimeout(tc.ScriptTimeout, "testcase script"); err != nil {
+		return nil, nil, err
+	}
+	if tc.PreScript.String != "" {
+		if pre, err = script.Compile(tc.Name+" pre_script", tc.PreScript.String, limits); err != nil {
+			return nil, nil, err
+		}
+	}
+	if tc.PostScript.String != "" {
+		if post, err = script.Compile(tc.Name+" post_script", tc.PostScript.String, limits); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pre, post, nil
+}
+
+// hooked runs the pre-request hook, if any, right before the request is
+// built and sent by exec
+func hooked(pre *script.Script, c stream.Context, req script.Request, exec func(script.Request) tester.Executor) tester.Executor {
+	if pre == nil {
+		return exec(req)
+	}
+	return func(ctx context.Context) (string, client.Response, error) {
+		if err := pre.Before(ctx, c, &req); err != nil {
+			return "", client.Response{}, err
+		}
+		return exec(req)(ctx)
+	}
+}
+
+// run executes the testcases phase by phase, the main ones with the given
+// engine which runs within c
+func run(ctx context.Context, e stream.Engine, c stream.Context, tests []*tmodel.Testcase, b builder) (stream.Report, error) {
+	p, err := b.newPhases(ctx, tests)
+	if err != nil {
+		return stream.Report{}, err
+	}
+	return p.Run(ctx, c, e), nil
+}
+
+// validOutcome tells whether o is an outcome of an unmet condition, empty
+// being the default, skip
+func validOutcome(o stream.Outcome) bool {
+	switch o {
+	case "", stream.Skip, stream.Stop:
+		return true
+	}
+	return false
+}
+
+// newPhases splits the testcases of a flow by phase, keeping their order
+// within each phase
+func (b builder) newPhases(ctx context.Context, tests []*tmodel.Testcase) (stream.Phases, error) {
+	var setup, main, teardown []*tmodel.Testcase
+	for _, tc := range tests {
+		switch stream.Phase(tc.Phase) {
+		case stream.Setup:
+			setup = append(setup, tc)
+		case "", stream.Main:
+			main = append(main, tc)
+		case stream.Teardown:
+			teardown = append(teardown, tc)
+		default:
+			return stream.Phases{}, fmt.Errorf("unsupported phase %q for testcase %d", tc.Phase, tc.ID)
+		}
+	}
+	var p stream.Phases
+	var err error
+	if p.Setup, err = b.newNodes(ctx, setup); err != nil {
+		return stream.Phases{}, err
+	}
+	if p.Main, err = b.newNodes(ctx, main); err != nil {
+		return stream.Phases{}, err
+	}
+	if p.Teardown, err = b.newNodes(ctx, teardown); err != nil {
+		return stream.Phases{}, err
+	}
+	return p, nil
+}
+
+// parseTimeout parses the timeout column of a flow or testcase, zero means
+// no timeout
+func parseTimeout(raw null.String, owner string) (time.Duration, error) {
+	if raw.String == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw.String)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid 'timeout' %q of %s", raw.String, owner)
+	}
+	return d, nil
+}
+
+// splitList parses a comma separated column such as needs
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// newNodes arranges testcases into the nodes of a flow. Consecutive
+// testcases sharing a loop repeat together, and within those, consecutive
+// testcases sharing a branch form an if/else group guarded by the condition
+// of the first one.
+func (b builder) newNodes(ctx context.Context, tests []*tmodel.Testcase) ([]stream.Node, error) {
+	var nodes []stream.Node
+	for i := 0; i < len(tests); {
+		tc := tests[i]
+		if tc.Loop.String != "" {
+			j := i
+			for ; j < len(tests) && tests[j].Loop == tc.Loop; j++ {
+			}
+			body := make([]*tmodel.Testcase, j-i)
+			for k := range body {
+				if tests[i+k].Snapshot {
+					return nil, fmt.Errorf("snapshot testcase %d cannot run in a loop", tests[i+k].ID)
+				}
+				cp := *tests[i+k]
+				cp.Loop.String = ""
+				body[k] = &cp
+			}
+			inner, err := b.newNodes(ctx, body)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, stream.Loop{Over: tc.Loop.String, Nodes: inner})
+			i = j
+			continue
+		}
+
+		if tc.Branch.String == "" {
+			step, err := b.newStep(ctx, *tc)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, step)
+			i++
+			continue
+		}
+
+		g := stream.Group{When: tc.When.String}
+		for ; i < len(tests) && tests[i].Branch == tc.Branch && tests[i].Loop.String == ""; i++ {
+			step, err := b.newStep(ctx, *tests[i])
+			if err != nil {
+				return nil, err
+			}
+			if tests[i] == tc {
+				step.When = ""
+			}
+			if tests[i].BranchElse {
+				g.Else = append(g.Else, step)
+			} else {
+				g.Then = append(g.Then, step)
+			}
+		}
+		nodes = append(nodes, g)
+	}
+	return nodes, nil
+}
+
+// compareOptions parses how the responses of a testcase are compared, the
+// snapshot ignore rules are added when comparing with the golden snapshot
+func compareOptions(tc tmodel.Testcase, snapshot bool) (asserter.DiffOptions, error) {
+	var m tmodel.Compare
+	if len(tc.Compare) > 0 {
+		if err := json.Unmarshal(tc.Compare, &m); err != nil {
+			return asserter.DiffOptions{}, fmt.Errorf("corrupt data stored for 'compare' in testcase")
+		}
+	}
+	o := asserter.DiffOptions{
+		Ignore:            m.Ignore,
+		IgnoreOrder:       m.IgnoreOrder,
+		IgnoreExtra:       m.IgnoreExtra,
+		IgnoreNumericType: m.IgnoreNumericType,
+	}
+	if snapshot {
+		o.Ignore = append(o.Ignore, splitList(tc.SnapshotIgnore.String)...)
+	}
+	return o, nil
+}
+
+// newRetry parses the retry policy stored with a testcase, rejecting an
+// unknown backoff and durations which are not positive
+func newRetry(raw tmodel.JSON) (stream.Retry, error) {
+	var m tmodel.Retry
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return stream.Retry{}, fmt.Errorf("corrupt data stored for 'retry' in testcase")
+	}
+	switch stream.Backoff(m.Backoff) {
+	case "", stream.Fixed, stream.Exponential:
+	default:
+		return stream.Retry{}, fmt.Errorf("invalid backoff %q in 'retry' of testcase, expected %s or %s", m.Backoff, stream.Fixed, stream.Exponential)
+	}
+	if m.MaxAttempts < 0 {
+		return stream.Retry{}, fmt.Errorf("invalid max_attempts %d in 'retry' of testcase", m.MaxAttempts)
+	}
+	r := stream.Retry{
+		MaxAttempts: m.MaxAttempts,
+		Backoff:     stream.Backoff(m.Backoff),
+		OnStatus:    m.OnStatus,
+		OnError:     m.OnError,
+	}
+	for _, d := range []struct {
+		raw  string
+		dest *time.Duration
+	}{
+		{m.Delay, &r.Delay},
+		{m.MaxDelay, &r.MaxDelay},
+		{m.PollFor, &r.PollFor},
+	} {
+		if d.raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.raw)
+		if err != nil || v <= 0 {
+			return stream.Retry{}, fmt.Errorf("invalid duration %q in 'retry' of testcase", d.raw)
+		}
+		*d.dest = v
+	}
+	return r, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/stream"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+func TestOnFalse(t *testing.T) {
+	cases := []struct {
+		OnFalse   string
+		Otherwise stream.Outcome
+		Valid     bool
+	}{
+		{"", "", true},
+		{"skip", stream.Skip, true},
+		{"stop", stream.Stop, true},
+		{"fail", "", false},
+		{"STOP", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.OnFalse, func(t *testing.T) {
+			m := tmodel.Testcase{ID: 1, Name: "check", API: "REST", OnFalse: tc.OnFalse}
+			err := validateTestcase(&m)
+			if tc.Valid != (err == nil) {
+				t.Fatalf("validate: %v", err)
+			}
+			if err != nil && !errors.Is(err, cerrors.ErrInValidation) {
+				t.Fatalf("validate: unexpected error %v", err)
+			}
+
+			step, err := builder{}.newStep(context.Background(), m)
+			if tc.Valid != (err == nil) {
+				t.Fatalf("build: %v", err)
+			}
+			if step.Otherwise != tc.Otherwise {
+				t.Fatalf("otherwise %q, want %q", step.Otherwise, tc.Otherwise)
+			}
+		})
+	}
+}
+
+func TestNewRetry(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Raw   string
+		Retry stream.Retry
+		Valid bool
+	}{
+		{"attempts", `{"max_attempts": 3, "delay": "1s"}`, stream.Retry{MaxAttempts: 3, Delay: time.Second}, true},
+		{"unbounded poll", `{"max_attempts": 0, "poll_for": "1m"}`, stream.Retry{PollFor: time.Minute}, true},
+		{"negative attempts", `{"max_attempts": -1}`, stream.Retry{}, false},
+		{"unknown backoff", `{"max_attempts": 2, "backoff": "linear"}`, stream.Retry{}, false},
+		{"negative delay", `{"max_attempts": 2, "delay": "-1s"}`, stream.Retry{}, false},
+		{"corrupt", `{"max_attempts": "2"}`, stream.Retry{}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r, err := newRetry(tmodel.JSON(tc.Raw))
+			if tc.Valid != (err == nil) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(r, tc.Retry) {
+				t.Fatalf("retry %#v, want %#v", r, tc.Retry)
+			}
+		})
+	}
+}
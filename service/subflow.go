@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/thejasn/tester/core/expr"
+	"github.com/thejasn/tester/core/stream"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+// MaxCallDepth bounds the flows calling each other, the outermost included
+var MaxCallDepth = 8
+
+// newCall builds the sub-flow called by a testcase along with its own auth
+// profile. Flows being built are tracked so that a flow calling itself,
+// directly or through others, is rejected, as are calls nested deeper than
+// MaxCallDepth.
+func (b builder) newCall(ctx context.Context, tc tmodel.Testcase) (*stream.Call, error) {
+	id := int(tc.SubflowID.Int64)
+	for i, caller := range b.calls {
+		if caller == id {
+			return nil, fmt.Errorf("flow %d calls itself through %s", id, chain(append(b.calls[i:len(b.calls):len(b.calls)], id)))
+		}
+	}
+	if len(b.calls) >= MaxCallDepth {
+		return nil, fmt.Errorf("flow %d is called through %s, deeper than %d flows", id, chain(b.calls), MaxCallDepth)
+	}
+	fl, err := b.flows.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not call flow %d as %w", id, err)
+	}
+	tests, _, err := b.tests.GetAllOrderedWhere(ctx, map[string]interface{}{
+		"flow_id": fl.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not find testcases of flow %d as %w", fl.ID, err)
+	}
+
+	inner := b
+	if inner.profile, err = loadAuth(ctx, b.auths, int(fl.AuthProfileID.Int64), fl.AuthProfileID.Valid); err != nil {
+		return nil, err
+	}
+	inner.calls = append(b.calls[:len(b.calls):len(b.calls)], id)
+	phases, err := inner.newPhases(ctx, tests)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &stream.Call{
+		FlowID: fl.ID,
+		Phases: phases,
+		Engine: func(c stream.Context) stream.Engine {
+			return engine(fl, c)
+		},
+	}
+	if call.Inputs, err = expressions(tc.Inputs, "inputs", "testcase"); err != nil {
+		return nil, err
+	}
+	if call.Exports, err = expressions(fl.Exports, "exports", "flow"); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// expressions decodes a map of names to expressions, checking that every
+// expression compiles
+func expressions(raw tmodel.JSON, column, owner string) (map[string]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("corrupt data stored for '%s' in %s", column, owner)
+	}
+	for name, src := range m {
+		if _, err := expr.Compile(src); err != nil {
+			return nil, fmt.Errorf("invalid '%s' %q of %s: %v", column, name, owner, err)
+		}
+	}
+	return m, nil
+}
+
+func chain(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, " -> ")
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/guregu/null"
+
+	"github.com/thejasn/tester/cerrors"
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+// callGraph serves flows whose testcases call the listed flows
+type callGraph map[int][]int
+
+func (g callGraph) Get(_ context.Context, id int) (fmodel.Flow, error) {
+	if _, ok := g[id]; !ok {
+		return fmodel.Flow{}, cerrors.ErrNotFound
+	}
+	return fmodel.Flow{ID: id}, nil
+}
+
+func (g callGraph) GetAllOrderedWhere(_ context.Context, where map[string]interface{}) ([]*tmodel.Testcase, int64, error) {
+	var tests []*tmodel.Testcase
+	for i, callee := range g[where["flow_id"].(int)] {
+		tests = append(tests, &tmodel.Testcase{ID: i + 1, TestCaseID: i + 1, Name: "call", SubflowID: null.IntFrom(int64(callee))})
+	}
+	return tests, int64(len(tests)), nil
+}
+
+// chainOf returns flows 1 to n, each calling the next one
+func chainOf(n int) callGraph {
+	g := callGraph{}
+	for i := 1; i < n; i++ {
+		g[i] = []int{i + 1}
+	}
+	g[n] = nil
+	return g
+}
+
+func TestNewCall(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Graph callGraph
+		Err   string
+	}{
+		{"calls another", callGraph{1: {2}, 2: nil}, ""},
+		{"calls the same flow twice", callGraph{1: {2}, 2: {3, 3}, 3: nil}, ""},
+		{"self call", callGraph{1: {1}}, "flow 1 calls itself through 1 -> 1"},
+		{"A to B to A", callGraph{1: {2}, 2: {1}}, "flow 1 calls itself through 1 -> 2 -> 1"},
+		{"cycle below", callGraph{1: {2}, 2: {3}, 3: {2}}, "flow 2 calls itself through 2 -> 3 -> 2"},
+		{"missing flow", callGraph{1: {2}}, "could not call flow 2"},
+		{"as deep as allowed", chainOf(MaxCallDepth), ""},
+		{"too deep", chainOf(MaxCallDepth + 1), "deeper than"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			b := builder{flows: tc.Graph, tests: tc.Graph, calls: []int{1}}
+			tests, _, _ := tc.Graph.GetAllOrderedWhere(context.Background(), map[string]interface{}{"flow_id": 1})
+			_, err := b.newCall(context.Background(), *tests[0])
+			if tc.Err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.Err) {
+				t.Fatalf("got error %v, want %q", err, tc.Err)
+			}
+		})
+	}
+}
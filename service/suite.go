@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/stream"
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	"github.com/thejasn/tester/domain/suite/model"
+	"github.com/thejasn/tester/domain/suite/repo"
+)
+
+// SuiteReport is the aggregate outcome of a suite run, or of any set of
+// flows run at once, flows are listed in the order of the suite whether
+// they ran in parallel or not
+type SuiteReport struct {
+	SuiteID   int               `json:"suite_id,omitempty"`
+	Passed    bool              `json:"passed"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Duration  time.Duration     `json:"duration"`
+	Flows     []SuiteFlowResult `json:"flows"`
+}
+
+// SuiteFlowResult is the outcome of a flow within a suite run, Error is set
+// when the flow could not be executed at all
+type SuiteFlowResult struct {
+	FlowID int            `json:"flow_id"`
+	Name   string         `json:"name"`
+	Passed bool           `json:"passed"`
+	Error  string         `json:"error,omitempty"`
+	Report *stream.Report `json:"report,omitempty"`
+}
+
+type Suite interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Suite, int64, error)
+	Get(context.Context, int) (model.Suite, error)
+	Add(context.Context, *model.Suite) (*model.Suite, int64, error)
+	Update(context.Context, int, *model.Suite) (*model.Suite, int64, error)
+	Delete(context.Context, int) (int64, error)
+	Execute(ctx context.Context, id int, o RunOptions) (SuiteReport, error)
+}
+
+func NewSuiteSvc(r repo.Suite, f Flow) Suite {
+	return suite{
+		repo:  r,
+		flows: f,
+	}
+}
+
+type suite struct {
+	repo  repo.Suite
+	flows Flow
+}
+
+func (s suite) GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Suite, int64, error) {
+	return s.repo.GetAll(ctx, page, pagesize, order)
+}
+
+func (s suite) Get(ctx context.Context, id int) (model.Suite, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s suite) Add(ctx context.Context, m *model.Suite) (*model.Suite, int64, error) {
+	if err := validateSuite(m); err != nil {
+		return nil, -1, err
+	}
+	return s.repo.Add(ctx, m)
+}
+
+func (s suite) Update(ctx context.Context, id int, m *model.Suite) (*model.Suite, int64, error) {
+	if err := validateSuite(m); err != nil {
+		return nil, -1, err
+	}
+	return s.repo.Update(ctx, id, m)
+}
+
+func (s suite) Delete(ctx context.Context, id int) (int64, error) {
+	return s.repo.Delete(ctx, id)
+}
+
+// Execute runs the flows of a suite, one after the other or in parallel,
+// sharing the environment and variables of the suite. The environment of
+// the suite is overridden by the one of o, if any, and so are variables
+// defined in both. A flow that fails does not stop the others.
+func (s suite) Execute(ctx context.Context, id int, o RunOptions) (SuiteReport, error) {
+	m, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return SuiteReport{}, fmt.Errorf("could not execute as suite %w", err)
+	}
+	o, parallelism, err := suiteRun(m, o)
+	if err != nil {
+		return SuiteReport{}, err
+	}
+	report := runFlows(ctx, s.flows, m.FlowIDs, parallelism, o)
+	report.SuiteID = m.ID
+	return report, nil
+}
+
+// suiteRun returns the options the flows of a suite run with and how many
+// of them run at a time
+func suiteRun(m model.Suite, o RunOptions) (RunOptions, int, error) {
+	if o.Environment == "" {
+		o.Environment = m.Environment.String
+	}
+	var variables map[string]interface{}
+	if len(m.Variables) > 0 {
+		if err := json.Unmarshal(m.Variables, &variables); err != nil {
+			return o, 0, fmt.Errorf("corrupt data stored for 'variables' in suite")
+		}
+	}
+	for k, v := range o.Variables {
+		if variables == nil {
+			variables = map[string]interface{}{}
+		}
+		variables[k] = v
+	}
+	o.Variables = variables
+
+	parallelism := 1
+	if m.Parallel {
+		parallelism = m.Parallelism
+	}
+	return o, parallelism, nil
+}
+
+// flowRunner runs flows by id, the service on the server and the suite
+// file on local runs
+type flowRunner interface {
+	Get(context.Context, int) (fmodel.Flow, error)
+	ExecuteWith(context.Context, int, RunOptions) (stream.Report, error)
+}
+
+// runFlows executes flows with at most parallelism of them at a time, the
+// report lists them in the given order while o.Progress hears of them in
+// the order they finish
+func runFlows(ctx context.Context, f flowRunner, ids []int, parallelism int, o RunOptions) SuiteReport {
+	if parallelism <= 0 {
+		parallelism = stream.DefaultParallelism
+	}
+	start := time.Now()
+	results := make([]SuiteFlowResult, len(ids))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, flowID := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i, flowID int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = executeFlow(ctx, f, flowID, o)
+			if o.Progress != nil {
+				mu.Lock()
+				o.Progress(results[i])
+				mu.Unlock()
+			}
+		}(i, flowID)
+	}
+	wg.Wait()
+
+	report := SuiteReport{Passed: true, Total: len(results), Flows: results}
+	for _, r := range results {
+		if r.Passed {
+			report.Succeeded++
+		} else {
+			report.Failed++
+			report.Passed = false
+		}
+	}
+	report.Duration = time.Since(start)
+	return report
+}
+
+func executeFlow(ctx context.Context, f flowRunner, flowID int, o RunOptions) SuiteFlowResult {
+	r := SuiteFlowResult{FlowID: flowID}
+	fl, err := f.Get(ctx, flowID)
+	if err != nil {
+		r.Error = fmt.Sprintf("could not execute as flow %v", err)
+		return r
+	}
+	r.Name = fl.Name
+	report, err := f.ExecuteWith(ctx, flowID, o)
+	if report.Steps != nil {
+		r.Report = &report
+	}
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Passed = report.Passed
+	return r
+}
+
+// validateSuite checks that a suite lists every flow once
+func validateSuite(m *model.Suite) error {
+	seen := make(map[int]bool, len(m.FlowIDs))
+	for _, id := range m.FlowIDs {
+		if seen[id] {
+			return fmt.Errorf("%w: flow %d is listed twice in suite", cerrors.ErrInValidation, id)
+		}
+		seen[id] = true
+	}
+	if m.Parallelism < 0 {
+		return fmt.Errorf("%w: invalid 'parallelism' %d of suite", cerrors.ErrInValidation, m.Parallelism)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/thejasn/tester/core/stream"
+	fmodel "github.com/thejasn/tester/domain/flow/model"
+	"github.com/thejasn/tester/domain/suite/model"
+	tmodel "github.com/thejasn/tester/domain/testcase/model"
+)
+
+// fakeFlows passes every flow but those listed in failing, and cannot find
+// flow 0
+type fakeFlows struct {
+	Flow
+	failing        map[int]bool
+	inflight, peak *int32
+}
+
+func (f fakeFlows) Get(_ context.Context, id int) (fmodel.Flow, error) {
+	if id == 0 {
+		return fmodel.Flow{}, errors.New("not found")
+	}
+	return fmodel.Flow{ID: id, Name: "flow"}, nil
+}
+
+func (f fakeFlows) ExecuteWith(_ context.Context, id int, _ RunOptions) (stream.Report, error) {
+	n := atomic.AddInt32(f.inflight, 1)
+	for {
+		p := atomic.LoadInt32(f.peak)
+		if n <= p || atomic.CompareAndSwapInt32(f.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	atomic.AddInt32(f.inflight, -1)
+	return stream.Report{Passed: !f.failing[id], Steps: []stream.StepResult{}}, nil
+}
+
+func newFakeFlows(failing ...int) fakeFlows {
+	f := fakeFlows{failing: map[int]bool{}, inflight: new(int32), peak: new(int32)}
+	for _, id := range failing {
+		f.failing[id] = true
+	}
+	return f
+}
+
+func TestRunFlows(t *testing.T) {
+	cases := []struct {
+		Name        string
+		IDs         []int
+		Failing     []int
+		Parallelism int
+		Peak        int32
+		Passed      []bool
+	}{
+		{"sequential", []int{1, 2, 3}, nil, 1, 1, []bool{true, true, true}},
+		{"parallel", []int{1, 2, 3, 4}, []int{2}, 2, 2, []bool{true, false, true, true}},
+		{"default parallelism", []int{1, 2}, nil, 0, 2, []bool{true, true}},
+		{"missing flow", []int{1, 0}, nil, 1, 1, []bool{true, false}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			f := newFakeFlows(tc.Failing...)
+			var heard int
+			r := runFlows(context.Background(), f, tc.IDs, tc.Parallelism, RunOptions{Progress: func(SuiteFlowResult) { heard++ }})
+
+			var passed []bool
+			for i, fl := range r.Flows {
+				if fl.FlowID != tc.IDs[i] {
+					t.Fatalf("flow %d reported as %d", tc.IDs[i], fl.FlowID)
+				}
+				passed = append(passed, fl.Passed)
+			}
+			if !reflect.DeepEqual(passed, tc.Passed) {
+				t.Fatalf("bad outcomes: %v", passed)
+			}
+			if *f.peak != tc.Peak || heard != len(tc.IDs) || r.Total != len(tc.IDs) || r.Succeeded+r.Failed != r.Total || r.Passed != (r.Failed == 0) {
+				t.Fatalf("bad report, peak %d, heard %d: %#v", *f.peak, heard, r)
+			}
+		})
+	}
+}
+
+func TestSuiteRun(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Suite       model.Suite
+		Options     RunOptions
+		Environment string
+		Variables   map[string]interface{}
+		Parallelism int
+	}{
+		{
+			Name:        "suite defaults",
+			Suite:       model.Suite{Environment: null.StringFrom("staging"), Variables: tmodel.JSON(`{"user": "alice"}`), Parallelism: 3},
+			Environment: "staging",
+			Variables:   map[string]interface{}{"user": "alice"},
+			Parallelism: 1,
+		},
+		{
+			Name:        "overridden",
+			Suite:       model.Suite{Environment: null.StringFrom("staging"), Variables: tmodel.JSON(`{"user": "alice", "tenant": 1}`), Parallel: true, Parallelism: 3},
+			Options:     RunOptions{Environment: "prod", Variables: map[string]interface{}{"user": "bob"}},
+			Environment: "prod",
+			Variables:   map[string]interface{}{"user": "bob", "tenant": 1.0},
+			Parallelism: 3,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			o, parallelism, err := suiteRun(tc.Suite, tc.Options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if o.Environment != tc.Environment || !reflect.DeepEqual(o.Variables, tc.Variables) || parallelism != tc.Parallelism {
+				t.Fatalf("bad run: %#v %d", o, parallelism)
+			}
+		})
+	}
+
+	if _, _, err := suiteRun(model.Suite{Variables: tmodel.JSON(`[1`)}, RunOptions{}); err == nil {
+		t.Fatal("expected an error on corrupt variables")
+	}
+}
+
+func TestValidateSuite(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Suite model.Suite
+		Valid bool
+	}{
+		{"valid", model.Suite{FlowIDs: []int{1, 2}, Parallelism: 2}, true},
+		{"flow listed twice", model.Suite{FlowIDs: []int{1, 2, 1}}, false},
+		{"negative parallelism", model.Suite{Parallelism: -1}, false},
+	}
+
+	for _, tc := range cases {
+		if err := validateSuite(&tc.Suite); (err == nil) != tc.Valid {
+			t.Fatalf("%s: bad validation: %v", tc.Name, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/stream"
+	frepo "github.com/thejasn/tester/domain/flow/repo"
+	trepo "github.com/thejasn/tester/domain/testcase/repo"
+)
+
+// FlowFilter narrows the flows listed, see repo.Filter
+type FlowFilter = frepo.Filter
+
+// TestcaseFilter narrows the testcases listed, see repo.Filter
+type TestcaseFilter = trepo.Filter
+
+// normalizeTags trims the comma separated tags and drops empty and
+// duplicate ones so that a tag can be matched as a whole
+func normalizeTags(tags null.String) null.String {
+	if !tags.Valid {
+		return tags
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range strings.Split(tags.String, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) == 0 {
+		return null.String{}
+	}
+	return null.StringFrom(strings.Join(out, ","))
+}
+
+// validateTag checks a tag searched for, it cannot hold a comma
+func validateTag(tag string) error {
+	if strings.Contains(tag, ",") {
+		return fmt.Errorf("%w: invalid tag %q", cerrors.ErrBadParams, tag)
+	}
+	return nil
+}
+
+// validateFlowFilter checks the status searched for, the outcome of the
+// latest run of a flow
+func validateFlowFilter(f *FlowFilter) error {
+	f.Status = strings.ToLower(f.Status)
+	switch f.Status {
+	case "", "passed", "failed":
+	default:
+		return fmt.Errorf("%w: invalid status %q, expected passed or failed", cerrors.ErrBadParams, f.Status)
+	}
+	return validateTag(f.Tag)
+}
+
+// validateTestcaseFilter checks the status searched for, the status of a
+// testcase in its latest run
+func validateTestcaseFilter(f *TestcaseFilter) error {
+	f.Status = strings.ToUpper(f.Status)
+	switch stream.Status(f.Status) {
+	case "", stream.Passed, stream.Failed, stream.Errored, stream.Skipped, stream.TimedOut:
+	default:
+		return fmt.Errorf("%w: invalid status %q", cerrors.ErrBadParams, f.Status)
+	}
+	return validateTag(f.Tag)
+}
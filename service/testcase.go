@@ -2,41 +2,60 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strconv"
 
-	"github.com/thejasn/tester/core/client/grpc"
-
-	"github.com/thejasn/tester/core/asserter"
-	"github.com/thejasn/tester/core/client/rest"
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/cerrors"
 	"github.com/thejasn/tester/core/stream"
-	"github.com/thejasn/tester/core/tester"
+	arepo "github.com/thejasn/tester/domain/auth/repo"
+	frepo "github.com/thejasn/tester/domain/flow/repo"
+	prepo "github.com/thejasn/tester/domain/protoset/repo"
+	rmodel "github.com/thejasn/tester/domain/run/model"
+	hrepo "github.com/thejasn/tester/domain/run/repo"
+	srepo "github.com/thejasn/tester/domain/schema/repo"
+	snaprepo "github.com/thejasn/tester/domain/snapshot/repo"
 	"github.com/thejasn/tester/domain/testcase/model"
 	"github.com/thejasn/tester/domain/testcase/repo"
 )
 
 type Testcase interface {
-	GetAll(context.Context, int64, int64, string) ([]*model.Testcase, int64, error)
+	GetAll(context.Context, int64, int64, string, TestcaseFilter) ([]*model.Testcase, int64, error)
 	Get(context.Context, int) (model.Testcase, error)
 	Add(context.Context, *model.Testcase) (*model.Testcase, int64, error)
 	Update(context.Context, int, *model.Testcase) (*model.Testcase, int64, error)
 	Delete(context.Context, int) (int64, error)
-	Execute(context.Context, int) (bool, error)
+	Execute(context.Context, int) (stream.Report, error)
 }
 
-func NewTestcaseSvc(r repo.Testcase) Testcase {
+func NewTestcaseSvc(r repo.Testcase, f frepo.Flow, a arepo.Profile, s srepo.Schema, p prepo.Protoset, n snaprepo.Snapshot, h hrepo.Run, w Webhook) Testcase {
 	return testcase{
-		r: r,
+		r:     r,
+		frepo: f,
+		arepo: a,
+		srepo: s,
+		prepo: p,
+		nrepo: n,
+		hrepo: h,
+		hooks: w,
 	}
 }
 
 type testcase struct {
-	r repo.Testcase
+	r     repo.Testcase
+	frepo frepo.Flow
+	arepo arepo.Profile
+	srepo srepo.Schema
+	prepo prepo.Protoset
+	nrepo snaprepo.Snapshot
+	hrepo hrepo.Run
+	hooks Webhook
 }
 
-func (t testcase) GetAll(ctx context.Context, page int64, pagesize int64, order string) ([]*model.Testcase, int64, error) {
-	return t.r.GetAll(ctx, page, pagesize, order)
+func (t testcase) GetAll(ctx context.Context, page int64, pagesize int64, order string, filter TestcaseFilter) ([]*model.Testcase, int64, error) {
+	if err := validateTestcaseFilter(&filter); err != nil {
+		return nil, -1, err
+	}
+	return t.r.GetAll(ctx, page, pagesize, order, filter)
 }
 
 func (t testcase) Get(ctx context.Context, id int) (model.Testcase, error) {
@@ -44,50 +63,79 @@ func (t testcase) Get(ctx context.Context, id int) (model.Testcase, error) {
 }
 
 func (t testcase) Add(ctx context.Context, ts *model.Testcase) (*model.Testcase, int64, error) {
+	if err := validateTestcase(ts); err != nil {
+		return nil, -1, err
+	}
+	ts.Tags = normalizeTags(ts.Tags)
 	return t.r.Add(ctx, ts)
 }
 
 func (t testcase) Update(ctx context.Context, id int, tc *model.Testcase) (*model.Testcase, int64, error) {
+	if err := validateTestcase(tc); err != nil {
+		return nil, -1, err
+	}
+	tc.Tags = normalizeTags(tc.Tags)
 	return t.r.Update(ctx, id, tc)
 }
 
+// validateTestcase checks the outcome of an unmet condition, the rest of a
+// testcase being checked once it is built
+func validateTestcase(m *model.Testcase) error {
+	if !validOutcome(stream.Outcome(m.OnFalse)) {
+		return fmt.Errorf("%w: unsupported 'on_false' %q of testcase", cerrors.ErrInValidation, m.OnFalse)
+	}
+	return nil
+}
+
 func (t testcase) Delete(ctx context.Context, id int) (int64, error) {
 	return t.r.Delete(ctx, id)
 }
 
-func (t testcase) Execute(ctx context.Context, id int) (bool, error) {
+// Execute runs a single testcase on its own. Its condition refers to other
+// steps of the flow, so it is ignored here.
+func (t testcase) Execute(ctx context.Context, id int) (stream.Report, error) {
 	tc, err := t.r.Get(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("could not execute as testcase %w", err)
+		return stream.Report{}, fmt.Errorf("could not execute as testcase %w", err)
+	}
+
+	fl, err := t.frepo.Get(ctx, tc.FlowID)
+	if err != nil {
+		return stream.Report{}, fmt.Errorf("could not execute as flow %w", err)
+	}
+
+	profile, err := loadAuth(ctx, t.arepo, int(fl.AuthProfileID.Int64), fl.AuthProfileID.Valid)
+	if err != nil {
+		return stream.Report{}, err
 	}
 
-	var expected model.Result
-	err = json.Unmarshal(tc.Expected, &expected)
+	b := builder{
+		profile:   profile,
+		schemas:   newSchemaCache(t.srepo),
+		protosets: newProtosetCache(t.prepo),
+		snapshots: t.nrepo,
+		flows:     t.frepo,
+		tests:     t.r,
+		auths:     t.arepo,
+		calls:     []int{fl.ID},
+	}
+	step, err := b.newStep(ctx, tc)
 	if err != nil {
-		return false, fmt.Errorf("corrupt data stored for 'expected' in testcase")
+		return stream.Report{}, err
 	}
+	step.When = ""
 
-	switch tc.API {
-	case "REST":
-		cfg := rest.NewRestConfig(ctx, tc.Scheme+"://"+tc.Host+":"+strconv.Itoa(tc.Port))
-		l := stream.NewLinearFlow()
-		l.Execute(tc.TestCaseID, tester.RestExecutor(ctx, cfg,
-			rest.WithMethod(tc.Method.String),
-			rest.WithBody(tc.Body.String),
-			rest.WithUriPath(tc.Path)), asserter.Assertion{
-			Expected: expected.Data,
-			Actual:   tc.Actual.String,
-			Operator: tc.Operation,
-		})
-	case "GRPC":
-		cfg := grpc.NewConfig(ctx, "something", tc.Host, strconv.Itoa(tc.Port))
-		l := stream.NewLinearFlow()
-		l.Execute(tc.TestCaseID, tester.GrpcExecutor(ctx, cfg,
-			grpc.WithRequest(tc.Body.String),
-			grpc.WithMethod(tc.Path)), asserter.Assertion{
-			Expected: expected.Data,
-			Actual:   tc.Actual.String,
-			Operator: tc.Operation})
+	l := stream.NewLinearFlow()
+	report := l.Run(ctx, step)
+
+	tests := []*model.Testcase{&tc}
+	if err = recordSnapshots(ctx, t.nrepo, tests, report); err != nil {
+		return report, err
+	}
+	r := rmodel.Run{TestcaseID: null.IntFrom(int64(tc.ID))}
+	if err = recordRun(ctx, t.hrepo, &r, tests, report); err != nil {
+		return report, err
 	}
-	return true, nil
+	t.hooks.Notify(ctx, null.IntFrom(int64(fl.ID)), tc.Name, r, report)
+	return report, nil
 }
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/stream"
+	rmodel "github.com/thejasn/tester/domain/run/model"
+	hrepo "github.com/thejasn/tester/domain/run/repo"
+	"github.com/thejasn/tester/domain/webhook/model"
+	"github.com/thejasn/tester/domain/webhook/repo"
+	"github.com/thejasn/tester/pkg/log"
+)
+
+// events a webhook is notified of, every finished run or only the runs
+// whose outcome differs from the previous run of the same flow
+const (
+	EventFinished = "finished"
+	EventChanged  = "changed"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the payload,
+// keyed with the secret of the webhook, as sha256=<digest>
+const SignatureHeader = "X-Tsekaro-Signature"
+
+var (
+	// DeliveryAttempts bounds the attempts to deliver a notification
+	DeliveryAttempts = 5
+	// DeliveryBackoff is the wait before the second attempt, it doubles
+	// with every attempt
+	DeliveryBackoff = time.Second
+	// DeliveryTimeout bounds a single attempt
+	DeliveryTimeout = 10 * time.Second
+)
+
+// WebhookPayload is posted to webhooks, FailedSteps lists the steps which
+// did not pass
+type WebhookPayload struct {
+	Event       string       `json:"event"`
+	WebhookID   int          `json:"webhook_id"`
+	Run         RunSummary   `json:"run"`
+	FailedSteps []FailedStep `json:"failed_steps"`
+}
+
+// RunSummary sums up a run of a flow or of a single testcase.
+// PreviousPassed is the outcome of the previous run, if any.
+type RunSummary struct {
+	ID             int       `json:"id"`
+	FlowID         null.Int  `json:"flow_id"`
+	TestcaseID     null.Int  `json:"testcase_id"`
+	Name           string    `json:"name"`
+	Passed         bool      `json:"passed"`
+	PreviousPassed null.Bool `json:"previous_passed"`
+	Message        string    `json:"message,omitempty"`
+	Source         string    `json:"source"`
+	DurationMs     float64   `json:"duration_ms"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// FailedStep is a step of a run which did not pass
+type FailedStep struct {
+	ID      int           `json:"id"`
+	Name    string        `json:"name"`
+	Status  stream.Status `json:"status"`
+	Message string        `json:"message,omitempty"`
+	Phase   stream.Phase  `json:"phase,omitempty"`
+}
+
+// Webhook manages webhooks, Notify is called once a run was recorded in
+// the history and posts it to the matching webhooks in the background.
+// CleanUp stops retrying failed deliveries and waits for those in flight to
+// be logged.
+type Webhook interface {
+	GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Webhook, int64, error)
+	Get(context.Context, int) (model.Webhook, error)
+	Add(context.Context, *model.Webhook) (*model.Webhook, int64, error)
+	Update(context.Context, int, *model.Webhook) (*model.Webhook, int64, error)
+	Delete(context.Context, int) (int64, error)
+	Deliveries(ctx context.Context, webhookID int, page, pagesize int64) ([]*model.Delivery, int64, error)
+	Notify(ctx context.Context, flowID null.Int, name string, run rmodel.Run, report stream.Report)
+	CleanUp(context.Context) error
+}
+
+func NewWebhookSvc(r repo.Webhook, h hrepo.Run) Webhook {
+	stop, cancel := context.WithCancel(context.Background())
+	return webhook{
+		repo:       r,
+		hrepo:      h,
+		client:     &http.Client{Timeout: DeliveryTimeout},
+		deliveries: &sync.WaitGroup{},
+		stop:       stop,
+		cancel:     cancel,
+	}
+}
+
+// webhook tracks the deliveries in flight with deliveries, stop being done
+// once they are no longer retried
+type webhook struct {
+	repo       repo.Webhook
+	hrepo      hrepo.Run
+	client     *http.Client
+	deliveries *sync.WaitGroup
+	stop       context.Context
+	cancel     context.CancelFunc
+}
+
+func (w webhook) GetAll(ctx context.Context, page, pagesize int64, order string) ([]*model.Webhook, int64, error) {
+	return w.repo.GetAll(ctx, page, pagesize, order)
+}
+
+func (w webhook) Get(ctx context.Context, id int) (model.Webhook, error) {
+	return w.repo.Get(ctx, id)
+}
+
+func (w webhook) Add(ctx context.Context, m *model.Webhook) (*model.Webhook, int64, error) {
+	if err := validateWebhook(m); err != nil {
+		return nil, -1, err
+	}
+	return w.repo.Add(ctx, m)
+}
+
+func (w webhook) Update(ctx context.Context, id int, m *model.Webhook) (*model.Webhook, int64, error) {
+	if err := validateWebhook(m); err != nil {
+		return nil, -1, err
+	}
+	return w.repo.Update(ctx, id, m)
+}
+
+func (w webhook) Delete(ctx context.Context, id int) (int64, error) {
+	return w.repo.Delete(ctx, id)
+}
+
+func (w webhook) Deliveries(ctx context.Context, webhookID int, page, pagesize int64) ([]*model.Delivery, int64, error) {
+	return w.repo.Deliveries(ctx, webhookID, page, pagesize)
+}
+
+// Notify posts a recorded run to the webhooks of its flow, flowID, and to
+// those of every flow. Failures are only logged, a run is never failed
+// because a notification could not be delivered.
+func (w webhook) Notify(ctx context.Context, flowID null.Int, name string, run rmodel.Run, report stream.Report) {
+	logger := log.GetLogger(ctx)
+	hooks, err := w.repo.Matching(ctx, flowID)
+	if err != nil {
+		logger.Errorf("could not find webhooks of run %d: %v", run.ID, err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	var previous null.Bool
+	if p, err := w.hrepo.Previous(ctx, run); err == nil {
+		previous = null.BoolFrom(p.Passed)
+	}
+	changed := previous.Valid && previous.Bool != run.Passed
+
+	payload := WebhookPayload{
+		Run: RunSummary{
+			ID:             run.ID,
+			FlowID:         run.FlowID,
+			TestcaseID:     run.TestcaseID,
+			Name:           name,
+			Passed:         run.Passed,
+			PreviousPassed: previous,
+			Message:        run.Message.String,
+			Source:         run.Source,
+			DurationMs:     run.DurationMs,
+			StartedAt:      run.StartedAt,
+		},
+		FailedSteps: failedSteps(report),
+	}
+
+	// deliveries outlive the request which triggered the run
+	bg := log.WithLogger(context.Background(), logger)
+	for _, h := range hooks {
+		if h.Event == EventChanged && !changed {
+			continue
+		}
+		p := payload
+		p.Event = h.Event
+		p.WebhookID = h.ID
+		w.deliveries.Add(1)
+		go func(h model.Webhook) {
+			defer w.deliveries.Done()
+			w.deliver(bg, h, p)
+		}(*h)
+	}
+}
+
+// CleanUp gives up the retries of failed deliveries, their latest attempt
+// being logged, and waits for the deliveries in flight
+func (w webhook) CleanUp(ctx context.Context) error {
+	log.GetLogger(ctx).Infof("webhook deliveries stopping")
+	w.cancel()
+	done := make(chan struct{})
+	go func() {
+		w.deliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.GetLogger(ctx).Infof("webhook deliveries gracefully stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook deliveries did not finish:%w", ctx.Err())
+	}
+}
+
+// deliver posts the payload until the webhook accepts it, waiting longer
+// after every failed attempt, and logs the outcome as a delivery
+func (w webhook) deliver(ctx context.Context, h model.Webhook, p WebhookPayload) {
+	logger := log.GetLogger(ctx)
+	body, err := json.Marshal(p)
+	if err != nil {
+		logger.Errorf("could not encode notification of run %d: %v", p.Run.ID, err)
+		return
+	}
+	d := &model.Delivery{
+		WebhookID: h.ID,
+		RunID:     null.IntFrom(int64(p.Run.ID)),
+		Event:     p.Event,
+		Payload:   body,
+	}
+	if err = w.repo.AddDelivery(ctx, d); err != nil {
+		logger.Errorf("could not log delivery of run %d to webhook %d: %v", p.Run.ID, h.ID, err)
+		return
+	}
+
+	start := time.Now()
+	backoff := DeliveryBackoff
+	for d.Attempts < DeliveryAttempts {
+		if d.Attempts > 0 {
+			if !w.wait(ctx, backoff) {
+				break
+			}
+			backoff *= 2
+		}
+		d.Attempts++
+		code, err := w.post(ctx, h, d.ID, body)
+		d.StatusCode = null.NewInt(int64(code), code != 0)
+		d.Error = null.NewString(errString(err), err != nil)
+		if err == nil {
+			d.Delivered = true
+			break
+		}
+	}
+	d.DurationMs = milliseconds(time.Since(start))
+	if !d.Delivered {
+		logger.Warnf("could not deliver run %d to webhook %d after %d attempt(s): %s", p.Run.ID, h.ID, d.Attempts, d.Error.String)
+	}
+	if err = w.repo.SaveDelivery(ctx, d); err != nil {
+		logger.Errorf("could not log delivery of run %d to webhook %d: %v", p.Run.ID, h.ID, err)
+	}
+}
+
+// wait sleeps for d unless ctx is done or the service stops first, in which
+// case false is returned
+func (w webhook) wait(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-w.stop.Done():
+		return false
+	}
+}
+
+// post makes a single attempt, any response other than 2xx is an error
+func (w webhook) post(ctx context.Context, h model.Webhook, deliveryID int, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tsekaro-webhook")
+	req.Header.Set("X-Tsekaro-Event", h.Event)
+	req.Header.Set("X-Tsekaro-Delivery", strconv.Itoa(deliveryID))
+	if h.Secret.String != "" {
+		req.Header.Set(SignatureHeader, "sha256="+sign(h.Secret.String, body))
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// sign returns the hex encoded HMAC-SHA256 of body keyed with secret
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func failedSteps(report stream.Report) []FailedStep {
+	out := []FailedStep{}
+	for _, s := range report.Steps {
+		switch s.Status {
+		case stream.Failed, stream.Errored, stream.TimedOut:
+			out = append(out, FailedStep{ID: s.ID, Name: s.Name, Status: s.Status, Message: s.Message, Phase: s.Phase})
+		}
+	}
+	return out
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// validateWebhook checks the url and event of a webhook
+func validateWebhook(m *model.Webhook) error {
+	u, err := url.Parse(m.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: invalid 'url' %q of webhook", cerrors.ErrInValidation, m.URL)
+	}
+	if m.Event == "" {
+		m.Event = EventFinished
+	}
+	if m.Event != EventFinished && m.Event != EventChanged {
+		return fmt.Errorf("%w: unsupported 'event' %q of webhook", cerrors.ErrInValidation, m.Event)
+	}
+	return nil
+}
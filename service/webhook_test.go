@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/thejasn/tester/core/stream"
+	rmodel "github.com/thejasn/tester/domain/run/model"
+	hrepo "github.com/thejasn/tester/domain/run/repo"
+	"github.com/thejasn/tester/domain/webhook/model"
+	"github.com/thejasn/tester/domain/webhook/repo"
+)
+
+// webhookRepo returns its hooks for every run and keeps the deliveries
+type webhookRepo struct {
+	repo.Webhook
+	hooks []*model.Webhook
+
+	mu         sync.Mutex
+	deliveries []model.Delivery
+}
+
+func (r *webhookRepo) Matching(context.Context, null.Int) ([]*model.Webhook, error) {
+	return r.hooks, nil
+}
+
+func (r *webhookRepo) AddDelivery(_ context.Context, d *model.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d.ID = len(r.deliveries) + 1
+	r.deliveries = append(r.deliveries, *d)
+	return nil
+}
+
+func (r *webhookRepo) SaveDelivery(_ context.Context, d *model.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[d.ID-1] = *d
+	return nil
+}
+
+// runRepo knows the outcome of the previous run, if any
+type runRepo struct {
+	hrepo.Run
+	previous *bool
+}
+
+func (r runRepo) Previous(context.Context, rmodel.Run) (rmodel.Run, error) {
+	if r.previous == nil {
+		return rmodel.Run{}, errors.New("no previous run")
+	}
+	return rmodel.Run{Passed: *r.previous}, nil
+}
+
+func TestWebhookCleanUp(t *testing.T) {
+	backoff := DeliveryBackoff
+	DeliveryBackoff = time.Hour
+	defer func() { DeliveryBackoff = backoff }()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	r := &webhookRepo{hooks: []*model.Webhook{{ID: 1, URL: srv.URL, Event: EventFinished}}}
+	w := NewWebhookSvc(r, runRepo{})
+	w.Notify(context.Background(), null.IntFrom(1), "login", rmodel.Run{ID: 7}, stream.Report{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.CleanUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.deliveries) != 1 || r.deliveries[0].Delivered || r.deliveries[0].Attempts < 1 || r.deliveries[0].StatusCode.Int64 != http.StatusBadGateway {
+		t.Fatalf("bad deliveries: %#v", r.deliveries)
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	backoff := DeliveryBackoff
+	DeliveryBackoff = time.Millisecond
+	defer func() { DeliveryBackoff = backoff }()
+
+	passed, failed := true, false
+	cases := []struct {
+		Name      string
+		Event     string
+		Secret    string
+		Previous  *bool
+		Passed    bool
+		Failures  int
+		Requests  int
+		Delivered bool
+	}{
+		{Name: "finished", Event: EventFinished, Passed: true, Requests: 1, Delivered: true},
+		{Name: "signed", Event: EventFinished, Secret: "s3cret", Requests: 1, Delivered: true},
+		{Name: "retried", Event: EventFinished, Failures: 2, Requests: 3, Delivered: true},
+		{Name: "given up", Event: EventFinished, Failures: DeliveryAttempts, Requests: DeliveryAttempts},
+		{Name: "changed", Event: EventChanged, Previous: &passed, Passed: false, Requests: 1, Delivered: true},
+		{Name: "unchanged", Event: EventChanged, Previous: &failed, Passed: false},
+		{Name: "first run", Event: EventChanged, Passed: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var mu sync.Mutex
+			var bodies [][]byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				mu.Lock()
+				defer mu.Unlock()
+				bodies = append(bodies, body)
+				want := ""
+				if tc.Secret != "" {
+					mac := hmac.New(sha256.New, []byte(tc.Secret))
+					mac.Write(body)
+					want = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+				}
+				if got := r.Header.Get(SignatureHeader); got != want {
+					t.Errorf("bad signature %q, want %q", got, want)
+				}
+				if len(bodies) <= tc.Failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer srv.Close()
+
+			hook := &model.Webhook{ID: 1, URL: srv.URL, Event: tc.Event, Secret: null.NewString(tc.Secret, tc.Secret != "")}
+			r := &webhookRepo{hooks: []*model.Webhook{hook}}
+			w := NewWebhookSvc(r, runRepo{previous: tc.Previous})
+			report := stream.Report{Steps: []stream.StepResult{{Name: "login", Status: stream.Failed}, {Name: "cart", Status: stream.Skipped}}}
+			w.Notify(context.Background(), null.IntFrom(1), "checkout", rmodel.Run{ID: 7, Passed: tc.Passed}, report)
+			w.(webhook).deliveries.Wait()
+
+			if len(bodies) != tc.Requests {
+				t.Fatalf("bad requests: %d", len(bodies))
+			}
+			if tc.Requests == 0 {
+				if len(r.deliveries) != 0 {
+					t.Fatalf("unexpected deliveries: %#v", r.deliveries)
+				}
+				return
+			}
+			d := r.deliveries[0]
+			if d.Attempts != tc.Requests || d.Delivered != tc.Delivered {
+				t.Fatalf("bad delivery: %#v", d)
+			}
+			var p WebhookPayload
+			if err := json.Unmarshal(bodies[0], &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Event != tc.Event || p.Run.ID != 7 || p.Run.Name != "checkout" || len(p.FailedSteps) != 1 || p.FailedSteps[0].Name != "login" {
+				t.Fatalf("bad payload: %#v", p)
+			}
+			if p.Run.PreviousPassed.Valid != (tc.Previous != nil) {
+				t.Fatalf("bad previous outcome: %#v", p.Run.PreviousPassed)
+			}
+		})
+	}
+}
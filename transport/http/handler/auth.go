@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/auth/model"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type authprofilehandler struct {
+	svc service.AuthProfile
+}
+
+func NewAuthProfileHandler(as service.AuthProfile) authprofilehandler {
+	return authprofilehandler{
+		svc: as,
+	}
+}
+
+func (a authprofilehandler) ConfigAuthProfilesRouter(router chi.Router) {
+	router.Get("/auth-profiles", a.GetAllAuthProfiles)
+	router.Post("/auth-profiles", a.AddAuthProfile)
+	router.Get("/auth-profiles/{id}", a.GetAuthProfile)
+	router.Put("/auth-profiles/{id}", a.UpdateAuthProfile)
+	router.Delete("/auth-profiles/{id}", a.DeleteAuthProfile)
+}
+
+// GetAllAuthProfiles is a function to get a slice of record(s) from auth_profile table in the tester database
+// @Summary Get list of AuthProfile
+// @Tags AuthProfile
+// @Description GetAllAuthProfile is a handler to get a slice of record(s) from auth_profile table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.Profile}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /auth-profiles [get]
+// http http://localhost:8080/auth-profiles?page=0&pagesize=20
+func (a authprofilehandler) GetAllAuthProfiles(w http.ResponseWriter, r *http.Request) {
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	records, totalRows, err := a.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	profiles := make([]model.Profile, len(records))
+	for i, record := range records {
+		profiles[i] = record.Redacted()
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: profiles, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
+
+// GetAuthProfile is a function to get a single record to auth_profile table in the tester database,
+// credentials being left out
+// @Summary Get record from table AuthProfile by id
+// @Tags AuthProfile
+// @ID record id
+// @Description GetAuthProfile is a function to get a single record to auth_profile table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "record id"
+// @Success 200 {object} model.Profile
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /auth-profiles/{id} [get]
+// http http://localhost:8080/auth-profiles/1
+func (a authprofilehandler) GetAuthProfile(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := a.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record.Redacted())
+}
+
+// AddAuthProfile add to add a single record to auth_profile table in the tester database
+// @Summary Add an record to auth_profile table
+// @Description add to add a single record to auth_profile table in the tester database
+// @Tags AuthProfile
+// @Accept  json
+// @Produce  json
+// @Param AuthProfile body model.Profile true "Add AuthProfile"
+// @Success 200 {object} model.Profile
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /auth-profiles [post]
+// echo '{"id": 7}' | http POST http://localhost:8080/auth-profiles
+func (a authprofilehandler) AddAuthProfile(w http.ResponseWriter, r *http.Request) {
+	profile := &model.Profile{}
+
+	if err := readJSON(r, profile); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	var err error
+	profile, _, err = a.svc.Add(log.WithLogger(r.Context(), log.Init()), profile)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, profile.Redacted())
+}
+
+// UpdateAuthProfile Update a single record from auth_profile table in the tester database
+// @Summary Update an record in table auth_profile
+// @Description Update a single record from auth_profile table in the tester database
+// @Tags AuthProfile
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Account ID"
+// @Param  AuthProfile body model.Profile true "Update AuthProfile record"
+// @Success 200 {object} model.Profile
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /auth-profiles/{id} [patch]
+// echo '{"id": 7}' | http PATCH http://localhost:8080/auth-profiles/1
+func (a authprofilehandler) UpdateAuthProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	profile := &model.Profile{}
+	if err := readJSON(r, profile); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	profile, _, err = a.svc.Update(log.WithLogger(r.Context(), log.Init()), id, profile)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, profile.Redacted())
+}
+
+// DeleteAuthProfile Delete a single record from auth_profile table in the tester database
+// @Summary Delete a record from auth_profile
+// @Description Delete a single record from auth_profile table in the tester database
+// @Tags AuthProfile
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "ID" Format(int64)
+// @Success 204 {object} model.Profile
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /auth-profiles/{id} [delete]
+// http DELETE http://localhost:8080/auth-profiles/1
+func (a authprofilehandler) DeleteAuthProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	rowsAffected, err := a.svc.Delete(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
@@ -26,6 +26,7 @@ func (f flowhandler) ConfigFlowsRouter(router chi.Router) {
 	router.Get("/flows/{id}", f.GetFlow)
 	router.Put("/flows/{id}", f.UpdateFlow)
 	router.Delete("/flows/{id}", f.DeleteFlow)
+	router.Get("/flows/execute", f.ExecuteTaggedFlows)
 	router.Get("/flows/execute/{id}", f.ExecuteFlow)
 }
 
@@ -38,11 +39,16 @@ func (f flowhandler) ConfigFlowsRouter(router chi.Router) {
 // @Param   page     query    int     false        "page requested (defaults to 0)"
 // @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
 // @Param   order    query    string  false        "db sort order column"
+// @Param   name     query    string  false        "substring of the name"
+// @Param   tag      query    string  false        "one of the tags"
+// @Param   api      query    string  false        "api type of one of the testcases e.g. REST"
+// @Param   host     query    string  false        "host of one of the testcases"
+// @Param   status   query    string  false        "outcome of the latest run, passed or failed"
 // @Success 200 {object} api.PagedResults{data=[]model.Flow}
 // @Failure 400 {object} api.HTTPError
 // @Failure 404 {object} api.HTTPError
 // @Router /flows [get]
-// http http://localhost:8080/flows?page=0&pagesize=20
+// http http://localhost:8080/flows?page=0&pagesize=20&tag=smoke&status=failed
 func (f flowhandler) GetAllFlows(w http.ResponseWriter, r *http.Request) {
 	page, err := readInt(r, "page", 0)
 	if err != nil || page < 0 {
@@ -58,7 +64,15 @@ func (f flowhandler) GetAllFlows(w http.ResponseWriter, r *http.Request) {
 
 	order := r.FormValue("order")
 
-	records, totalRows, err := f.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	filter := service.FlowFilter{
+		Name:   r.FormValue("name"),
+		Tag:    r.FormValue("tag"),
+		API:    r.FormValue("api"),
+		Host:   r.FormValue("host"),
+		Status: r.FormValue("status"),
+	}
+
+	records, totalRows, err := f.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order, filter)
 	if err != nil {
 		returnError(w, r, err)
 		return
@@ -199,7 +213,14 @@ func (f flowhandler) ExecuteFlow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	record, err := f.svc.Execute(log.WithLogger(r.Context(), log.Init()), id)
+	ctx, cancel, err := runContext(r)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+	defer cancel()
+
+	record, err := f.svc.ExecuteWith(ctx, id, service.RunOptions{Environment: r.FormValue("environment")})
 	if err != nil {
 		returnError(w, r, err)
 		return
@@ -207,3 +228,30 @@ func (f flowhandler) ExecuteFlow(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, record)
 }
+
+// ExecuteTaggedFlows runs every flow carrying a tag and returns the aggregate report
+// @Summary Execute the flows with a tag
+// @Tags Flow
+// @Description ExecuteTaggedFlows runs every flow carrying the tag, one after the other, and reports on each of them
+// @Produce  json
+// @Param  tag         query string true  "tag of the flows to run"
+// @Param  environment query string false "environment the flows run in"
+// @Param  timeout     query string false "deadline of the whole run e.g. 5m"
+// @Param  stream      query bool   false "stream each flow as it finishes, as newline delimited json"
+// @Success 200 {object} service.SuiteReport
+// @Failure 400 {object} api.HTTPError
+// @Router /flows/execute [get]
+// http http://localhost:8080/flows/execute?tag=smoke
+func (f flowhandler) ExecuteTaggedFlows(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel, err := runContext(r)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+	defer cancel()
+
+	writeRun(w, r, service.RunOptions{Environment: r.FormValue("environment")}, func(o service.RunOptions) (service.SuiteReport, error) {
+		return f.svc.ExecuteTagged(ctx, r.FormValue("tag"), o)
+	})
+}
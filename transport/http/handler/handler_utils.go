@@ -1,14 +1,18 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
 	"github.com/thejasn/tester/utils/httputil"
 )
 
@@ -35,6 +39,23 @@ func readInt(r *http.Request, param string, v int64) (int64, error) {
 	return strconv.ParseInt(p, 10, 64)
 }
 
+// runContext returns the context of an execution request, bounded by the
+// optional timeout query parameter e.g. ?timeout=30s
+func runContext(r *http.Request) (context.Context, context.CancelFunc, error) {
+	ctx := log.WithLogger(r.Context(), log.Init())
+	p := r.FormValue("timeout")
+	if p == "" {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	d, err := time.ParseDuration(p)
+	if err != nil || d <= 0 {
+		return nil, nil, cerrors.ErrBadParams
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, cancel, nil
+}
+
 func writeJSON(w http.ResponseWriter, v interface{}) {
 	data, _ := json.Marshal(v)
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -42,6 +63,49 @@ func writeJSON(w http.ResponseWriter, v interface{}) {
 	w.Write(data)
 }
 
+// runEvent is a line of a streamed run, one per flow as it finishes and a
+// last one carrying either the aggregate report or the error of the run
+type runEvent struct {
+	Event   string                   `json:"event"`
+	Flow    *service.SuiteFlowResult `json:"flow,omitempty"`
+	Report  *service.SuiteReport     `json:"report,omitempty"`
+	Message string                   `json:"message,omitempty"`
+}
+
+// writeRun executes a suite or tagged run and writes its report. With
+// ?stream=true the response is newline delimited json, each flow being
+// written and flushed as soon as it finishes.
+func writeRun(w http.ResponseWriter, r *http.Request, o service.RunOptions, run func(service.RunOptions) (service.SuiteReport, error)) {
+	if streamed, _ := strconv.ParseBool(r.FormValue("stream")); !streamed {
+		report, err := run(o)
+		if err != nil {
+			returnError(w, r, err)
+			return
+		}
+		writeJSON(w, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	enc := json.NewEncoder(w)
+	send := func(e runEvent) {
+		_ = enc.Encode(e)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	o.Progress = func(f service.SuiteFlowResult) {
+		send(runEvent{Event: "flow", Flow: &f})
+	}
+	report, err := run(o)
+	if err != nil {
+		send(runEvent{Event: "error", Message: err.Error()})
+		return
+	}
+	send(runEvent{Event: "report", Report: &report})
+}
+
 func writeRowsAffected(w http.ResponseWriter, rowsAffected int64) {
 	data, _ := json.Marshal(rowsAffected)
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
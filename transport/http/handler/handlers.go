@@ -1,6 +1,14 @@
 package handler
 
 type Set struct {
-	Flow     flowhandler
-	Testcase testcasehandler
+	Flow        flowhandler
+	Testcase    testcasehandler
+	AuthProfile authprofilehandler
+	Schema      schemahandler
+	Protoset    protosethandler
+	Snapshot    snapshothandler
+	Run         runhandler
+	Suite       suitehandler
+	Schedule    schedulehandler
+	Webhook     webhookhandler
 }
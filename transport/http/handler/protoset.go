@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/protoset/model"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type protosethandler struct {
+	svc service.Protoset
+}
+
+func NewProtosetHandler(ps service.Protoset) protosethandler {
+	return protosethandler{
+		svc: ps,
+	}
+}
+
+func (p protosethandler) ConfigProtosetsRouter(router chi.Router) {
+	router.Get("/protosets", p.GetAllProtosets)
+	router.Post("/protosets", p.AddProtoset)
+	router.Get("/protosets/{id}", p.GetProtoset)
+	router.Put("/protosets/{id}", p.UpdateProtoset)
+	router.Delete("/protosets/{id}", p.DeleteProtoset)
+}
+
+// GetAllProtosets is a function to get a slice of record(s) from protoset table in the tester database
+// @Summary Get list of Protoset
+// @Tags Protoset
+// @Description GetAllProtoset is a handler to get a slice of record(s) from protoset table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.Protoset}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /protosets [get]
+// http http://localhost:8080/protosets?page=0&pagesize=20
+func (p protosethandler) GetAllProtosets(w http.ResponseWriter, r *http.Request) {
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	records, totalRows, err := p.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
+
+// GetProtoset is a function to get a single record to protoset table in the tester database
+// @Summary Get record from table Protoset by id
+// @Tags Protoset
+// @ID record id
+// @Description GetProtoset is a function to get a single record to protoset table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "record id"
+// @Success 200 {object} model.Protoset
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /protosets/{id} [get]
+// http http://localhost:8080/protosets/1
+func (p protosethandler) GetProtoset(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := p.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record)
+}
+
+// AddProtoset add to add a single record to protoset table in the tester database
+// @Summary Add an record to protoset table
+// @Description add to add a single record to protoset table in the tester database
+// @Tags Protoset
+// @Accept  json
+// @Produce  json
+// @Param Protoset body model.Protoset true "Add Protoset"
+// @Success 200 {object} model.Protoset
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /protosets [post]
+// echo '{"id": 7}' | http POST http://localhost:8080/protosets
+func (p protosethandler) AddProtoset(w http.ResponseWriter, r *http.Request) {
+	protoset := &model.Protoset{}
+
+	if err := readJSON(r, protoset); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	var err error
+	protoset, _, err = p.svc.Add(log.WithLogger(r.Context(), log.Init()), protoset)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, protoset)
+}
+
+// UpdateProtoset Update a single record from protoset table in the tester database
+// @Summary Update an record in table protoset
+// @Description Update a single record from protoset table in the tester database
+// @Tags Protoset
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Account ID"
+// @Param  Protoset body model.Protoset true "Update Protoset record"
+// @Success 200 {object} model.Protoset
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /protosets/{id} [patch]
+// echo '{"id": 7}' | http PATCH http://localhost:8080/protosets/1
+func (p protosethandler) UpdateProtoset(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	protoset := &model.Protoset{}
+	if err := readJSON(r, protoset); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	protoset, _, err = p.svc.Update(log.WithLogger(r.Context(), log.Init()), id, protoset)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, protoset)
+}
+
+// DeleteProtoset Delete a single record from protoset table in the tester database
+// @Summary Delete a record from protoset
+// @Description Delete a single record from protoset table in the tester database
+// @Tags Protoset
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "ID" Format(int64)
+// @Success 204 {object} model.Protoset
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /protosets/{id} [delete]
+// http DELETE http://localhost:8080/protosets/1
+func (p protosethandler) DeleteProtoset(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	rowsAffected, err := p.svc.Delete(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
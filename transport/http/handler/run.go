@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/core/export"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type runhandler struct {
+	svc service.Run
+}
+
+func NewRunHandler(rs service.Run) runhandler {
+	return runhandler{
+		svc: rs,
+	}
+}
+
+func (h runhandler) ConfigRunsRouter(router chi.Router) {
+	router.Get("/runs", h.GetAllRuns)
+	router.Get("/runs/{id}", h.GetRun)
+	router.Get("/runs/{id}/report", h.GetRunReport)
+	router.Get("/testcases/{id}/timings", h.GetTimings)
+}
+
+// GetAllRuns is a function to get a slice of record(s) from run table in the tester database
+// @Summary Get list of Run
+// @Tags Run
+// @Description GetAllRuns is a handler to get the history of runs, latest first
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Param   flow_id  query    int     false        "runs of the given flow only"
+// @Success 200 {object} api.PagedResults{data=[]model.Run}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /runs [get]
+// http http://localhost:8080/runs?flow_id=1&page=0&pagesize=20
+func (h runhandler) GetAllRuns(w http.ResponseWriter, r *http.Request) {
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	flowID, err := readInt(r, "flow_id", 0)
+	if err != nil || flowID < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	records, totalRows, err := h.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order, int(flowID))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
+
+// GetRun is a function to get a single record to run table in the tester database
+// @Summary Get record from table Run by id
+// @Tags Run
+// @ID record id
+// @Description GetRun returns a run along with its report
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "record id"
+// @Success 200 {object} model.Run
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /runs/{id} [get]
+// http http://localhost:8080/runs/1
+func (h runhandler) GetRun(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := h.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record)
+}
+
+// GetTimings is a function to get the timings of a testcase over its latest runs
+// @Summary Get the timings of a testcase
+// @Tags Run
+// @Description GetTimings returns the outcome and timings of a testcase in its latest runs, latest first
+// @Accept  json
+// @Produce  json
+// @Param  id    path  int true  "testcase id"
+// @Param  limit query int false "number of runs (defaults to 100)"
+// @Success 200 {object} []model.RunStep
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /testcases/{id}/timings [get]
+// http http://localhost:8080/testcases/1/timings?limit=50
+func (h runhandler) GetTimings(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	limit, err := readInt(r, "limit", service.DefaultTimingsLimit)
+	if err != nil || limit <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	records, err := h.svc.Timings(log.WithLogger(r.Context(), log.Init()), id, int(limit))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, records)
+}
+
+// GetRunReport renders the report of a run for CI servers and people
+// @Summary Export the report of a run
+// @Tags Run
+// @Description GetRunReport renders a run as JUnit XML, TAP, json or a standalone HTML page, each step being a test
+// @Produce  json,xml,plain,html
+// @Param  id     path  int    true  "record id"
+// @Param  format query string false "junit, tap, json or html (defaults to json)"
+// @Success 200 {object} export.Run
+// @Failure 400 {object} api.HTTPError
+// @Router /runs/{id}/report [get]
+// http http://localhost:8080/runs/1/report?format=junit
+func (h runhandler) GetRunReport(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	format, err := export.ParseFormat(r.FormValue("format"))
+	if err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	record, err := h.svc.Export(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	var b bytes.Buffer
+	if err = export.Write(&b, format, record); err != nil {
+		returnError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(b.Bytes())
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/schedule/model"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type schedulehandler struct {
+	svc service.Schedule
+}
+
+func NewScheduleHandler(ss service.Schedule) schedulehandler {
+	return schedulehandler{
+		svc: ss,
+	}
+}
+
+func (h schedulehandler) ConfigSchedulesRouter(router chi.Router) {
+	router.Get("/schedules", h.GetAllSchedules)
+	router.Post("/schedules", h.AddSchedule)
+	router.Get("/schedules/{id}", h.GetSchedule)
+	router.Put("/schedules/{id}", h.UpdateSchedule)
+	router.Delete("/schedules/{id}", h.DeleteSchedule)
+}
+
+// GetAllSchedules is a function to get a slice of record(s) from schedule table in the tester database
+// @Summary Get list of Schedule
+// @Tags Schedule
+// @Description GetAllSchedule is a handler to get a slice of record(s) from schedule table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.Schedule}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /schedules [get]
+// http http://localhost:8080/schedules?page=0&pagesize=20
+func (h schedulehandler) GetAllSchedules(w http.ResponseWriter, r *http.Request) {
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	records, totalRows, err := h.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
+
+// GetSchedule is a function to get a single record to schedule table in the tester database
+// @Summary Get record from table Schedule by id
+// @Tags Schedule
+// @ID record id
+// @Description GetSchedule is a function to get a single record to schedule table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "record id"
+// @Success 200 {object} model.Schedule
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /schedules/{id} [get]
+// http http://localhost:8080/schedules/1
+func (h schedulehandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := h.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record)
+}
+
+// AddSchedule add to add a single record to schedule table in the tester database
+// @Summary Add an record to schedule table
+// @Description add to add a single record to schedule table in the tester database
+// @Tags Schedule
+// @Accept  json
+// @Produce  json
+// @Param Schedule body model.Schedule true "Add Schedule"
+// @Success 200 {object} model.Schedule
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /schedules [post]
+// echo '{"name": "smoke", "cron": "*/5 * * * *", "target": "tag", "tag": "smoke", "environment": "staging"}' | http POST http://localhost:8080/schedules
+func (h schedulehandler) AddSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule := &model.Schedule{}
+
+	if err := readJSON(r, schedule); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	var err error
+	schedule, _, err = h.svc.Add(log.WithLogger(r.Context(), log.Init()), schedule)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, schedule)
+}
+
+// UpdateSchedule Update a single record from schedule table in the tester database
+// @Summary Update an record in table schedule
+// @Description Update a single record from schedule table in the tester database
+// @Tags Schedule
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Account ID"
+// @Param  Schedule body model.Schedule true "Update Schedule record"
+// @Success 200 {object} model.Schedule
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /schedules/{id} [patch]
+// echo '{"id": 4}' | http PATCH http://localhost:8080/schedules/1
+func (h schedulehandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	schedule := &model.Schedule{}
+	if err := readJSON(r, schedule); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	schedule, _, err = h.svc.Update(log.WithLogger(r.Context(), log.Init()), id, schedule)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, schedule)
+}
+
+// DeleteSchedule Delete a single record from schedule table in the tester database
+// @Summary Delete a record from schedule
+// @Description Delete a single record from schedule table in the tester database
+// @Tags Schedule
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "ID" Format(int64)
+// @Success 204 {object} model.Schedule
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /schedules/{id} [delete]
+// http DELETE http://localhost:8080/schedules/1
+func (h schedulehandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	rowsAffected, err := h.svc.Delete(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/schema/model"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type schemahandler struct {
+	svc service.Schema
+}
+
+func NewSchemaHandler(ss service.Schema) schemahandler {
+	return schemahandler{
+		svc: ss,
+	}
+}
+
+func (s schemahandler) ConfigSchemasRouter(router chi.Router) {
+	router.Get("/schemas", s.GetAllSchemas)
+	router.Post("/schemas", s.AddSchema)
+	router.Get("/schemas/{id}", s.GetSchema)
+	router.Put("/schemas/{id}", s.UpdateSchema)
+	router.Delete("/schemas/{id}", s.DeleteSchema)
+}
+
+// GetAllSchemas is a function to get a slice of record(s) from json_schema table in the tester database
+// @Summary Get list of Schema
+// @Tags Schema
+// @Description GetAllSchema is a handler to get a slice of record(s) from json_schema table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.Schema}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /schemas [get]
+// http http://localhost:8080/schemas?page=0&pagesize=20
+func (s schemahandler) GetAllSchemas(w http.ResponseWriter, r *http.Request) {
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	records, totalRows, err := s.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
+
+// GetSchema is a function to get a single record to json_schema table in the tester database
+// @Summary Get record from table Schema by id
+// @Tags Schema
+// @ID record id
+// @Description GetSchema is a function to get a single record to json_schema table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "record id"
+// @Success 200 {object} model.Schema
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /schemas/{id} [get]
+// http http://localhost:8080/schemas/1
+func (s schemahandler) GetSchema(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := s.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record)
+}
+
+// AddSchema add to add a single record to json_schema table in the tester database
+// @Summary Add an record to json_schema table
+// @Description add to add a single record to json_schema table in the tester database
+// @Tags Schema
+// @Accept  json
+// @Produce  json
+// @Param Schema body model.Schema true "Add Schema"
+// @Success 200 {object} model.Schema
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /schemas [post]
+// echo '{"id": 7}' | http POST http://localhost:8080/schemas
+func (s schemahandler) AddSchema(w http.ResponseWriter, r *http.Request) {
+	schema := &model.Schema{}
+
+	if err := readJSON(r, schema); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	var err error
+	schema, _, err = s.svc.Add(log.WithLogger(r.Context(), log.Init()), schema)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, schema)
+}
+
+// UpdateSchema Update a single record from json_schema table in the tester database
+// @Summary Update an record in table json_schema
+// @Description Update a single record from json_schema table in the tester database
+// @Tags Schema
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Account ID"
+// @Param  Schema body model.Schema true "Update Schema record"
+// @Success 200 {object} model.Schema
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /schemas/{id} [patch]
+// echo '{"id": 7}' | http PATCH http://localhost:8080/schemas/1
+func (s schemahandler) UpdateSchema(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	schema := &model.Schema{}
+	if err := readJSON(r, schema); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	schema, _, err = s.svc.Update(log.WithLogger(r.Context(), log.Init()), id, schema)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, schema)
+}
+
+// DeleteSchema Delete a single record from json_schema table in the tester database
+// @Summary Delete a record from json_schema
+// @Description Delete a single record from json_schema table in the tester database
+// @Tags Schema
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "ID" Format(int64)
+// @Success 204 {object} model.Schema
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /schemas/{id} [delete]
+// http DELETE http://localhost:8080/schemas/1
+func (s schemahandler) DeleteSchema(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	rowsAffected, err := s.svc.Delete(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type snapshothandler struct {
+	svc service.Snapshot
+}
+
+func NewSnapshotHandler(ss service.Snapshot) snapshothandler {
+	return snapshothandler{
+		svc: ss,
+	}
+}
+
+func (s snapshothandler) ConfigSnapshotsRouter(router chi.Router) {
+	router.Get("/testcases/{id}/snapshot", s.GetSnapshot)
+	router.Post("/testcases/{id}/snapshot/accept", s.AcceptSnapshot)
+	router.Delete("/testcases/{id}/snapshot", s.DeleteSnapshot)
+}
+
+// GetSnapshot is a function to review the snapshot of a testcase
+// @Summary Get the golden and pending snapshot of a testcase
+// @Tags Snapshot
+// @Description GetSnapshot returns the golden snapshot of a testcase, the pending response and their diff
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "testcase id"
+// @Success 200 {object} service.SnapshotReview
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, no snapshot recorded for the testcase"
+// @Router /testcases/{id}/snapshot [get]
+// http http://localhost:8080/testcases/1/snapshot
+func (s snapshothandler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := s.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record)
+}
+
+// AcceptSnapshot approves the pending response of a testcase as its golden snapshot
+// @Summary Accept the pending snapshot of a testcase
+// @Tags Snapshot
+// @Description AcceptSnapshot replaces the golden snapshot of a testcase with its pending response
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "testcase id"
+// @Success 200 {object} model.Snapshot
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /testcases/{id}/snapshot/accept [post]
+// http POST http://localhost:8080/testcases/1/snapshot/accept
+func (s snapshothandler) AcceptSnapshot(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := s.svc.Accept(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record)
+}
+
+// DeleteSnapshot discards the snapshot of a testcase
+// @Summary Delete the snapshot of a testcase
+// @Description DeleteSnapshot discards the snapshot of a testcase, the next passing run records a new one
+// @Tags Snapshot
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "testcase id"
+// @Success 204 {object} model.Snapshot
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /testcases/{id}/snapshot [delete]
+// http DELETE http://localhost:8080/testcases/1/snapshot
+func (s snapshothandler) DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	rowsAffected, err := s.svc.Delete(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/suite/model"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type suitehandler struct {
+	svc service.Suite
+}
+
+func NewSuiteHandler(ss service.Suite) suitehandler {
+	return suitehandler{
+		svc: ss,
+	}
+}
+
+func (h suitehandler) ConfigSuitesRouter(router chi.Router) {
+	router.Get("/suites", h.GetAllSuites)
+	router.Post("/suites", h.AddSuite)
+	router.Get("/suites/{id}", h.GetSuite)
+	router.Put("/suites/{id}", h.UpdateSuite)
+	router.Delete("/suites/{id}", h.DeleteSuite)
+	router.Get("/suites/execute/{id}", h.ExecuteSuite)
+}
+
+// GetAllSuites is a function to get a slice of record(s) from suite table in the tester database
+// @Summary Get list of Suite
+// @Tags Suite
+// @Description GetAllSuite is a handler to get a slice of record(s) from suite table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.Suite}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /suites [get]
+// http http://localhost:8080/suites?page=0&pagesize=20
+func (h suitehandler) GetAllSuites(w http.ResponseWriter, r *http.Request) {
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	records, totalRows, err := h.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
+
+// GetSuite is a function to get a single record to suite table in the tester database
+// @Summary Get record from table Suite by id
+// @Tags Suite
+// @ID record id
+// @Description GetSuite is a function to get a single record to suite table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "record id"
+// @Success 200 {object} model.Suite
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /suites/{id} [get]
+// http http://localhost:8080/suites/1
+func (h suitehandler) GetSuite(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := h.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record)
+}
+
+// AddSuite add to add a single record to suite table in the tester database
+// @Summary Add an record to suite table
+// @Description add to add a single record to suite table in the tester database
+// @Tags Suite
+// @Accept  json
+// @Produce  json
+// @Param Suite body model.Suite true "Add Suite"
+// @Success 200 {object} model.Suite
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /suites [post]
+// echo '{"id": 3}' | http POST http://localhost:8080/suites
+func (h suitehandler) AddSuite(w http.ResponseWriter, r *http.Request) {
+	suite := &model.Suite{}
+
+	if err := readJSON(r, suite); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	var err error
+	suite, _, err = h.svc.Add(log.WithLogger(r.Context(), log.Init()), suite)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, suite)
+}
+
+// UpdateSuite Update a single record from suite table in the tester database
+// @Summary Update an record in table suite
+// @Description Update a single record from suite table in the tester database
+// @Tags Suite
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Account ID"
+// @Param  Suite body model.Suite true "Update Suite record"
+// @Success 200 {object} model.Suite
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /suites/{id} [patch]
+// echo '{"id": 3}' | http PATCH http://localhost:8080/suites/1
+func (h suitehandler) UpdateSuite(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	suite := &model.Suite{}
+	if err := readJSON(r, suite); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	suite, _, err = h.svc.Update(log.WithLogger(r.Context(), log.Init()), id, suite)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, suite)
+}
+
+// DeleteSuite Delete a single record from suite table in the tester database
+// @Summary Delete a record from suite
+// @Description Delete a single record from suite table in the tester database
+// @Tags Suite
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "ID" Format(int64)
+// @Success 204 {object} model.Suite
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /suites/{id} [delete]
+// http DELETE http://localhost:8080/suites/1
+func (h suitehandler) DeleteSuite(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	rowsAffected, err := h.svc.Delete(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
+
+// ExecuteSuite runs the flows of a suite and returns the aggregate report
+// @Summary Execute a suite
+// @Tags Suite
+// @Description ExecuteSuite runs the flows of a suite, in order or in parallel, and reports on each of them
+// @Produce  json
+// @Param  id          path  int    true  "record id"
+// @Param  environment query string false "environment overriding the one of the suite"
+// @Param  timeout     query string false "deadline of the whole run e.g. 5m"
+// @Param  stream      query bool   false "stream each flow as it finishes, as newline delimited json"
+// @Success 200 {object} service.SuiteReport
+// @Failure 400 {object} api.HTTPError
+// @Router /suites/execute/{id} [get]
+// http http://localhost:8080/suites/execute/3?environment=staging
+func (h suitehandler) ExecuteSuite(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	ctx, cancel, err := runContext(r)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+	defer cancel()
+
+	writeRun(w, r, service.RunOptions{Environment: r.FormValue("environment")}, func(o service.RunOptions) (service.SuiteReport, error) {
+		return h.svc.Execute(ctx, id, o)
+	})
+}
@@ -38,6 +38,12 @@ func (t testcasehandler) ConfigTestcasesRouter(router chi.Router) {
 // @Param   page     query    int     false        "page requested (defaults to 0)"
 // @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
 // @Param   order    query    string  false        "db sort order column"
+// @Param   flow_id  query    int     false        "flow the testcases belong to"
+// @Param   name     query    string  false        "substring of the name"
+// @Param   tag      query    string  false        "one of the tags"
+// @Param   api      query    string  false        "api type e.g. REST"
+// @Param   host     query    string  false        "host called"
+// @Param   status   query    string  false        "status in the latest run e.g. FAILED"
 // @Success 200 {object} api.PagedResults{data=[]model.Testcase}
 // @Failure 400 {object} api.HTTPError
 // @Failure 404 {object} api.HTTPError
@@ -58,7 +64,22 @@ func (t testcasehandler) GetAllTestcases(w http.ResponseWriter, r *http.Request)
 
 	order := r.FormValue("order")
 
-	records, totalRows, err := t.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	flowID, err := readInt(r, "flow_id", 0)
+	if err != nil || flowID < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	filter := service.TestcaseFilter{
+		FlowID: int(flowID),
+		Name:   r.FormValue("name"),
+		Tag:    r.FormValue("tag"),
+		API:    r.FormValue("api"),
+		Host:   r.FormValue("host"),
+		Status: r.FormValue("status"),
+	}
+
+	records, totalRows, err := t.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order, filter)
 	if err != nil {
 		returnError(w, r, err)
 		return
@@ -199,7 +220,14 @@ func (t testcasehandler) ExecuteTestcase(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	record, err := t.svc.Execute(log.WithLogger(r.Context(), log.Init()), id)
+	ctx, cancel, err := runContext(r)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+	defer cancel()
+
+	record, err := t.svc.Execute(ctx, id)
 	if err != nil {
 		returnError(w, r, err)
 		return
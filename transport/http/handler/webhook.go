@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thejasn/tester/cerrors"
+	"github.com/thejasn/tester/domain/webhook/model"
+	"github.com/thejasn/tester/pkg/log"
+	"github.com/thejasn/tester/service"
+)
+
+type webhookhandler struct {
+	svc service.Webhook
+}
+
+func NewWebhookHandler(ss service.Webhook) webhookhandler {
+	return webhookhandler{
+		svc: ss,
+	}
+}
+
+func (h webhookhandler) ConfigWebhooksRouter(router chi.Router) {
+	router.Get("/webhooks", h.GetAllWebhooks)
+	router.Post("/webhooks", h.AddWebhook)
+	router.Get("/webhooks/{id}", h.GetWebhook)
+	router.Put("/webhooks/{id}", h.UpdateWebhook)
+	router.Delete("/webhooks/{id}", h.DeleteWebhook)
+	router.Get("/webhooks/{id}/deliveries", h.GetDeliveries)
+}
+
+// GetAllWebhooks is a function to get a slice of record(s) from webhook table in the tester database
+// @Summary Get list of Webhook
+// @Tags Webhook
+// @Description GetAllWebhook is a handler to get a slice of record(s) from webhook table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.Webhook}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /webhooks [get]
+// http http://localhost:8080/webhooks?page=0&pagesize=20
+func (h webhookhandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	records, totalRows, err := h.svc.GetAll(log.WithLogger(r.Context(), log.Init()), page, pagesize, order)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	webhooks := make([]model.Webhook, len(records))
+	for i, record := range records {
+		webhooks[i] = record.Redacted()
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: webhooks, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
+
+// GetWebhook is a function to get a single record to webhook table in the tester database
+// @Summary Get record from table Webhook by id
+// @Tags Webhook
+// @ID record id
+// @Description GetWebhook is a function to get a single record to webhook table in the tester database
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "record id"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /webhooks/{id} [get]
+// http http://localhost:8080/webhooks/1
+func (h webhookhandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	record, err := h.svc.Get(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, record.Redacted())
+}
+
+// AddWebhook add to add a single record to webhook table in the tester database
+// @Summary Add an record to webhook table
+// @Description add to add a single record to webhook table in the tester database
+// @Tags Webhook
+// @Accept  json
+// @Produce  json
+// @Param Webhook body model.Webhook true "Add Webhook"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /webhooks [post]
+// echo '{"name": "chat-ops", "url": "https://hooks.example.com/tsekaro", "secret": "s3cr3t", "event": "changed"}' | http POST http://localhost:8080/webhooks
+func (h webhookhandler) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook := &model.Webhook{}
+
+	if err := readJSON(r, webhook); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	var err error
+	webhook, _, err = h.svc.Add(log.WithLogger(r.Context(), log.Init()), webhook)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, webhook.Redacted())
+}
+
+// UpdateWebhook Update a single record from webhook table in the tester database
+// @Summary Update an record in table webhook
+// @Description Update a single record from webhook table in the tester database
+// @Tags Webhook
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Account ID"
+// @Param  Webhook body model.Webhook true "Update Webhook record"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /webhooks/{id} [patch]
+// echo '{"id": 5}' | http PATCH http://localhost:8080/webhooks/1
+func (h webhookhandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	webhook := &model.Webhook{}
+	if err := readJSON(r, webhook); err != nil {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	webhook, _, err = h.svc.Update(log.WithLogger(r.Context(), log.Init()), id, webhook)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeJSON(w, webhook.Redacted())
+}
+
+// DeleteWebhook Delete a single record from webhook table in the tester database
+// @Summary Delete a record from webhook
+// @Description Delete a single record from webhook table in the tester database
+// @Tags Webhook
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "ID" Format(int64)
+// @Success 204 {object} model.Webhook
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /webhooks/{id} [delete]
+// http DELETE http://localhost:8080/webhooks/1
+func (h webhookhandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	rowsAffected, err := h.svc.Delete(log.WithLogger(r.Context(), log.Init()), id)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
+
+// GetDeliveries is a function to get the deliveries of a webhook, latest first
+// @Summary Get the delivery log of a webhook
+// @Tags Webhook
+// @Description GetDeliveries lists the notifications posted to a webhook along with the outcome of their last attempt
+// @Produce  json
+// @Param   id       path     int     true         "record id"
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Success 200 {object} api.PagedResults{data=[]model.Delivery}
+// @Failure 400 {object} api.HTTPError
+// @Router /webhooks/{id}/deliveries [get]
+// http http://localhost:8080/webhooks/5/deliveries?page=0&pagesize=20
+func (h webhookhandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := parseInt(chi.URLParam(r, "id"))
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(w, r, cerrors.ErrBadParams)
+		return
+	}
+
+	records, totalRows, err := h.svc.Deliveries(log.WithLogger(r.Context(), log.Init()), id, page, pagesize)
+	if err != nil {
+		returnError(w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(w, result)
+}
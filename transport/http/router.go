@@ -43,6 +43,14 @@ func (r Router) Route(ctx context.Context) *chi.Mux {
 		})
 		m.Group(r.handler.Flow.ConfigFlowsRouter)
 		m.Group(r.handler.Testcase.ConfigTestcasesRouter)
+		m.Group(r.handler.AuthProfile.ConfigAuthProfilesRouter)
+		m.Group(r.handler.Schema.ConfigSchemasRouter)
+		m.Group(r.handler.Protoset.ConfigProtosetsRouter)
+		m.Group(r.handler.Snapshot.ConfigSnapshotsRouter)
+		m.Group(r.handler.Run.ConfigRunsRouter)
+		m.Group(r.handler.Suite.ConfigSuitesRouter)
+		m.Group(r.handler.Schedule.ConfigSchedulesRouter)
+		m.Group(r.handler.Webhook.ConfigWebhooksRouter)
 	})
 	log.GetLogger(ctx).Info("Registering handlers")
 	walkFunc := func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
@@ -8,8 +8,16 @@ package main
 import (
 	"context"
 	"github.com/go-chi/chi"
+	repo3 "github.com/thejasn/tester/domain/auth/repo"
 	"github.com/thejasn/tester/domain/flow/repo"
+	repo5 "github.com/thejasn/tester/domain/protoset/repo"
+	repo7 "github.com/thejasn/tester/domain/run/repo"
+	repo9 "github.com/thejasn/tester/domain/schedule/repo"
+	repo4 "github.com/thejasn/tester/domain/schema/repo"
+	repo6 "github.com/thejasn/tester/domain/snapshot/repo"
+	repo8 "github.com/thejasn/tester/domain/suite/repo"
 	repo2 "github.com/thejasn/tester/domain/testcase/repo"
+	repo10 "github.com/thejasn/tester/domain/webhook/repo"
 	"github.com/thejasn/tester/service"
 	"github.com/thejasn/tester/transport/http"
 	"github.com/thejasn/tester/transport/http/handler"
@@ -18,17 +26,54 @@ import (
 
 // Injectors from inject_tester.go:
 
-func injectTester(ctx context.Context, r *chi.Mux, db *gorm.DB) http.Router {
+func injectTester(ctx context.Context, r *chi.Mux, db *gorm.DB) application {
 	flow := repo.NewFlowRepo(db)
 	testcase := repo2.NewTestcaseRepo(db)
-	serviceFlow := service.NewFlowSvc(flow, testcase)
+	profile := repo3.NewProfileRepo(db)
+	schema := repo4.NewSchemaRepo(db)
+	protoset := repo5.NewProtosetRepo(db)
+	snapshot := repo6.NewSnapshotRepo(db)
+	run := repo7.NewRunRepo(db)
+	webhook := repo10.NewWebhookRepo(db)
+	serviceWebhook := service.NewWebhookSvc(webhook, run)
+	serviceFlow := service.NewFlowSvc(flow, testcase, profile, schema, protoset, snapshot, run, serviceWebhook)
 	flowhandler := handler.NewFlowHandler(serviceFlow)
-	serviceTestcase := service.NewTestcaseSvc(testcase)
+	serviceTestcase := service.NewTestcaseSvc(testcase, flow, profile, schema, protoset, snapshot, run, serviceWebhook)
 	testcasehandler := handler.NewTestcaseHandler(serviceTestcase)
+	authProfile := service.NewAuthProfileSvc(profile)
+	authprofilehandler := handler.NewAuthProfileHandler(authProfile)
+	serviceSchema := service.NewSchemaSvc(schema)
+	schemahandler := handler.NewSchemaHandler(serviceSchema)
+	serviceProtoset := service.NewProtosetSvc(protoset)
+	protosethandler := handler.NewProtosetHandler(serviceProtoset)
+	serviceSnapshot := service.NewSnapshotSvc(snapshot, testcase)
+	snapshothandler := handler.NewSnapshotHandler(serviceSnapshot)
+	serviceRun := service.NewRunSvc(run, flow, testcase)
+	runhandler := handler.NewRunHandler(serviceRun)
+	suite := repo8.NewSuiteRepo(db)
+	serviceSuite := service.NewSuiteSvc(suite, serviceFlow)
+	suitehandler := handler.NewSuiteHandler(serviceSuite)
+	schedule := repo9.NewScheduleRepo(db)
+	serviceSchedule := service.NewScheduleSvc(schedule, serviceFlow, serviceSuite, flow)
+	schedulehandler := handler.NewScheduleHandler(serviceSchedule)
+	webhookhandler := handler.NewWebhookHandler(serviceWebhook)
 	set := handler.Set{
-		Flow:     flowhandler,
-		Testcase: testcasehandler,
+		Flow:        flowhandler,
+		Testcase:    testcasehandler,
+		AuthProfile: authprofilehandler,
+		Schema:      schemahandler,
+		Protoset:    protosethandler,
+		Snapshot:    snapshothandler,
+		Run:         runhandler,
+		Suite:       suitehandler,
+		Schedule:    schedulehandler,
+		Webhook:     webhookhandler,
 	}
 	router := http.NewRouter(r, set)
-	return router
+	mainApplication := application{
+		Router:    router,
+		Scheduler: serviceSchedule,
+		Webhooks:  serviceWebhook,
+	}
+	return mainApplication
 }